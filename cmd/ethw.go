@@ -6,7 +6,7 @@ import (
 )
 
 func main() {
-	ctx := kong.Parse(&cmd.Cli)
+	ctx := kong.Parse(&cmd.Cli, cmd.Vars)
 
 	// Configure logging
 	cmd.Cli.Log.ConfigureLog()
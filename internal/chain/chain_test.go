@@ -0,0 +1,396 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// fakeEth is the subset of the eth namespace of a node backing the tests, holding a single ERC-20 token.
+type fakeEth struct {
+	balances      map[common.Address]*big.Int
+	nonces        map[common.Address]uint64
+	token         common.Address
+	tokenBalances map[common.Address]*big.Int
+}
+
+type fakeCallArgs struct {
+	To    *common.Address `json:"to"`
+	Data  hexutil.Bytes   `json:"data"`
+	Input hexutil.Bytes   `json:"input"`
+}
+
+func (f *fakeEth) ChainId() *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(1337))
+}
+
+func (f *fakeEth) GetBalance(address common.Address, block string) *hexutil.Big {
+	if balance, ok := f.balances[address]; ok {
+		return (*hexutil.Big)(balance)
+	}
+	return new(hexutil.Big)
+}
+
+func (f *fakeEth) GetTransactionCount(address common.Address, block string) hexutil.Uint64 {
+	return hexutil.Uint64(f.nonces[address])
+}
+
+func (f *fakeEth) Call(args fakeCallArgs, block string) (hexutil.Bytes, error) {
+	data := args.Input
+	if len(data) == 0 {
+		data = args.Data
+	}
+	if args.To == nil || *args.To != f.token || len(data) < 4 {
+		return nil, nil
+	}
+	switch string(data[:4]) {
+	case string(selectorDecimals):
+		return common.LeftPadBytes([]byte{6}, 32), nil
+	case string(selectorSymbol):
+		return common.RightPadBytes([]byte("USDC"), 32), nil
+	case string(selectorBalanceOf):
+		balance := f.tokenBalances[common.BytesToAddress(data[4:])]
+		if balance == nil {
+			balance = new(big.Int)
+		}
+		return common.LeftPadBytes(balance.Bytes(), 32), nil
+	}
+	return nil, errors.New("execution reverted")
+}
+
+// EstimateGas charges 21000 gas for ether transfers and 50000 for token calls.
+func (f *fakeEth) EstimateGas(args fakeCallArgs) hexutil.Uint64 {
+	if args.To != nil && *args.To == f.token {
+		return 50000
+	}
+	return 21000
+}
+
+type ChainTestSuite struct {
+	suite.Suite
+	eth    *fakeEth
+	node   *httptest.Server
+	client *Client
+}
+
+func (suite *ChainTestSuite) SetupTest() {
+	suite.eth = &fakeEth{
+		balances:      map[common.Address]*big.Int{},
+		nonces:        map[common.Address]uint64{},
+		token:         common.HexToAddress("0x00000000000000000000000000000000000000aa"),
+		tokenBalances: map[common.Address]*big.Int{},
+	}
+	server := rpc.NewServer()
+	assert.NoError(suite.T(), server.RegisterName("eth", suite.eth))
+	suite.node = httptest.NewServer(server)
+
+	var err error
+	suite.client, err = Dial(context.Background(), suite.node.URL, 0)
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *ChainTestSuite) TearDownTest() {
+	suite.client.Close()
+	suite.node.Close()
+}
+
+func (suite *ChainTestSuite) TestFormatAndParseUnits() {
+	assert.Equal(suite.T(), "1.5", FormatUnits(big.NewInt(1500000000000000000), EtherDecimals))
+	assert.Equal(suite.T(), "0.000000000000000001", FormatUnits(big.NewInt(1), EtherDecimals))
+	assert.Equal(suite.T(), "0", FormatUnits(new(big.Int), EtherDecimals))
+	assert.Equal(suite.T(), "-2", FormatUnits(big.NewInt(-2000000), 6))
+	assert.Equal(suite.T(), "", FormatUnits(nil, 6))
+
+	for raw, expected := range map[string]string{
+		"1.5":       "1500000000000000000",
+		"30gwei":    "30000000000",
+		"21000 wei": "21000",
+		"2 ether":   "2000000000000000000",
+		".25":       "250000000000000000",
+		"1.500":     "1500000000000000000",
+	} {
+		amount, err := ParseUnits(raw, EtherDecimals)
+		if assert.NoError(suite.T(), err, raw) {
+			assert.Equal(suite.T(), expected, amount.String(), raw)
+		}
+	}
+	amount, err := ParseUnits("12.34", 6)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "12340000", amount.String())
+
+	for _, raw := range []string{"", ".", "1.2.3", "-1", "1e18", "0.0000001", "5gwei"} {
+		_, err := ParseUnits(raw, 6)
+		assert.Error(suite.T(), err, raw)
+	}
+}
+
+// TestParseFee checks fees without unit are read as gwei, not ether.
+func (suite *ChainTestSuite) TestParseFee() {
+	for raw, expected := range map[string]string{
+		"30":          "30000000000",
+		"1.5":         "1500000000",
+		" 2 ":         "2000000000",
+		"30gwei":      "30000000000",
+		"100 wei":     "100",
+		"0.000001eth": "1000000000000",
+	} {
+		fee, err := ParseFee(raw)
+		if assert.NoError(suite.T(), err, raw) {
+			assert.Equal(suite.T(), expected, fee.String(), raw)
+		}
+	}
+
+	for _, raw := range []string{"", "gwei", "0.0000000001", "1.5wei", "2 shannon"} {
+		_, err := ParseFee(raw)
+		assert.Error(suite.T(), err, raw)
+	}
+}
+
+func (suite *ChainTestSuite) TestDecodeSymbol() {
+	encoded := append(common.LeftPadBytes([]byte{32}, 32), common.LeftPadBytes([]byte{3}, 32)...)
+	encoded = append(encoded, common.RightPadBytes([]byte("DAI"), 32)...)
+	assert.Equal(suite.T(), "DAI", decodeSymbol(encoded))
+	assert.Equal(suite.T(), "MKR", decodeSymbol(common.RightPadBytes([]byte("MKR"), 32)))
+	assert.Equal(suite.T(), "AB", decodeSymbol(common.RightPadBytes([]byte("A\nB\x1b"), 32)))
+	assert.Equal(suite.T(), "", decodeSymbol(nil))
+}
+
+func (suite *ChainTestSuite) TestStatus() {
+	ctx := context.Background()
+	account := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	suite.eth.balances[account] = big.NewInt(2500000000000000000)
+	suite.eth.nonces[account] = 7
+	suite.eth.tokenBalances[account] = big.NewInt(1000000)
+
+	chainID, err := suite.client.ChainID(ctx)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(1337), chainID.Int64())
+
+	token, err := suite.client.Token(ctx, suite.eth.token)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), Token{Address: suite.eth.token, Symbol: "USDC", Decimals: 6}, token)
+
+	_, err = suite.client.Token(ctx, common.HexToAddress("0x00000000000000000000000000000000000000bb"))
+	assert.ErrorIs(suite.T(), err, errEmptyResult)
+
+	status := suite.client.Status(ctx, account, []Token{token})
+	assert.NoError(suite.T(), status.Err)
+	assert.Equal(suite.T(), "2.5", FormatUnits(status.Balance, EtherDecimals))
+	assert.Equal(suite.T(), uint64(7), *status.Nonce)
+	assert.Equal(suite.T(), "1", FormatUnits(status.TokenBalances[0], token.Decimals))
+
+	missing := Token{Address: common.HexToAddress("0x00000000000000000000000000000000000000bb"), Symbol: "NOPE"}
+	status = suite.client.Status(ctx, account, []Token{token, missing})
+	assert.Error(suite.T(), status.Err)
+	assert.Contains(suite.T(), status.ErrorMessage(), "NOPE balance")
+	assert.NotNil(suite.T(), status.Balance)
+	assert.NotNil(suite.T(), status.TokenBalances[0])
+	assert.Nil(suite.T(), status.TokenBalances[1])
+}
+
+func (suite *ChainTestSuite) TestSignTransfer() {
+	key, err := crypto.HexToECDSA("8e46b439b30731a639a3d94a9016b040a87b3027da8c932af7e1560862d11b58")
+	assert.NoError(suite.T(), err)
+	from, to := crypto.PubkeyToAddress(key.PublicKey), common.HexToAddress("0x0000000000000000000000000000000000000002")
+	chainID := big.NewInt(1337)
+
+	t := &Transfer{From: from, To: to, Amount: big.NewInt(1000), Gas: 21000, Status: TransferPlanned}
+	fees := Fees{TipCap: big.NewInt(1), FeeCap: big.NewInt(10)}
+	assert.NoError(suite.T(), t.Sign(chainID, fees, 3, key))
+	assert.Equal(suite.T(), big.NewInt(210000), t.MaxCost())
+
+	tx := new(types.Transaction)
+	assert.NoError(suite.T(), tx.UnmarshalBinary(t.Raw))
+	assert.Equal(suite.T(), uint8(types.DynamicFeeTxType), tx.Type())
+	assert.Equal(suite.T(), t.Hash, tx.Hash())
+	assert.Equal(suite.T(), uint64(3), tx.Nonce())
+	assert.Equal(suite.T(), to, *tx.To())
+	assert.Equal(suite.T(), big.NewInt(1000), tx.Value())
+	sender, err := types.Sender(types.LatestSignerForChainID(chainID), tx)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), from, sender)
+
+	token := &Token{Address: suite.eth.token, Symbol: "USDC", Decimals: 6}
+	t = &Transfer{From: from, To: to, Token: token, Amount: big.NewInt(5), Gas: 60000, Status: TransferPlanned}
+	assert.NoError(suite.T(), t.Sign(chainID, Fees{GasPrice: big.NewInt(7)}, 4, key))
+	assert.NoError(suite.T(), tx.UnmarshalBinary(t.Raw))
+	assert.Equal(suite.T(), uint8(types.LegacyTxType), tx.Type())
+	assert.Equal(suite.T(), token.Address, *tx.To())
+	assert.Zero(suite.T(), tx.Value().Sign())
+	assert.Equal(suite.T(), TransferData(to, big.NewInt(5)), tx.Data())
+	assert.Equal(suite.T(), "USDC", t.Asset())
+}
+
+func (suite *ChainTestSuite) TestJournal() {
+	path := filepath.Join(suite.T().TempDir(), "journal.json")
+	from, to := common.HexToAddress("0x01"), common.HexToAddress("0x02")
+	token := &Token{Address: suite.eth.token, Symbol: "USDC", Decimals: 6}
+
+	journal, err := OpenJournal(path, 1337)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), journal.Transfers)
+	assert.True(suite.T(), journal.Done())
+
+	ether := &Transfer{From: from, To: to, Amount: big.NewInt(1), Status: TransferPending}
+	tokens := &Transfer{From: from, To: to, Token: token, Amount: big.NewInt(2), Status: TransferConfirmed}
+	journal.Add(ether)
+	journal.Add(tokens)
+	assert.NoError(suite.T(), journal.Save())
+
+	journal, err = OpenJournal(path, 1337)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), journal.Transfers, 2)
+	assert.False(suite.T(), journal.Done())
+	assert.Equal(suite.T(), big.NewInt(1), journal.Find(from, to, nil).Amount)
+	assert.Equal(suite.T(), big.NewInt(2), journal.Find(from, to, token).Amount)
+	assert.Nil(suite.T(), journal.Find(to, from, nil))
+
+	journal.Drop(journal.Find(from, to, nil))
+	assert.True(suite.T(), journal.Done())
+
+	_, err = OpenJournal(path, 1)
+	assert.Error(suite.T(), err)
+
+	assert.NoError(suite.T(), journal.Remove())
+	assert.NoFileExists(suite.T(), path)
+	assert.NoError(suite.T(), journal.Remove())
+}
+
+func (suite *ChainTestSuite) TestPlanSweep() {
+	ctx := context.Background()
+	to := common.HexToAddress("0x00000000000000000000000000000000000000ff")
+	token := Token{Address: suite.eth.token, Symbol: "USDC", Decimals: 6}
+	// Ether transfers cost at most 210000 wei, token ones 500000 wei
+	fees := Fees{TipCap: big.NewInt(1), FeeCap: big.NewInt(10)}
+	journal, err := OpenJournal(filepath.Join(suite.T().TempDir(), "journal.json"), 1337)
+	assert.NoError(suite.T(), err)
+
+	plan := func(from common.Address, tokens []Token) []*Transfer {
+		transfers, err := suite.client.PlanSweep(ctx, journal, fees, from, to, tokens)
+		assert.NoError(suite.T(), err)
+		return transfers
+	}
+
+	// Ether only, the balance left once the gas is paid
+	etherOnly := common.HexToAddress("0x01")
+	suite.eth.balances[etherOnly] = big.NewInt(1000000)
+	transfers := plan(etherOnly, nil)
+	if assert.Len(suite.T(), transfers, 1) {
+		assert.Nil(suite.T(), transfers[0].Token)
+		assert.Equal(suite.T(), uint64(21000), transfers[0].Gas)
+		assert.Equal(suite.T(), big.NewInt(790000), transfers[0].Amount)
+		assert.Equal(suite.T(), TransferPlanned, transfers[0].Status)
+	}
+
+	// Tokens first, the ether left paying for their gas
+	withToken := common.HexToAddress("0x02")
+	suite.eth.balances[withToken] = big.NewInt(1000000)
+	suite.eth.tokenBalances[withToken] = big.NewInt(5)
+	transfers = plan(withToken, []Token{token})
+	if assert.Len(suite.T(), transfers, 2) {
+		assert.Equal(suite.T(), suite.eth.token, transfers[0].Token.Address)
+		assert.Equal(suite.T(), big.NewInt(5), transfers[0].Amount)
+		assert.Equal(suite.T(), TransferPlanned, transfers[0].Status)
+		assert.Nil(suite.T(), transfers[1].Token)
+		assert.Equal(suite.T(), big.NewInt(290000), transfers[1].Amount)
+	}
+
+	// Token transfers the ether can't pay the gas of fail, the ether is still swept
+	poor := common.HexToAddress("0x03")
+	suite.eth.balances[poor] = big.NewInt(400000)
+	suite.eth.tokenBalances[poor] = big.NewInt(5)
+	transfers = plan(poor, []Token{token})
+	if assert.Len(suite.T(), transfers, 2) {
+		assert.Equal(suite.T(), TransferFailed, transfers[0].Status)
+		assert.Equal(suite.T(), "not enough ether to pay the gas", transfers[0].Error)
+		assert.Equal(suite.T(), big.NewInt(190000), transfers[1].Amount)
+	}
+
+	// Dust not covering the gas of its transfer is left, as are empty accounts
+	dust := common.HexToAddress("0x04")
+	suite.eth.balances[dust] = big.NewInt(210000)
+	assert.Empty(suite.T(), plan(dust, []Token{token}))
+	assert.Empty(suite.T(), plan(common.HexToAddress("0x05"), []Token{token}))
+
+	// Recorded transfers are reused, failed ones planned again
+	resumed := common.HexToAddress("0x06")
+	suite.eth.balances[resumed] = big.NewInt(1000000)
+	suite.eth.tokenBalances[resumed] = big.NewInt(5)
+	pending := &Transfer{From: resumed, To: to, Amount: big.NewInt(123), Status: TransferPending}
+	failed := &Transfer{From: resumed, To: to, Token: &token, Amount: big.NewInt(1), Status: TransferFailed}
+	journal.Add(pending)
+	journal.Add(failed)
+	transfers = plan(resumed, []Token{token})
+	if assert.Len(suite.T(), transfers, 2) {
+		assert.Equal(suite.T(), TransferPlanned, transfers[0].Status)
+		assert.Equal(suite.T(), big.NewInt(5), transfers[0].Amount)
+		assert.Same(suite.T(), pending, transfers[1])
+	}
+	assert.Nil(suite.T(), journal.Find(resumed, to, &token), "failed transfers are dropped from the journal")
+}
+
+func (suite *ChainTestSuite) TestReadStateDump() {
+	dir := suite.T().TempDir()
+	used, unused := common.HexToAddress("0x01"), common.HexToAddress("0x02")
+
+	object := filepath.Join(dir, "dump.json")
+	assert.NoError(suite.T(), os.WriteFile(object, []byte(`{
+  "root": "0x00",
+  "accounts": {
+    "0x0000000000000000000000000000000000000001": {"balance": "1000", "nonce": 3},
+    "0x0000000000000000000000000000000000000002": {"balance": "0", "nonce": 0},
+    "pre(0x1234)": {"balance": "5", "nonce": 1}
+  }
+}`), 0o600))
+	iterative := filepath.Join(dir, "dump.jsonl")
+	assert.NoError(suite.T(), os.WriteFile(iterative, []byte(`{"root":"0x00"}
+{"balance":"1000","nonce":3,"address":"0x0000000000000000000000000000000000000001","key":"0x01"}
+{"balance":"0","nonce":0,"address":"0x0000000000000000000000000000000000000002","key":"0x02"}
+{"balance":"5","nonce":1,"key":"0x03"}
+`), 0o600))
+
+	for _, path := range []string{object, iterative} {
+		accounts, err := ReadStateDump(path)
+		assert.NoError(suite.T(), err)
+		assert.Len(suite.T(), accounts, 2)
+		assert.Equal(suite.T(), big.NewInt(1000), accounts[used].Balance)
+		assert.Equal(suite.T(), uint64(3), *accounts[used].Nonce)
+		assert.Equal(suite.T(), uint64(0), *accounts[unused].Nonce)
+		assert.True(suite.T(), accounts[used].Used())
+		assert.False(suite.T(), accounts[unused].Used(), "dumped accounts without nonce nor balance are unused")
+	}
+
+	invalid := filepath.Join(dir, "invalid.json")
+	assert.NoError(suite.T(), os.WriteFile(invalid, []byte(`{"accounts":{"0x0000000000000000000000000000000000000001":{"balance":"x"}}}`), 0o600))
+	_, err := ReadStateDump(invalid)
+	assert.Error(suite.T(), err)
+}
+
+func (suite *ChainTestSuite) TestReadAddressList() {
+	path := filepath.Join(suite.T().TempDir(), "addresses.csv")
+	assert.NoError(suite.T(), os.WriteFile(path, []byte("address,balance\n0x0000000000000000000000000000000000000001,1\n"+
+		`{"from":"0x00000000000000000000000000000000000000aa","to":"0x00000000000000000000000000000000000000BB"}`+"\n"), 0o600))
+
+	addresses, err := ReadAddressList(path)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), addresses, 3)
+	assert.True(suite.T(), addresses[common.HexToAddress("0x01")])
+	assert.True(suite.T(), addresses[common.HexToAddress("0xbb")])
+	assert.False(suite.T(), addresses[common.HexToAddress("0x02")])
+}
+
+func TestChainTestSuite(t *testing.T) {
+	suite.Run(t, new(ChainTestSuite))
+}
@@ -0,0 +1,120 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// Client is a JSON-RPC client of an execution node, sending at most a given number of requests per second.
+type Client struct {
+	eth    *ethclient.Client
+	ticker *time.Ticker
+}
+
+// Dial connects to the node at url, which can be an HTTP, WebSocket or IPC endpoint. rate is the maximum number of
+// requests sent per second, unlimited when 0.
+func Dial(ctx context.Context, url string, rate float64) (*Client, error) {
+	eth, err := ethclient.DialContext(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", url, err)
+	}
+	c := &Client{eth: eth}
+	if rate > 0 {
+		c.ticker = time.NewTicker(time.Duration(float64(time.Second) / rate))
+	}
+	return c, nil
+}
+
+// Close closes the connection to the node.
+func (c *Client) Close() {
+	if c.ticker != nil {
+		c.ticker.Stop()
+	}
+	c.eth.Close()
+}
+
+// wait blocks until the next request may be sent.
+func (c *Client) wait(ctx context.Context) error {
+	if c.ticker == nil {
+		return ctx.Err()
+	}
+	select {
+	case <-c.ticker.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ChainID returns the chain ID of the node.
+func (c *Client) ChainID(ctx context.Context) (*big.Int, error) {
+	if err := c.wait(ctx); err != nil {
+		return nil, err
+	}
+	return c.eth.ChainID(ctx)
+}
+
+// Balance returns the balance of the account at the latest block, in wei.
+func (c *Client) Balance(ctx context.Context, account common.Address) (*big.Int, error) {
+	if err := c.wait(ctx); err != nil {
+		return nil, err
+	}
+	balance, err := c.eth.BalanceAt(ctx, account, nil)
+	if err != nil {
+		// ethclient returns a zero balance along with errors
+		return nil, err
+	}
+	return balance, nil
+}
+
+// Nonce returns the number of transactions sent by the account, at the latest block.
+func (c *Client) Nonce(ctx context.Context, account common.Address) (uint64, error) {
+	if err := c.wait(ctx); err != nil {
+		return 0, err
+	}
+	return c.eth.NonceAt(ctx, account, nil)
+}
+
+// PendingNonce returns the next nonce of the account, counting its transactions waiting in the pool of the node.
+func (c *Client) PendingNonce(ctx context.Context, account common.Address) (uint64, error) {
+	if err := c.wait(ctx); err != nil {
+		return 0, err
+	}
+	return c.eth.PendingNonceAt(ctx, account)
+}
+
+// EstimateGas estimates the gas used by a transaction.
+func (c *Client) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	if err := c.wait(ctx); err != nil {
+		return 0, err
+	}
+	return c.eth.EstimateGas(ctx, msg)
+}
+
+// Receipt returns the receipt of a mined transaction, or nil when the transaction isn't mined yet.
+func (c *Client) Receipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	if err := c.wait(ctx); err != nil {
+		return nil, err
+	}
+	receipt, err := c.eth.TransactionReceipt(ctx, hash)
+	if errors.Is(err, ethereum.NotFound) {
+		return nil, nil
+	}
+	return receipt, err
+}
+
+// call executes a read-only contract call at the latest block.
+func (c *Client) call(ctx context.Context, to common.Address, data []byte) ([]byte, error) {
+	if err := c.wait(ctx); err != nil {
+		return nil, err
+	}
+	return c.eth.CallContract(ctx, ethereum.CallMsg{To: &to, Data: data}, nil)
+}
@@ -0,0 +1,97 @@
+package chain
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// addressRegex matches the hex addresses of an address list.
+var addressRegex = regexp.MustCompile(`0x[0-9a-fA-F]{40}`)
+
+// dumpAccount is an account of a geth state dump.
+type dumpAccount struct {
+	Address *common.Address `json:"address"`
+	Balance string          `json:"balance"`
+	Nonce   uint64          `json:"nonce"`
+}
+
+// ReadStateDump reads the accounts of a state dump written by geth dump, either a single JSON object holding the
+// accounts, or one account per line as written with --iterative. Accounts dumped without their address, because the
+// node has no preimage of their hash, are skipped.
+func ReadStateDump(path string) (map[common.Address]AccountStatus, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state dump: %w", err)
+	}
+	defer f.Close()
+
+	accounts := map[common.Address]AccountStatus{}
+	add := func(key string, account dumpAccount) error {
+		address := account.Address
+		if address == nil {
+			if !common.IsHexAddress(key) {
+				return nil
+			}
+			a := common.HexToAddress(key)
+			address = &a
+		}
+		balance, ok := new(big.Int).SetString(account.Balance, 10)
+		if !ok {
+			return fmt.Errorf("invalid balance %q of %s", account.Balance, address.Hex())
+		}
+		nonce := account.Nonce
+		accounts[*address] = AccountStatus{Address: *address, Balance: balance, Nonce: &nonce}
+		return nil
+	}
+
+	decoder := json.NewDecoder(bufio.NewReader(f))
+	for {
+		var entry struct {
+			dumpAccount
+			Accounts map[string]dumpAccount `json:"accounts"`
+		}
+		if err := decoder.Decode(&entry); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to decode state dump %s: %w", path, err)
+		}
+
+		if entry.Accounts != nil {
+			for key, account := range entry.Accounts {
+				if err := add(key, account); err != nil {
+					return nil, fmt.Errorf("invalid state dump %s: %w", path, err)
+				}
+			}
+		} else if entry.Address != nil {
+			if err := add("", entry.dumpAccount); err != nil {
+				return nil, fmt.Errorf("invalid state dump %s: %w", path, err)
+			}
+		}
+	}
+	return accounts, nil
+}
+
+// ReadAddressList reads the addresses of a file, taking every hex address found on its lines, so plain lists as
+// well as CSV or JSON exports are accepted.
+func ReadAddressList(path string) (map[common.Address]bool, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read address list: %w", err)
+	}
+
+	addresses := map[common.Address]bool{}
+	for _, line := range strings.Split(string(content), "\n") {
+		for _, match := range addressRegex.FindAllString(line, -1) {
+			addresses[common.HexToAddress(match)] = true
+		}
+	}
+	return addresses, nil
+}
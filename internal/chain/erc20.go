@@ -0,0 +1,109 @@
+package chain
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+)
+
+// Selectors of the ERC-20 functions used by ethw.
+var (
+	selectorBalanceOf = []byte{0x70, 0xa0, 0x82, 0x31}
+	selectorDecimals  = []byte{0x31, 0x3c, 0xe5, 0x67}
+	selectorSymbol    = []byte{0x95, 0xd8, 0x9b, 0x41}
+	selectorTransfer  = []byte{0xa9, 0x05, 0x9c, 0xbb}
+)
+
+// errEmptyResult is returned when a call returns nothing, e.g. because the address holds no contract.
+var errEmptyResult = errors.New("empty result, is it an ERC-20 contract?")
+
+// Token is an ERC-20 token contract.
+type Token struct {
+	Address  common.Address
+	Symbol   string
+	Decimals uint8
+}
+
+// Token reads the symbol and decimals of the ERC-20 token contract at address.
+func (c *Client) Token(ctx context.Context, address common.Address) (Token, error) {
+	token := Token{Address: address}
+
+	result, err := c.call(ctx, address, selectorDecimals)
+	if err != nil {
+		return token, fmt.Errorf("failed to read decimals of token %s: %w", address.Hex(), err)
+	}
+	decimals, err := decodeUint(result)
+	if err != nil {
+		return token, fmt.Errorf("invalid decimals of token %s: %w", address.Hex(), err)
+	}
+	if !decimals.IsUint64() || decimals.Uint64() > 255 {
+		return token, fmt.Errorf("invalid decimals of token %s: %s", address.Hex(), decimals)
+	}
+	token.Decimals = uint8(decimals.Uint64())
+
+	// The symbol is optional in ERC-20, and some early tokens return it as bytes32
+	if result, err = c.call(ctx, address, selectorSymbol); err == nil {
+		token.Symbol = decodeSymbol(result)
+	}
+	if token.Symbol == "" {
+		token.Symbol = address.Hex()
+	}
+	return token, nil
+}
+
+// TokenBalance returns the balance of the account in the ERC-20 token, in its smallest unit.
+func (c *Client) TokenBalance(ctx context.Context, token, account common.Address) (*big.Int, error) {
+	result, err := c.call(ctx, token, append(append([]byte{}, selectorBalanceOf...), common.LeftPadBytes(account.Bytes(), 32)...))
+	if err != nil {
+		return nil, err
+	}
+	return decodeUint(result)
+}
+
+// TransferData returns the call data of an ERC-20 transfer of amount to the recipient.
+func TransferData(to common.Address, amount *big.Int) []byte {
+	data := append([]byte{}, selectorTransfer...)
+	data = append(data, common.LeftPadBytes(to.Bytes(), 32)...)
+	return append(data, math.U256Bytes(new(big.Int).Set(amount))...)
+}
+
+// decodeUint decodes a uint256 return value.
+func decodeUint(result []byte) (*big.Int, error) {
+	if len(result) == 0 {
+		return nil, errEmptyResult
+	}
+	if len(result) < 32 {
+		return nil, fmt.Errorf("invalid result of %d bytes", len(result))
+	}
+	return new(big.Int).SetBytes(result[:32]), nil
+}
+
+// decodeSymbol decodes a symbol returned as an ABI encoded string or as a zero padded bytes32.
+func decodeSymbol(result []byte) string {
+	if len(result) >= 64 {
+		offset, length := new(big.Int).SetBytes(result[:32]), new(big.Int).SetBytes(result[32:64])
+		if offset.Cmp(big.NewInt(32)) == 0 && length.IsUint64() && 64+length.Uint64() <= uint64(len(result)) {
+			return sanitizeSymbol(result[64 : 64+length.Uint64()])
+		}
+	}
+	if len(result) == 32 {
+		return sanitizeSymbol(bytes.TrimRight(result, "\x00"))
+	}
+	return ""
+}
+
+// sanitizeSymbol keeps the printable ASCII characters of a symbol, which is shown in terminals and column headers.
+func sanitizeSymbol(symbol []byte) string {
+	var sanitized []byte
+	for _, b := range symbol {
+		if b > ' ' && b < 0x7f {
+			sanitized = append(sanitized, b)
+		}
+	}
+	return string(sanitized)
+}
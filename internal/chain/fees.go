@@ -0,0 +1,100 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Fees are the prices paid per unit of gas by a transaction.
+type Fees struct {
+	// TipCap and FeeCap are the priority fee and maximum fee of EIP-1559 transactions.
+	TipCap *big.Int
+	FeeCap *big.Int
+	// GasPrice is only set on chains without EIP-1559, where legacy transactions are sent.
+	GasPrice *big.Int
+}
+
+// Legacy reports whether the fees are for legacy transactions.
+func (f Fees) Legacy() bool {
+	return f.GasPrice != nil
+}
+
+// Max returns the maximum price paid per unit of gas.
+func (f Fees) Max() *big.Int {
+	if f.Legacy() {
+		return f.GasPrice
+	}
+	return f.FeeCap
+}
+
+// SuggestFees suggests EIP-1559 fees from the base fee of the latest block: the priority fee suggested by the node
+// and a maximum fee of twice the base fee plus the priority fee, covering six blocks of base fee increases. tipCap and
+// feeCap override the suggestions when not nil. On chains without a base fee the gas price suggested by the node,
+// or feeCap, is used for legacy transactions.
+func (c *Client) SuggestFees(ctx context.Context, tipCap, feeCap *big.Int) (Fees, error) {
+	if err := c.wait(ctx); err != nil {
+		return Fees{}, err
+	}
+	header, err := c.eth.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return Fees{}, fmt.Errorf("failed to read latest block: %w", err)
+	}
+
+	if header.BaseFee == nil {
+		if feeCap != nil {
+			return Fees{GasPrice: feeCap}, nil
+		}
+		if err := c.wait(ctx); err != nil {
+			return Fees{}, err
+		}
+		gasPrice, err := c.eth.SuggestGasPrice(ctx)
+		if err != nil {
+			return Fees{}, fmt.Errorf("failed to suggest gas price: %w", err)
+		}
+		return Fees{GasPrice: gasPrice}, nil
+	}
+
+	if tipCap == nil {
+		if err := c.wait(ctx); err != nil {
+			return Fees{}, err
+		}
+		if tipCap, err = c.eth.SuggestGasTipCap(ctx); err != nil {
+			return Fees{}, fmt.Errorf("failed to suggest priority fee: %w", err)
+		}
+	}
+	if feeCap == nil {
+		feeCap = new(big.Int).Add(new(big.Int).Mul(header.BaseFee, big.NewInt(2)), tipCap)
+	}
+	if feeCap.Cmp(tipCap) < 0 {
+		return Fees{}, fmt.Errorf("maximum fee %s is lower than the priority fee %s", feeCap, tipCap)
+	}
+	return Fees{TipCap: tipCap, FeeCap: feeCap}, nil
+}
+
+// NewTx returns an unsigned EIP-1559 transaction, or a legacy one for legacy fees.
+func NewTx(chainID *big.Int, fees Fees, nonce uint64, to common.Address, value *big.Int, gas uint64, data []byte) *types.Transaction {
+	if fees.Legacy() {
+		return types.NewTx(&types.LegacyTx{
+			Nonce:    nonce,
+			GasPrice: fees.GasPrice,
+			Gas:      gas,
+			To:       &to,
+			Value:    value,
+			Data:     data,
+		})
+	}
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     nonce,
+		GasTipCap: fees.TipCap,
+		GasFeeCap: fees.FeeCap,
+		Gas:       gas,
+		To:        &to,
+		Value:     value,
+		Data:      data,
+	})
+}
@@ -0,0 +1,122 @@
+package chain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Journal records the transfers of a run in a file, so an interrupted run resumes them instead of sending them
+// twice. Transfers are recorded once signed, before they're sent.
+type Journal struct {
+	path      string
+	ChainID   uint64      `json:"chain_id"`
+	Transfers []*Transfer `json:"transfers"`
+}
+
+// OpenJournal reads the journal at path, returning an empty one when the file doesn't exist. Journals written on
+// another chain are rejected.
+func OpenJournal(path string, chainID uint64) (*Journal, error) {
+	journal := &Journal{path: path, ChainID: chainID}
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return journal, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	if err := json.Unmarshal(content, journal); err != nil {
+		return nil, fmt.Errorf("failed to decode journal %s: %w", path, err)
+	}
+	if journal.ChainID != chainID {
+		return nil, fmt.Errorf("journal %s was written on chain %d, not %d", path, journal.ChainID, chainID)
+	}
+	return journal, nil
+}
+
+// Path returns the path of the journal file.
+func (j *Journal) Path() string {
+	return j.path
+}
+
+// Find returns the recorded transfer of the asset between the accounts, or nil.
+func (j *Journal) Find(from, to common.Address, token *Token) *Transfer {
+	for _, t := range j.Transfers {
+		if t.From != from || t.To != to || (t.Token == nil) != (token == nil) {
+			continue
+		}
+		if token == nil || t.Token.Address == token.Address {
+			return t
+		}
+	}
+	return nil
+}
+
+// Add records a transfer.
+func (j *Journal) Add(t *Transfer) {
+	j.Transfers = append(j.Transfers, t)
+}
+
+// Drop removes a transfer which was never sent.
+func (j *Journal) Drop(t *Transfer) {
+	for i := range j.Transfers {
+		if j.Transfers[i] == t {
+			j.Transfers = append(j.Transfers[:i], j.Transfers[i+1:]...)
+			return
+		}
+	}
+}
+
+// Done reports whether every recorded transfer is confirmed.
+func (j *Journal) Done() bool {
+	for _, t := range j.Transfers {
+		if t.Status != TransferConfirmed {
+			return false
+		}
+	}
+	return true
+}
+
+// Save writes the journal, replacing the file atomically.
+func (j *Journal) Save() error {
+	content, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode journal: %w", err)
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(j.path), "."+filepath.Base(j.path)+".tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create journal: %w", err)
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+
+	if err := os.Rename(f.Name(), j.path); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	return nil
+}
+
+// Remove deletes the journal file, once every transfer is done.
+func (j *Journal) Remove() error {
+	if err := os.Remove(j.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove journal: %w", err)
+	}
+	return nil
+}
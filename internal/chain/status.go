@@ -0,0 +1,72 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// AccountStatus is the on-chain state of an account.
+type AccountStatus struct {
+	Address common.Address
+	// Balance and Nonce are nil when they couldn't be looked up.
+	Balance *big.Int
+	Nonce   *uint64
+	// TokenBalances holds the balance in every requested token, in the order of the tokens.
+	TokenBalances []*big.Int
+	Err           error
+}
+
+// WalletStatus is the on-chain state of a wallet account, with the alias and derivation path it's known by.
+type WalletStatus struct {
+	Alias          string
+	DerivationPath string
+	AccountStatus
+}
+
+// ErrorMessage returns the error message of a failed lookup, or an empty string.
+func (s AccountStatus) ErrorMessage() string {
+	if s.Err == nil {
+		return ""
+	}
+	return s.Err.Error()
+}
+
+// Used reports whether the account was used, i.e. sent a transaction or holds ether. Accounts whose balance or nonce
+// are unknown count as unused.
+func (s AccountStatus) Used() bool {
+	return s.Nonce != nil && *s.Nonce > 0 || s.Balance != nil && s.Balance.Sign() > 0
+}
+
+// Status looks up the balance, nonce and token balances of the account. Lookups failing are reported in the status
+// error, leaving their values nil; the others are still made.
+func (c *Client) Status(ctx context.Context, account common.Address, tokens []Token) AccountStatus {
+	status := AccountStatus{Address: account, TokenBalances: make([]*big.Int, len(tokens))}
+	var errs []string
+
+	var err error
+	if status.Balance, err = c.Balance(ctx, account); err != nil {
+		errs = append(errs, fmt.Sprintf("balance: %v", err))
+	}
+
+	if nonce, err := c.Nonce(ctx, account); err != nil {
+		errs = append(errs, fmt.Sprintf("nonce: %v", err))
+	} else {
+		status.Nonce = &nonce
+	}
+
+	for i, token := range tokens {
+		if status.TokenBalances[i], err = c.TokenBalance(ctx, token.Address, account); err != nil {
+			errs = append(errs, fmt.Sprintf("%s balance: %v", token.Symbol, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		status.Err = errors.New(strings.Join(errs, "; "))
+	}
+	return status
+}
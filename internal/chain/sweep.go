@@ -0,0 +1,78 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// PlanSweep returns the transfers sweeping the tokens and then the ether of the account to the target, reusing the
+// transfers recorded in the journal. The ether sent is the balance left once the maximum fees of every transfer are
+// paid, and isn't sent at all when it doesn't cover the gas of its own transfer; token transfers the account can't
+// pay the gas of are reported failed without being sent.
+func (c *Client) PlanSweep(ctx context.Context, journal *Journal, fees Fees, from, to common.Address, tokens []Token) ([]*Transfer, error) {
+	// recorded returns the transfer of the asset recorded in the journal, failed transfers are planned again
+	recorded := func(token *Token) *Transfer {
+		t := journal.Find(from, to, token)
+		if t != nil && t.Status == TransferFailed {
+			journal.Drop(t)
+			return nil
+		}
+		return t
+	}
+
+	var transfers, planned []*Transfer
+	for i := range tokens {
+		token := &tokens[i]
+		if t := recorded(token); t != nil {
+			transfers = append(transfers, t)
+			continue
+		}
+
+		balance, err := c.TokenBalance(ctx, token.Address, from)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s balance of %s: %w", token.Symbol, from.Hex(), err)
+		}
+		if balance.Sign() == 0 {
+			continue
+		}
+		t := &Transfer{From: from, To: to, Token: token, Amount: balance, MaxFee: fees.Max(), Status: TransferPlanned}
+		if err := c.EstimateTransfer(ctx, t); err != nil {
+			return nil, err
+		}
+		planned = append(planned, t)
+	}
+
+	balance, err := c.Balance(ctx, from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read balance of %s: %w", from.Hex(), err)
+	}
+	left := new(big.Int).Set(balance)
+	for _, t := range planned {
+		if left.Cmp(t.MaxCost()) < 0 {
+			t.Status, t.Error = TransferFailed, "not enough ether to pay the gas"
+		} else {
+			left.Sub(left, t.MaxCost())
+		}
+		transfers = append(transfers, t)
+	}
+
+	if t := recorded(nil); t != nil {
+		return append(transfers, t), nil
+	}
+	if left.Sign() == 0 {
+		return transfers, nil
+	}
+	// The gas is estimated sending 1 wei, as the balance left can't pay for it on top of itself
+	ether := &Transfer{From: from, To: to, Amount: big.NewInt(1), MaxFee: fees.Max(), Status: TransferPlanned}
+	if err := c.EstimateTransfer(ctx, ether); err != nil {
+		return nil, err
+	}
+	if left.Cmp(ether.MaxCost()) <= 0 {
+		return transfers, nil
+	}
+	ether.Amount = left.Sub(left, ether.MaxCost())
+	return append(transfers, ether), nil
+}
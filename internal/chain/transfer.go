@@ -0,0 +1,205 @@
+package chain
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Status of a transfer.
+const (
+	// TransferPlanned transfers aren't signed nor sent yet.
+	TransferPlanned = "planned"
+	// TransferPending transfers were signed and sent, or are about to be, and wait to be mined.
+	TransferPending = "pending"
+	// TransferConfirmed transfers were mined successfully.
+	TransferConfirmed = "confirmed"
+	// TransferFailed transfers were rejected by the node, reverted, or lost their nonce to another transaction.
+	TransferFailed = "failed"
+)
+
+// Transfer is a transfer of ether, or of an ERC-20 token, between two accounts.
+type Transfer struct {
+	From common.Address `json:"from"`
+	To   common.Address `json:"to"`
+	// Token is nil for ether transfers.
+	Token  *Token   `json:"token,omitempty"`
+	Amount *big.Int `json:"amount"`
+	Nonce  uint64   `json:"nonce"`
+	Gas    uint64   `json:"gas"`
+	// MaxFee is the maximum price paid per unit of gas.
+	MaxFee *big.Int    `json:"max_fee_per_gas,omitempty"`
+	Hash   common.Hash `json:"hash"`
+	// Raw is the signed transaction, sent again when an interrupted run resumes.
+	Raw    hexutil.Bytes `json:"raw,omitempty"`
+	Status string        `json:"status"`
+	Block  uint64        `json:"block,omitempty"`
+	Error  string        `json:"error,omitempty"`
+}
+
+// WalletTransfer is a transfer funding or sweeping a wallet account, with the alias and derivation path it's known by.
+type WalletTransfer struct {
+	Alias          string
+	DerivationPath string
+	*Transfer
+}
+
+// Asset returns the symbol of the transferred token, ETH for ether transfers.
+func (t *Transfer) Asset() string {
+	if t.Token == nil {
+		return "ETH"
+	}
+	return t.Token.Symbol
+}
+
+// Decimals returns the decimals of the transferred amount.
+func (t *Transfer) Decimals() uint8 {
+	if t.Token == nil {
+		return EtherDecimals
+	}
+	return t.Token.Decimals
+}
+
+// MaxCost returns the maximum fee paid for the gas of the transfer.
+func (t *Transfer) MaxCost() *big.Int {
+	if t.MaxFee == nil {
+		return nil
+	}
+	return new(big.Int).Mul(t.MaxFee, new(big.Int).SetUint64(t.Gas))
+}
+
+// call returns the recipient, value and data of the transaction making the transfer.
+func (t *Transfer) call() (common.Address, *big.Int, []byte) {
+	if t.Token == nil {
+		return t.To, t.Amount, nil
+	}
+	return t.Token.Address, new(big.Int), TransferData(t.To, t.Amount)
+}
+
+// EstimateTransfer estimates the gas of the transfer and records it.
+func (c *Client) EstimateTransfer(ctx context.Context, t *Transfer) error {
+	to, value, data := t.call()
+	gas, err := c.EstimateGas(ctx, ethereum.CallMsg{From: t.From, To: &to, Value: value, Data: data})
+	if err != nil {
+		return fmt.Errorf("failed to estimate gas of transfer to %s: %w", t.To.Hex(), err)
+	}
+	t.Gas = gas
+	return nil
+}
+
+// Sign signs the transfer with the given nonce and fees, with the latest signer of the chain.
+func (t *Transfer) Sign(chainID *big.Int, fees Fees, nonce uint64, key *ecdsa.PrivateKey) error {
+	to, value, data := t.call()
+	tx, err := types.SignTx(NewTx(chainID, fees, nonce, to, value, t.Gas, data), types.LatestSignerForChainID(chainID), key)
+	if err != nil {
+		return fmt.Errorf("failed to sign transfer to %s: %w", t.To.Hex(), err)
+	}
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to encode transfer to %s: %w", t.To.Hex(), err)
+	}
+
+	t.Nonce, t.MaxFee, t.Hash, t.Raw = nonce, fees.Max(), tx.Hash(), raw
+	return nil
+}
+
+// Submit sends a signed transfer to the node and marks it pending. Sending a transaction the node already knows is
+// fine, so transfers of interrupted runs are submitted again.
+func (c *Client) Submit(ctx context.Context, t *Transfer) error {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(t.Raw); err != nil {
+		return fmt.Errorf("failed to decode transfer to %s: %w", t.To.Hex(), err)
+	}
+	if err := c.wait(ctx); err != nil {
+		return err
+	}
+	if err := c.eth.SendTransaction(ctx, tx); err != nil && !knownTransaction(err) {
+		return fmt.Errorf("failed to send transfer to %s: %w", t.To.Hex(), err)
+	}
+	t.Status = TransferPending
+	return nil
+}
+
+// Resume checks a transfer sent by an interrupted run: mined transfers are settled from their receipt, the others
+// are submitted again unless their nonce was used by another transaction meanwhile.
+func (c *Client) Resume(ctx context.Context, t *Transfer) error {
+	if done, err := c.settle(ctx, t); err != nil || done {
+		return err
+	}
+	nonce, err := c.Nonce(ctx, t.From)
+	if err != nil {
+		return err
+	}
+	if nonce > t.Nonce {
+		// The nonce was used meanwhile, but the transfer may have been mined just before reading it
+		if done, err := c.settle(ctx, t); err != nil || done {
+			return err
+		}
+		t.Status, t.Error = TransferFailed, fmt.Sprintf("nonce %d of %s was used by another transaction", t.Nonce, t.From.Hex())
+		return nil
+	}
+	return c.Submit(ctx, t)
+}
+
+// Wait polls the receipts of the pending transfers every interval until all of them are mined, or ctx is done.
+func (c *Client) Wait(ctx context.Context, transfers []*Transfer, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		pending := 0
+		for _, t := range transfers {
+			if t.Status != TransferPending {
+				continue
+			}
+			if done, err := c.settle(ctx, t); err != nil {
+				return err
+			} else if !done {
+				pending++
+			}
+		}
+		if pending == 0 {
+			return nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// settle updates the transfer from its receipt, reporting whether it was mined.
+func (c *Client) settle(ctx context.Context, t *Transfer) (bool, error) {
+	receipt, err := c.Receipt(ctx, t.Hash)
+	if err != nil {
+		return false, fmt.Errorf("failed to read receipt of %s: %w", t.Hash.Hex(), err)
+	}
+	if receipt == nil {
+		return false, nil
+	}
+
+	t.Block = receipt.BlockNumber.Uint64()
+	if receipt.Status == types.ReceiptStatusSuccessful {
+		t.Status, t.Error = TransferConfirmed, ""
+	} else {
+		t.Status, t.Error = TransferFailed, "transaction reverted"
+	}
+	return true, nil
+}
+
+// knownTransaction reports whether the node rejected a transaction because it already has it, as geth, Anvil and
+// most clients word it.
+func knownTransaction(err error) bool {
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "already known") || strings.Contains(message, "known transaction") ||
+		strings.Contains(message, "already imported")
+}
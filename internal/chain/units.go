@@ -0,0 +1,82 @@
+package chain
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// EtherDecimals is the number of decimals of ether amounts expressed in wei.
+const EtherDecimals = 18
+
+// GweiDecimals is the number of decimals of gwei amounts expressed in wei.
+const GweiDecimals = 9
+
+// FormatUnits formats an amount of the smallest unit of a token with the given decimals, e.g. wei as ether, without
+// trailing zeros.
+func FormatUnits(amount *big.Int, decimals uint8) string {
+	if amount == nil {
+		return ""
+	}
+	digits := new(big.Int).Abs(amount).String()
+	sign := ""
+	if amount.Sign() < 0 {
+		sign = "-"
+	}
+	if decimals == 0 {
+		return sign + digits
+	}
+
+	if len(digits) <= int(decimals) {
+		digits = strings.Repeat("0", int(decimals)-len(digits)+1) + digits
+	}
+	whole, fraction := digits[:len(digits)-int(decimals)], strings.TrimRight(digits[len(digits)-int(decimals):], "0")
+	if fraction == "" {
+		return sign + whole
+	}
+	return sign + whole + "." + fraction
+}
+
+// ParseUnits parses a decimal amount, e.g. 0.5, into the smallest unit of a token with the given decimals. A unit
+// suffix of wei, gwei or ether is accepted for ether amounts, e.g. 30gwei.
+func ParseUnits(raw string, decimals uint8) (*big.Int, error) {
+	value := strings.TrimSpace(strings.ToLower(raw))
+	if decimals == EtherDecimals {
+		for _, unit := range []struct {
+			suffix   string
+			decimals uint8
+		}{{"gwei", 9}, {"wei", 0}, {"ether", EtherDecimals}, {"eth", EtherDecimals}} {
+			if strings.HasSuffix(value, unit.suffix) {
+				value, decimals = strings.TrimSpace(strings.TrimSuffix(value, unit.suffix)), unit.decimals
+				break
+			}
+		}
+	}
+
+	whole, fraction, _ := strings.Cut(value, ".")
+	if whole == "" && fraction == "" || strings.Trim(whole+fraction, "0123456789") != "" {
+		return nil, fmt.Errorf("invalid amount %q", raw)
+	}
+	if len(fraction) > int(decimals) {
+		if strings.Trim(fraction[decimals:], "0") != "" {
+			return nil, fmt.Errorf("invalid amount %q: more than %d decimals", raw, decimals)
+		}
+		fraction = fraction[:decimals]
+	}
+
+	amount, ok := new(big.Int).SetString(whole+fraction+strings.Repeat("0", int(decimals)-len(fraction)), 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount %q", raw)
+	}
+	return amount, nil
+}
+
+// ParseFee parses a fee per gas into wei. Fees are quoted in gwei, so an amount without unit, e.g. 1.5, is read as
+// gwei; the units of ParseUnits are accepted too, e.g. 100wei.
+func ParseFee(raw string) (*big.Int, error) {
+	value := strings.TrimSpace(raw)
+	if value != "" && strings.ContainsRune("0123456789.", rune(value[len(value)-1])) {
+		return ParseUnits(value, GweiDecimals)
+	}
+	return ParseUnits(value, EtherDecimals)
+}
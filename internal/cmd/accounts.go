@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+
+	"github.com/aldoborrero/ethw/internal/keystore"
+	"github.com/aldoborrero/ethw/internal/wallet"
+	"github.com/alecthomas/kong"
+	"github.com/charmbracelet/log"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// accountOptions groups the flags selecting accounts derived from a mnemonic over an index range, or the accounts of
+// a keystore.
+type accountOptions struct {
+	KeystoreDir  string   `flag:"" optional:"" type:"path" help:"Use the accounts of this keystore directory (./keystore by default)"`
+	Account      []string `flag:"" optional:"" help:"Keystore account to use, by address or alias, repeatable (every account by default)"`
+	MnemonicFile string   `flag:"" optional:"" type:"existingfile" help:"Use the accounts derived from the mnemonic stored in this file instead of a keystore"`
+	Scheme       string   `flag:"" optional:"" default:"bip44" help:"Derivation path scheme used with --mnemonic-file: bip44, ledger-live, ledger-legacy or a template such as \"m/44'/60'/0'/0/%d\""`
+	Range        string   `flag:"" optional:"" default:"0-9" help:"Inclusive range of account indexes derived with --mnemonic-file"`
+}
+
+// selectedAccount is an account selected with accountOptions.
+type selectedAccount struct {
+	// Index is the derivation index of accounts derived from a mnemonic, and the position of keystore accounts.
+	Index          int
+	Alias          string
+	Address        common.Address
+	DerivationPath string
+	// key is the private key of accounts derived from a mnemonic, keystore accounts have to be unlocked.
+	key *ecdsa.PrivateKey
+}
+
+// keystoreDir returns the keystore directory, ./keystore by default.
+func (o accountOptions) keystoreDir() string {
+	if o.KeystoreDir == "" {
+		return "./keystore"
+	}
+	return kong.ExpandPath(o.KeystoreDir)
+}
+
+// accounts returns the selected accounts, without unlocking keystore accounts.
+func (o accountOptions) accounts() ([]selectedAccount, error) {
+	if o.KeystoreDir != "" && o.MnemonicFile != "" {
+		return nil, errors.New("accounts come either from --keystore-dir or from --mnemonic-file")
+	}
+	if o.MnemonicFile != "" {
+		if len(o.Account) > 0 {
+			return nil, errors.New("--account selects keystore accounts, use --range with --mnemonic-file")
+		}
+		return o.mnemonicAccounts()
+	}
+	return o.keystoreAccounts()
+}
+
+// mnemonicAccounts derives the accounts of the mnemonic over the range.
+func (o accountOptions) mnemonicAccounts() ([]selectedAccount, error) {
+	mnemonic, err := readMnemonicFile(kong.ExpandPath(o.MnemonicFile))
+	if err != nil {
+		return nil, err
+	}
+	start, end, err := wallet.ParseRange(o.Range)
+	if err != nil {
+		return nil, err
+	}
+	wallets, err := wallet.NewWallets(mnemonic, o.Scheme, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := make([]selectedAccount, len(wallets))
+	for i, w := range wallets {
+		key, err := crypto.HexToECDSA(w.PrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode private key of account %d: %w", start+i, err)
+		}
+		selected[i] = selectedAccount{
+			Index:          start + i,
+			Address:        common.HexToAddress(w.Address),
+			DerivationPath: w.DerivationPath,
+			key:            key,
+		}
+	}
+	return selected, nil
+}
+
+// keystoreAccounts returns the accounts of the keystore given with --account, or all of them, with the alias and
+// derivation path recorded in the keystore metadata.
+func (o accountOptions) keystoreAccounts() ([]selectedAccount, error) {
+	ks := keystore.NewKeyStore(o.keystoreDir())
+	metadata, err := ks.Metadata()
+	if err != nil {
+		return nil, err
+	}
+
+	var addresses []common.Address
+	if len(o.Account) == 0 {
+		for _, account := range ks.Accounts() {
+			addresses = append(addresses, account.Address)
+		}
+	}
+	for _, ref := range o.Account {
+		info, err := resolveKeystoreAccount(ks, metadata, ref)
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, info.Address)
+	}
+
+	selected := make([]selectedAccount, len(addresses))
+	for i, address := range addresses {
+		meta := metadata.Get(address)
+		selected[i] = selectedAccount{Index: i, Alias: meta.Alias, Address: address}
+		if meta.Source != nil {
+			selected[i].DerivationPath = meta.Source.Path
+		}
+	}
+	return selected, nil
+}
+
+// unlock returns the private keys of the accounts, decrypting keystore accounts with passwords read in order.
+func (o accountOptions) unlock(accounts []selectedAccount, passwordOpts passwordOptions) ([]*ecdsa.PrivateKey, error) {
+	keys := make([]*ecdsa.PrivateKey, len(accounts))
+	var pending []int
+	for i, account := range accounts {
+		if account.key != nil {
+			keys[i] = account.key
+		} else {
+			pending = append(pending, i)
+		}
+	}
+	if len(pending) == 0 {
+		return keys, nil
+	}
+
+	passwords, err := resolvePasswordSource(passwordOpts, nil, false, false)
+	if err != nil {
+		return nil, err
+	}
+	ks := keystore.NewKeyStore(o.keystoreDir())
+	for n, i := range pending {
+		address := accounts[i].Address
+		walletPassword, err := passwordFor(passwords, n, address.Hex())
+		if err != nil {
+			return nil, err
+		}
+		log.Infof("Unlocking account %s", address.Hex())
+		key, err := ks.DecryptKey(address, walletPassword)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unlock account %s: %w", address.Hex(), err)
+		}
+		keys[i] = key.PrivateKey
+	}
+	return keys, nil
+}
@@ -1,21 +1,63 @@
 package cmd
 
 import (
+	"strconv"
 	"time"
 
+	"github.com/aldoborrero/ethw/internal/password"
+	"github.com/alecthomas/kong"
 	"github.com/charmbracelet/log"
 )
 
+// Vars are the variables interpolated in the flag tags of Cli.
+var Vars = kong.Vars{
+	"generated_password_length": strconv.Itoa(password.DefaultGeneratedLength),
+}
+
 var Cli struct {
 	Wallet struct {
-		Create walletCreateCmd `cmd:"" help:"Create new Ethereum wallets"`
+		Create   walletCreateCmd   `cmd:"" help:"Create new Ethereum wallets"`
+		Status   walletStatusCmd   `cmd:"" help:"Look up the balance and nonce of accounts over JSON-RPC"`
+		Fund     walletFundCmd     `cmd:"" help:"Send ether or ERC-20 tokens to accounts over JSON-RPC"`
+		Sweep    walletSweepCmd    `cmd:"" help:"Send the whole balance of accounts to an address over JSON-RPC"`
+		Discover walletDiscoverCmd `cmd:"" help:"Find the used accounts of a mnemonic or extended public key"`
 	} `cmd:"" help:"Manage Ethereum wallets"`
 
 	KeyStore struct {
-		Create keystoreCreateCmd `cmd:"" help:"Manage Ethereum keystores"`
-		List   keystoreListCmd   `cmd:"" help:"List all wallets from the keystore"`
+		Create    keystoreCreateCmd    `cmd:"" help:"Manage Ethereum keystores"`
+		List      keystoreListCmd      `cmd:"" help:"List all wallets from the keystore"`
+		Benchmark keystoreBenchmarkCmd `cmd:"" help:"Calibrate KDF parameters to a target unlock time on this machine"`
+		Export    keystoreExportCmd    `cmd:"" help:"Export the private keys of keystore accounts"`
+		Passwd    keystorePasswdCmd    `cmd:"" help:"Change the password of keystore accounts"`
+		Verify    keystoreVerifyCmd    `cmd:"" help:"Verify the integrity of every key file in the keystore"`
+		Watch     keystoreWatchCmd     `cmd:"" help:"Stream events as accounts are added to or removed from the keystore"`
+		Apply     keystoreApplyCmd     `cmd:"" help:"Reconcile the keystore with the accounts declared in a manifest"`
+		Label     keystoreLabelCmd     `cmd:"" help:"Set the alias, lifecycle state or environment of a keystore account"`
+		Tag       keystoreTagCmd       `cmd:"" help:"Add or remove tags of a keystore account"`
+		Merge     keystoreMergeCmd     `cmd:"" help:"Merge keystore directories into one, deduplicating accounts by address"`
+		Copy      keystoreCopyCmd      `cmd:"" help:"Copy accounts from a keystore directory into another"`
+		Diff      keystoreDiffCmd      `cmd:"" help:"Compare the accounts of two keystore directories"`
+		Restore   keystoreRestoreCmd   `cmd:"" help:"Restore the accounts derived from a mnemonic over an index range into a keystore"`
 	} `cmd:"" name:"keystore" help:"Manage Ethereum KeyStores"`
 
+	Validator struct {
+		Create             validatorCreateCmd             `cmd:"" help:"Derive validator keys from a mnemonic and write EIP-2335 keystores"`
+		Deposit            validatorDepositCmd            `cmd:"" help:"Generate launchpad compatible deposit data for validator keys"`
+		DepositVerify      validatorDepositVerifyCmd      `cmd:"" help:"Verify the roots and signatures of deposit data files"`
+		BLSChange          validatorBLSChangeCmd          `cmd:"" name:"bls-change" help:"Sign BLS to execution changes for validators with 0x00 withdrawal credentials"`
+		Exit               validatorExitCmd               `cmd:"" help:"Sign voluntary exits offline"`
+		Export             validatorExportCmd             `cmd:"" help:"Export validator keystores in the directory layout of a consensus client"`
+		SlashingProtection validatorSlashingProtectionCmd `cmd:"" name:"slashing-protection" help:"Generate, merge, validate and minify EIP-3076 slashing protection interchange files"`
+	} `cmd:"" help:"Manage Ethereum consensus layer validator keys"`
+
+	Signer struct {
+		Serve signerServeCmd `cmd:"" help:"Serve accounts over a Clef compatible JSON-RPC signer API"`
+	} `cmd:"" help:"Sign with ethw accounts from other tools"`
+
+	Tx struct {
+		Sign txSignCmd `cmd:"" help:"Sign a transaction offline with a mnemonic or keystore account"`
+	} `cmd:"" help:"Build and sign Ethereum transactions"`
+
 	Seed struct {
 		Create seedCreateCmd `cmd:"" help:"Create a new seed"`
 	} `cmd:"" help:"Manage cryptographic seeds for Ethereum wallets"`
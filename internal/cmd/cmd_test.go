@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/alecthomas/kong"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type CmdTestSuite struct {
+	suite.Suite
+}
+
+// parseServe parses the arguments of the signer serve command.
+func (suite *CmdTestSuite) parseServe(args ...string) *signerServeCmd {
+	var cli struct {
+		Serve signerServeCmd `cmd:""`
+	}
+	parser, err := kong.New(&cli, Vars)
+	if !assert.NoError(suite.T(), err) {
+		return nil
+	}
+	_, err = parser.Parse(append([]string{"serve", "--chain-id=1337"}, args...))
+	assert.NoError(suite.T(), err)
+	return &cli.Serve
+}
+
+func (suite *CmdTestSuite) TestServeOptions() {
+	// Without --ipc-path only the default HTTP endpoint is served
+	opts := suite.parseServe().serveOptions()
+	assert.Equal(suite.T(), "localhost:8550", opts.HTTPAddr)
+	assert.Equal(suite.T(), "", opts.IPCPath)
+
+	opts = suite.parseServe("--http-addr=").serveOptions()
+	assert.Equal(suite.T(), "", opts.HTTPAddr)
+	assert.Equal(suite.T(), "", opts.IPCPath)
+
+	opts = suite.parseServe("--ipc-path=signer.ipc").serveOptions()
+	assert.True(suite.T(), filepath.IsAbs(opts.IPCPath))
+	assert.Equal(suite.T(), "signer.ipc", filepath.Base(opts.IPCPath))
+}
+
+// Execute the test suite
+func TestCmdTestSuite(t *testing.T) {
+	suite.Run(t, new(CmdTestSuite))
+}
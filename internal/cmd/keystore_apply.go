@@ -0,0 +1,357 @@
+package cmd
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/aldoborrero/ethw/internal/keystore"
+	"github.com/aldoborrero/ethw/internal/password"
+	"github.com/aldoborrero/ethw/internal/utils/output"
+	"github.com/aldoborrero/ethw/internal/utils/pool"
+	"github.com/aldoborrero/ethw/internal/wallet"
+	"github.com/alecthomas/kong"
+	"github.com/charmbracelet/log"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/tyler-smith/go-bip39"
+)
+
+type keystoreApplyCmd struct {
+	File        string          `flag:"" short:"f" required:"" type:"existingfile" help:"Manifest declaring the accounts the keystore should hold"`
+	KeystoreDir string          `flag:"" optional:"" type:"path" help:"Directory of the keystore, overriding the keystore_dir of the manifest (./keystore by default)"`
+	DryRun      bool            `flag:"" optional:"" help:"Only show the plan, without changing the keystore"`
+	Verify      bool            `flag:"" optional:"" default:"true" negatable:"" help:"Decrypt every written key file again to check it round-trips"`
+	Jobs        int             `flag:"" optional:"" short:"j" default:"0" help:"Number of key files encrypted in parallel, 0 uses every CPU"`
+	MemoryLimit int             `flag:"" optional:"" default:"2048" help:"Memory, in MiB, the key derivations running in parallel may use, lowering --jobs for memory hungry scrypt parameters"`
+	KDF         kdfOptions      `embed:""`
+	Password    passwordOptions `embed:""`
+}
+
+func (cmd *keystoreApplyCmd) Run() error {
+	manifest, err := keystore.ReadManifest(kong.ExpandPath(cmd.File))
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	keystoreDir := cmd.KeystoreDir
+	if keystoreDir == "" {
+		keystoreDir = manifest.KeystoreDir
+	}
+	if keystoreDir == "" {
+		keystoreDir = "./keystore"
+	}
+
+	kdf, err := cmd.KDF.toKDF()
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	desired, keys, mnemonics, err := deriveManifestAccounts(manifest)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	ks := keystore.NewKeyStoreWithKDF(kong.ExpandPath(keystoreDir), kdf)
+	plan := ks.Plan(desired, manifest.RemoveExtra)
+
+	var writer output.KeystoreOutputWriter
+	switch Cli.OutputFormat {
+	case "json":
+		writer = output.KeystoreJSONOutputWriter{}
+	case "csv":
+		writer = output.KeystoreCSVOutputWriter{}
+	case "table":
+		writer = output.KeystoreTableOutputWriter{}
+	default:
+		writer = output.KeystoreTextOutputWriter{}
+	}
+
+	// The plan is always shown first, applying it only carries out what was shown
+	if err := writer.WriteApplyOutput(plan, cmd.DryRun); err != nil {
+		return fmt.Errorf("failed to generate output: %w", err)
+	}
+
+	conflicts := 0
+	for _, entry := range plan {
+		if entry.Action == keystore.ActionConflict {
+			conflicts++
+		}
+	}
+	if conflicts > 0 {
+		err := fmt.Errorf("the plan has %d conflicting account(s), nothing was applied", conflicts)
+		log.Error(err.Error())
+		return err
+	}
+	if cmd.DryRun {
+		return nil
+	}
+
+	// Metadata is checked before changing anything, but only saved once the key files are committed
+	metadata, err := ks.Metadata()
+	if err == nil {
+		err = updateManifestMetadata(metadata, manifest, plan)
+	}
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	if keystore.PlanChanges(plan) == 0 {
+		log.Infof("Keystore %s is up to date", ks.Dir())
+	} else if err := cmd.apply(ks, manifest, plan, keys, mnemonics); err != nil {
+		err = fmt.Errorf("%w, the keystore was left untouched", err)
+		log.Error(err.Error())
+		return err
+	}
+
+	if err := metadata.Save(); err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// updateManifestMetadata records the alias, tags, environment and derivation source the manifest declares for its
+// accounts, and drops the metadata of removed accounts.
+func updateManifestMetadata(metadata *keystore.Metadata, manifest *keystore.Manifest, plan []keystore.PlanEntry) error {
+	// Removed accounts go first, so their aliases can be taken over
+	for _, entry := range plan {
+		if entry.Action == keystore.ActionRemoveExtra {
+			metadata.Delete(entry.Address)
+		}
+	}
+
+	for _, entry := range plan {
+		if entry.Index < 0 {
+			continue
+		}
+		account := manifest.Accounts[entry.Index]
+		err := metadata.Update(entry.Address, func(meta *keystore.AccountMetadata) {
+			if account.Alias != "" {
+				meta.Alias = account.Alias
+			}
+			meta.AddTags(account.Tags...)
+			if account.Environment != "" {
+				meta.Environment = account.Environment
+			}
+			if entry.Action != keystore.ActionKeep || meta.CreatedBy == "" {
+				meta.CreatedBy = createdBy()
+			}
+			meta.Source = &keystore.DerivationSource{Type: keystore.SourceMnemonic, Mnemonic: account.Mnemonic, Path: entry.DerivationPath}
+		})
+		if err != nil {
+			return fmt.Errorf("account %d: %w", entry.Index+1, err)
+		}
+	}
+
+	return nil
+}
+
+// apply resolves the passwords of the accounts to write, in manifest order as they may be prompted for, then stages
+// their key files and the removals in a transaction which is only committed if every step succeeded.
+func (cmd *keystoreApplyCmd) apply(ks *keystore.KeystoreWrapper, manifest *keystore.Manifest, plan []keystore.PlanEntry, keys []*ecdsa.PrivateKey, mnemonics []string) error {
+	var writes []int
+	passwords := make(map[int]string)
+	var fallback password.Source
+	for i, entry := range plan {
+		if entry.Action != keystore.ActionCreate && entry.Action != keystore.ActionReplace {
+			continue
+		}
+
+		source, err := manifestPasswordSource(manifest.Accounts[entry.Index].Password)
+		if err != nil {
+			return fmt.Errorf("account %d: %w", entry.Index+1, err)
+		}
+		// A source of the manifest belongs to its account alone, so it's read as a one password source
+		index := 0
+		if source == nil {
+			if fallback == nil {
+				if fallback, err = resolvePasswordSource(cmd.Password, nil, false, true); err != nil {
+					return fmt.Errorf("account %d has no password source in the manifest: %w", entry.Index+1, err)
+				}
+			}
+			source, index = fallback, entry.Index
+		}
+
+		if passwords[i], err = passwordFor(source, index, entry.Address.Hex()); err != nil {
+			return fmt.Errorf("failed to get password for account %d: %w", entry.Index+1, err)
+		}
+		writes = append(writes, i)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	tx, err := ks.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil {
+			log.Error(err.Error())
+		}
+	}()
+
+	var (
+		mu       sync.Mutex
+		failures []string
+	)
+	fail := func(err error) {
+		log.Error(err.Error())
+		mu.Lock()
+		failures = append(failures, err.Error())
+		mu.Unlock()
+	}
+
+	pool.Run(len(writes), kdfWorkers(cmd.Jobs, cmd.MemoryLimit, ks.KDF()), func(i int) {
+		entry := plan[writes[i]]
+		if ctx.Err() != nil {
+			return
+		}
+
+		log.Infof("Writing key file of account %d with address %s (%s)", entry.Index+1, entry.Address.Hex(), entry.Action)
+		keyJSON, err := keystore.EncryptKey(keys[entry.Index], passwords[writes[i]], ks.KDF())
+		if err != nil {
+			fail(fmt.Errorf("failed to encrypt private key of account %d: %w", entry.Index+1, err))
+			return
+		}
+		var alias string
+		if manifest.NameByAlias {
+			alias = entry.Alias
+		}
+		_, staged, err := tx.WriteKey(entry.Address, alias, keyJSON, entry.Action == keystore.ActionReplace)
+		if err != nil {
+			fail(fmt.Errorf("failed to stage key file of account %d: %w", entry.Index+1, err))
+			return
+		}
+
+		if cmd.Verify {
+			verification := keystore.VerifyKeyFile(staged, keystore.VerifyOptions{
+				Password:       passwords[writes[i]],
+				HasPassword:    true,
+				Mnemonic:       mnemonics[entry.Index],
+				DerivationPath: entry.DerivationPath,
+			})
+			if !verification.OK() {
+				fail(fmt.Errorf("key file of account %d failed verification: %s", entry.Index+1, strings.Join(verification.Failures(), "; ")))
+			}
+		}
+	})
+
+	if ctx.Err() != nil {
+		return errors.New("interrupted")
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("failed to write %d of %d key files", len(failures), len(writes))
+	}
+
+	for _, entry := range plan {
+		if entry.Action != keystore.ActionRemoveExtra {
+			continue
+		}
+		account, err := ks.Find(entry.Address)
+		if err != nil {
+			return err
+		}
+		log.Infof("Removing key file %s of extra account %s", account.URL.Path, entry.Address.Hex())
+		if err := tx.Remove(account); err != nil {
+			return err
+		}
+	}
+
+	log.Infof("Committing %d change(s) into %s", keystore.PlanChanges(plan), ks.Dir())
+	return tx.Commit()
+}
+
+// deriveManifestAccounts derives the accounts declared in the manifest, reading every referenced mnemonic once, and
+// returns them with their keys and the mnemonics they're derived from.
+func deriveManifestAccounts(manifest *keystore.Manifest) ([]keystore.DesiredAccount, []*ecdsa.PrivateKey, []string, error) {
+	mnemonics := make(map[string]string)
+	desired := make([]keystore.DesiredAccount, len(manifest.Accounts))
+	keys := make([]*ecdsa.PrivateKey, len(manifest.Accounts))
+	accountMnemonics := make([]string, len(manifest.Accounts))
+
+	for i, account := range manifest.Accounts {
+		mnemonic, ok := mnemonics[account.Mnemonic]
+		if !ok {
+			var err error
+			if mnemonic, err = readManifestMnemonic(manifest.Mnemonics[account.Mnemonic]); err != nil {
+				return nil, nil, nil, fmt.Errorf("mnemonic %q: %w", account.Mnemonic, err)
+			}
+			mnemonics[account.Mnemonic] = mnemonic
+		}
+		accountMnemonics[i] = mnemonic
+
+		path := account.Path
+		if account.Index != nil {
+			scheme := account.Scheme
+			if scheme == "" {
+				scheme = "bip44"
+			}
+			var err error
+			if path, err = wallet.DerivationPath(scheme, *account.Index); err != nil {
+				return nil, nil, nil, fmt.Errorf("account %d: %w", i+1, err)
+			}
+		}
+
+		walletInstance, err := wallet.NewWallet(mnemonic, account.Alias, path)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to derive account %d: %w", i+1, err)
+		}
+		if keys[i], err = crypto.HexToECDSA(walletInstance.PrivateKey); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to decode private key of account %d: %w", i+1, err)
+		}
+		desired[i] = keystore.DesiredAccount{
+			Alias:          account.Alias,
+			Address:        common.HexToAddress(walletInstance.Address),
+			DerivationPath: walletInstance.DerivationPath,
+			OnConflict:     account.OnConflict,
+		}
+	}
+
+	return desired, keys, accountMnemonics, nil
+}
+
+// readManifestMnemonic reads a mnemonic from the file or environment variable the manifest points to.
+func readManifestMnemonic(source keystore.ManifestMnemonic) (string, error) {
+	if source.File != "" {
+		return readMnemonicFile(source.File)
+	}
+
+	value, ok := os.LookupEnv(source.Env)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", source.Env)
+	}
+	mnemonic := strings.Join(strings.Fields(value), " ")
+	if !bip39.IsMnemonicValid(mnemonic) {
+		return "", errInvalidSeedMnemonic
+	}
+	return mnemonic, nil
+}
+
+// manifestPasswordSource maps the password source of a manifest account, nil when it has none.
+func manifestPasswordSource(p keystore.ManifestPassword) (password.Source, error) {
+	switch {
+	case p.File != "":
+		return password.NewFile(p.File)
+	case p.Env != "":
+		return password.Env{Name: p.Env}, nil
+	case p.Command != "":
+		return password.Command{Command: p.Command}, nil
+	case p.Keyring != "":
+		return password.Keyring{Service: p.Keyring}, nil
+	default:
+		return nil, nil
+	}
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aldoborrero/ethw/internal/keystore"
+	"github.com/aldoborrero/ethw/internal/utils/output"
+	"github.com/charmbracelet/log"
+)
+
+type keystoreBenchmarkCmd struct {
+	KDF    string        `name:"kdf" enum:"scrypt,pbkdf2" default:"scrypt" help:"Key derivation function to calibrate"`
+	Target time.Duration `default:"1s" help:"Target time to unlock a single key file on this machine"`
+}
+
+func (cmd *keystoreBenchmarkCmd) Run() error {
+	log.Infof("Calibrating %s parameters for a target unlock time of %s", cmd.KDF, cmd.Target)
+
+	results, recommended, err := keystore.Calibrate(cmd.KDF, cmd.Target)
+	if err != nil {
+		log.Error("Failed to calibrate KDF parameters: ", err)
+		return err
+	}
+
+	var writer output.KeystoreOutputWriter
+	switch Cli.OutputFormat {
+	case "json":
+		writer = output.KeystoreJSONOutputWriter{}
+	case "csv":
+		writer = output.KeystoreCSVOutputWriter{}
+	case "table":
+		writer = output.KeystoreTableOutputWriter{}
+	default:
+		writer = output.KeystoreTextOutputWriter{}
+	}
+
+	if err := writer.WriteBenchmarkOutput(results, recommended); err != nil {
+		return fmt.Errorf("failed to generate output: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"github.com/aldoborrero/ethw/internal/keystore"
+	"github.com/alecthomas/kong"
+	"github.com/charmbracelet/log"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type keystoreCopyCmd struct {
+	Source      string   `arg:"" type:"existingdir" help:"Keystore directory the accounts are copied from"`
+	Destination string   `arg:"" type:"path" help:"Keystore directory the accounts are copied into, created if needed"`
+	Address     []string `flag:"" required:"" help:"Address, or alias in the source keystore, of an account to copy, can be repeated"`
+	OnConflict  string   `flag:"" optional:"" enum:"fail,skip,replace" default:"fail" help:"What to do with key files conflicting with the destination: fail, skip (keep the destination) or replace it"`
+	DryRun      bool     `flag:"" optional:"" help:"Only show what would be copied, without writing anything"`
+}
+
+func (cmd *keystoreCopyCmd) Run() error {
+	source := kong.ExpandPath(cmd.Source)
+	metadata, err := keystore.ReadMetadata(source)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	addresses := make([]common.Address, len(cmd.Address))
+	for i, ref := range cmd.Address {
+		if addresses[i], err = metadata.Resolve(ref); err != nil {
+			log.Error(err.Error())
+			return err
+		}
+	}
+
+	return mergeKeystores([]string{source}, cmd.Destination, addresses, cmd.OnConflict, cmd.DryRun)
+}
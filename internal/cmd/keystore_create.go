@@ -1,42 +1,107 @@
 package cmd
 
 import (
+	"context"
+	"crypto/ecdsa"
 	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"regexp"
+	"runtime"
 	"strings"
+	"syscall"
 
 	"github.com/aldoborrero/ethw/internal/keystore"
+	"github.com/aldoborrero/ethw/internal/password"
 	"github.com/aldoborrero/ethw/internal/utils/output"
+	"github.com/aldoborrero/ethw/internal/utils/pool"
 	"github.com/aldoborrero/ethw/internal/wallet"
 	"github.com/alecthomas/kong"
 	"github.com/charmbracelet/log"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/tyler-smith/go-bip39"
 )
 
 type keystoreCreateCmd struct {
-	Wallets     []WalletData `arg:"" type:"custom" help:"List of 'seed' and 'password' to generate wallets"`
-	Overwrite   bool         `flag:"" optional:"" help:"Overwrite wallet creation if exists one in the keystore"`
-	KeystoreDir string       `flag:"" optional:"" type:"path" default:"./keystore" help:"Directory to save the keystore file"`
+	Wallets              []WalletData    `arg:"" type:"custom" help:"List of 'seed' and, optionally, 'password', 'path' and 'alias' to generate wallets"`
+	KeystoreDir          string          `flag:"" optional:"" type:"path" default:"./keystore" help:"Directory to save the keystore file"`
+	UnsafeInlinePassword bool            `flag:"" optional:"" help:"Allow passwords inside wallet specs (they leak into shell history and ps)"`
+	GeneratePasswords    bool            `flag:"" optional:"" help:"Generate a strong random password for every account (requires --password-output)"`
+	GeneratedLength      int             `flag:"" optional:"" name:"generated-password-length" default:"${generated_password_length}" help:"Length of generated passwords"`
+	PasswordOutput       string          `flag:"" optional:"" type:"path" help:"Write a geth-compatible password file (one line per created account, in --unlock order)"`
+	UnlockOutput         string          `flag:"" optional:"" type:"path" help:"Write the address list of geth's --unlock flag for the created accounts to this file"`
+	IfExists             string          `flag:"" optional:"" enum:"fail,skip,replace" default:"fail" help:"What to do with wallets already present in the keystore: fail, skip or replace them"`
+	Verify               bool            `flag:"" optional:"" default:"true" negatable:"" help:"Decrypt every written key file again to check it round-trips"`
+	Jobs                 int             `flag:"" optional:"" short:"j" default:"0" help:"Number of wallets derived and encrypted in parallel, 0 uses every CPU"`
+	MemoryLimit          int             `flag:"" optional:"" default:"2048" help:"Memory, in MiB, the key derivations running in parallel may use, lowering --jobs for memory hungry scrypt parameters"`
+	Tag                  []string        `flag:"" optional:"" help:"Tag recorded in the keystore metadata of every created account, can be repeated"`
+	Environment          string          `flag:"" optional:"" help:"Environment recorded in the keystore metadata of every created account, e.g. devnet"`
+	NameByAlias          bool            `flag:"" optional:"" help:"Prefix the key file names of accounts with an alias with <alias>--"`
+	KDF                  kdfOptions      `embed:""`
+	Password             passwordOptions `embed:""`
 }
 
 func (cmd *keystoreCreateCmd) Run() error {
 	absKeystoreDir := kong.ExpandPath(cmd.KeystoreDir)
 
-	if cmd.Overwrite {
-		if err := os.RemoveAll(absKeystoreDir); err != nil {
-			log.Error("Failed to remove keystore directory: ", err)
-			return err
-		}
+	kdf, err := cmd.KDF.toKDF()
+	if err != nil {
+		log.Error(err.Error())
+		return err
 	}
 
-	ks := keystore.NewKeyStore(absKeystoreDir)
-
+	inline := make(password.Static, len(cmd.Wallets))
 	for i, walletData := range cmd.Wallets {
-		if err := cmd.createWallet(walletData, i, ks); err != nil {
-			log.Error(err.Error())
-			return err
+		inline[i] = walletData.Password
+	}
+	passwords, err := cmd.passwordSource(inline)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	workers := kdfWorkers(cmd.Jobs, cmd.MemoryLimit, kdf)
+	log.Infof("Encrypting key files with %s on %d workers", kdf, workers)
+	ks := keystore.NewKeyStoreWithKDF(absKeystoreDir, kdf)
+	metadata, err := ks.Metadata()
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	// Nothing is written unless every wallet can be created, a failed run leaves the keystore untouched
+	plan := cmd.planWallets(ks, metadata, passwords, workers)
+	createErr := plan.err()
+	if createErr == nil {
+		createErr = cmd.writeWallets(ks, plan, workers)
+	}
+	if createErr != nil {
+		plan.rollBack()
+	}
+
+	// Metadata is only recorded once the key files are committed
+	var metadataErr error
+	if createErr == nil {
+		metadataErr = metadata.Save()
+	}
+
+	unlock := keystore.GethUnlock{}
+	for _, result := range plan.results {
+		if result.Written() {
+			unlock.Addresses = append(unlock.Addresses, result.Address)
+		}
+	}
+	if createErr == nil && cmd.PasswordOutput != "" && len(unlock.Addresses) > 0 {
+		unlock.PasswordFile = kong.ExpandPath(cmd.PasswordOutput)
+	}
+	var unlockErr error
+	if createErr == nil && cmd.UnlockOutput != "" && len(unlock.Addresses) > 0 {
+		unlockFile := kong.ExpandPath(cmd.UnlockOutput)
+		log.Infof("Writing unlock list %s", unlockFile)
+		if err := os.WriteFile(unlockFile, []byte(unlock.UnlockList()+"\n"), 0o644); err != nil {
+			unlockErr = fmt.Errorf("failed to write unlock list: %w", err)
 		}
 	}
 
@@ -52,33 +117,315 @@ func (cmd *keystoreCreateCmd) Run() error {
 		writer = output.KeystoreTextOutputWriter{}
 	}
 
-	if err := writer.WriteCreateOutput(*ks); err != nil {
+	if err := writer.WriteCreateOutput(plan.results, unlock); err != nil {
 		return fmt.Errorf("failed to generate output: %w", err)
 	}
 
+	if createErr != nil {
+		return fmt.Errorf("%w, the keystore was left untouched", createErr)
+	}
+	if metadataErr != nil {
+		log.Error(metadataErr.Error())
+		return fmt.Errorf("key files were created, but %w", metadataErr)
+	}
+	if unlockErr != nil {
+		log.Error(unlockErr.Error())
+		return fmt.Errorf("key files were created, but %w", unlockErr)
+	}
+
+	return nil
+}
+
+// passwordSource returns where the passwords of the new accounts come from, either generated or read from the
+// configured sources.
+func (cmd *keystoreCreateCmd) passwordSource(inline password.Static) (password.Source, error) {
+	if !cmd.GeneratePasswords {
+		return resolvePasswordSource(cmd.Password, inline, cmd.UnsafeInlinePassword, true)
+	}
+
+	if cmd.PasswordOutput == "" {
+		return nil, errors.New("--generate-passwords requires --password-output, generated passwords would be lost otherwise")
+	}
+	if source, err := cmd.Password.source(true); err != nil || source != nil {
+		return nil, errors.New("--generate-passwords can't be combined with other password sources")
+	}
+	for _, p := range inline {
+		if p != "" {
+			return nil, errors.New("--generate-passwords can't be combined with inline passwords")
+		}
+	}
+
+	return password.Generator{Length: cmd.GeneratedLength}, nil
+}
+
+// createPlan holds the wallets of a keystore create run, derived and checked against the keystore. Results without
+// a status yet are pending: their key file still has to be written.
+type createPlan struct {
+	results   []keystore.CreateResult
+	keys      []*ecdsa.PrivateKey
+	passwords []string
+	replace   []bool
+	pending   []int
+	// duplicates maps wallets to the earlier wallet with the same address, whose key file they share.
+	duplicates map[int]int
+}
+
+// fail records the failure of a wallet.
+func (p *createPlan) fail(index int, err error) {
+	log.Error(err.Error())
+	p.results[index].Status = keystore.StatusFailed
+	p.results[index].Err = err
+}
+
+// err returns an error if any wallet failed.
+func (p *createPlan) err() error {
+	failed := 0
+	for _, result := range p.results {
+		if result.Status == keystore.StatusFailed {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to create %d of %d wallets", failed, len(p.results))
+	}
 	return nil
 }
 
-func (cmd *keystoreCreateCmd) createWallet(walletData WalletData, index int, ks *keystore.KeystoreWrapper) error {
-	mnemonic := walletData.Mnemonic
-	password := walletData.Password
+// rollBack marks every wallet whose key file was, or would have been, written as rolled back.
+func (p *createPlan) rollBack() {
+	for _, index := range p.pending {
+		if p.results[index].Status != keystore.StatusFailed {
+			p.results[index].Status = keystore.StatusRolledBack
+			p.results[index].KeyFile = ""
+		}
+	}
+	for index := range p.duplicates {
+		p.results[index].Status = keystore.StatusRolledBack
+	}
+}
+
+// planWallets derives the wallets on a pool of workers, then applies the --if-exists policy and resolves passwords
+// in argument order, as they may be prompted for. The metadata of the wallets to write is recorded in metadata.
+func (cmd *keystoreCreateCmd) planWallets(ks *keystore.KeystoreWrapper, metadata *keystore.Metadata, passwords password.Source, workers int) *createPlan {
+	plan := &createPlan{
+		results:    make([]keystore.CreateResult, len(cmd.Wallets)),
+		keys:       make([]*ecdsa.PrivateKey, len(cmd.Wallets)),
+		passwords:  make([]string, len(cmd.Wallets)),
+		replace:    make([]bool, len(cmd.Wallets)),
+		duplicates: make(map[int]int),
+	}
+
+	pool.Run(len(cmd.Wallets), workers, func(index int) {
+		walletData := cmd.Wallets[index]
+		plan.results[index] = keystore.CreateResult{Index: index, DerivationPath: walletData.DerivationPath, Metadata: keystore.AccountMetadata{Alias: walletData.Alias}}
+
+		walletInstance, err := wallet.NewWallet(walletData.Mnemonic, "", walletData.DerivationPath)
+		if err != nil {
+			plan.fail(index, fmt.Errorf("failed to generate wallet %d from seed: %w", index+1, err))
+			return
+		}
+		plan.results[index].Address = common.HexToAddress(walletInstance.Address)
+		plan.results[index].DerivationPath = walletInstance.DerivationPath
+
+		if plan.keys[index], err = crypto.HexToECDSA(walletInstance.PrivateKey); err != nil {
+			plan.fail(index, fmt.Errorf("failed to decode private key of wallet %d: %w", index+1, err))
+		}
+	})
+
+	firsts := make(map[common.Address]int)
+	for index := range plan.results {
+		result := &plan.results[index]
+		if result.Status == keystore.StatusFailed {
+			continue
+		}
+
+		// The same account given twice is only written once
+		if first, ok := firsts[result.Address]; ok {
+			if cmd.IfExists == "skip" {
+				plan.duplicates[index] = first
+			} else {
+				plan.fail(index, fmt.Errorf("wallet %d has the same address %s as wallet %d", index+1, result.Address.Hex(), first+1))
+			}
+			continue
+		}
+		firsts[result.Address] = index
+
+		if ks.HasAddress(result.Address) {
+			switch cmd.IfExists {
+			case "skip":
+				log.Infof("Skipping wallet %d with existing address %s", index+1, result.Address.Hex())
+				account, err := ks.Find(result.Address)
+				if err != nil {
+					plan.fail(index, err)
+					continue
+				}
+				result.Status = keystore.StatusSkippedExisting
+				result.KeyFile = account.URL.Path
+				result.Metadata = metadata.Get(result.Address)
+				continue
+			case "fail":
+				plan.fail(index, fmt.Errorf("failed to create wallet %d with address %s: %w", index+1, result.Address.Hex(), keystore.ErrAccountExists))
+				continue
+			}
+			plan.replace[index] = true
+		}
+
+		err := metadata.Update(result.Address, func(meta *keystore.AccountMetadata) {
+			if result.Metadata.Alias != "" {
+				meta.Alias = result.Metadata.Alias
+			}
+			meta.AddTags(cmd.Tag...)
+			if cmd.Environment != "" {
+				meta.Environment = cmd.Environment
+			}
+			meta.CreatedBy = createdBy()
+			meta.Source = &keystore.DerivationSource{Type: keystore.SourceMnemonic, Path: result.DerivationPath}
+		})
+		if err != nil {
+			plan.fail(index, fmt.Errorf("failed to record metadata of wallet %d: %w", index+1, err))
+			continue
+		}
+		result.Metadata = metadata.Get(result.Address)
+
+		walletPassword, err := passwordFor(passwords, index, result.Address.Hex())
+		if err != nil {
+			plan.fail(index, fmt.Errorf("failed to get password for wallet %d: %w", index+1, err))
+			continue
+		}
+		plan.passwords[index] = walletPassword
+		plan.pending = append(plan.pending, index)
+	}
+
+	return plan
+}
+
+// writeWallets encrypts the pending wallets on a pool of workers and stages their key files, then writes the
+// password file and commits the key files into the keystore. On any failure, or on SIGINT/SIGTERM, the staged key
+// files are discarded and the keystore is left as it was.
+func (cmd *keystoreCreateCmd) writeWallets(ks *keystore.KeystoreWrapper, plan *createPlan, workers int) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	walletInstance, err := wallet.NewWallet(mnemonic, "")
+	tx, err := ks.Begin()
 	if err != nil {
-		log.Errorf("Failed to generate wallet %d from seed: %v", index+1, err)
 		return err
 	}
+	defer func() {
+		if err := tx.Rollback(); err != nil {
+			log.Error(err.Error())
+		}
+	}()
+
+	pool.Run(len(plan.pending), workers, func(i int) {
+		index := plan.pending[i]
+		result := &plan.results[index]
+		if ctx.Err() != nil {
+			return
+		}
+
+		log.Infof("Creating wallet %d with address %s", index+1, result.Address.Hex())
+		keyJSON, err := keystore.EncryptKey(plan.keys[index], plan.passwords[index], ks.KDF())
+		if err != nil {
+			plan.fail(index, fmt.Errorf("failed to encrypt private key of wallet %d: %w", index+1, err))
+			return
+		}
+		var alias string
+		if cmd.NameByAlias {
+			alias = result.Metadata.Alias
+		}
+		account, staged, err := tx.WriteKey(result.Address, alias, keyJSON, plan.replace[index])
+		if err != nil {
+			plan.fail(index, fmt.Errorf("failed to import private key into keystore for wallet %d: %w", index+1, err))
+			return
+		}
 
-	log.Infof("Creating wallet %d with address %s", index+1, walletInstance.Address)
-	if err := ks.ImportPrivateKey(walletInstance.PrivateKey, password, false); err != nil {
-		log.Errorf("Failed to import private key into keystore for wallet %d: %v", index+1, err)
+		if cmd.Verify {
+			verification := keystore.VerifyKeyFile(staged, keystore.VerifyOptions{
+				Password:       plan.passwords[index],
+				HasPassword:    true,
+				Mnemonic:       cmd.Wallets[index].Mnemonic,
+				DerivationPath: cmd.Wallets[index].DerivationPath,
+			})
+			// Staged files already have their final name, so every check applies
+			if !verification.OK() {
+				plan.fail(index, fmt.Errorf("key file of wallet %d failed verification: %s", index+1, strings.Join(verification.Failures(), "; ")))
+				return
+			}
+		}
+
+		result.KeyFile = account.URL.Path
+		result.Status = keystore.StatusCreated
+		if plan.replace[index] {
+			result.Status = keystore.StatusReplaced
+		}
+	})
+
+	if ctx.Err() != nil {
+		return errors.New("interrupted")
+	}
+	if err := plan.err(); err != nil {
+		return err
+	}
+
+	for index := range plan.results {
+		first, ok := plan.duplicates[index]
+		if !ok {
+			continue
+		}
+		log.Infof("Skipping wallet %d with the same address as wallet %d", index+1, first+1)
+		plan.results[index].Status = keystore.StatusSkippedExisting
+		plan.results[index].KeyFile = plan.results[first].KeyFile
+	}
+
+	// The password file is written first, passwords of committed key files must never be lost
+	var passwordFile string
+	if cmd.PasswordOutput != "" {
+		var usedPasswords []string
+		for index, result := range plan.results {
+			if result.Written() {
+				usedPasswords = append(usedPasswords, plan.passwords[index])
+			}
+		}
+		if len(usedPasswords) > 0 {
+			passwordFile = kong.ExpandPath(cmd.PasswordOutput)
+			log.Infof("Writing password file %s", passwordFile)
+			if err := password.WriteFile(passwordFile, usedPasswords); err != nil {
+				return err
+			}
+		}
+	}
+
+	log.Infof("Committing %d key files into %s", len(plan.pending), ks.Dir())
+	if err := tx.Commit(); err != nil {
+		if passwordFile != "" {
+			os.Remove(passwordFile)
+		}
 		return err
 	}
+
 	return nil
 }
 
+// kdfWorkers returns the number of wallets processed in parallel: jobs (every CPU when 0), lowered so that the key
+// derivations running at the same time fit in memoryLimit MiB.
+func kdfWorkers(jobs, memoryLimit int, kdf keystore.KDF) int {
+	workers := jobs
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if memory := kdf.Memory(); memory > 0 {
+		if limit := int(uint64(memoryLimit) << 20 / memory); limit < workers {
+			workers = limit
+		}
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	return workers
+}
+
 var (
-	reWalletData                     = regexp.MustCompile(`(?:seed=([^;]*))(?:;password=([^;]*))?(?:;path=([^;]*))?`)
+	reWalletData                     = regexp.MustCompile(`(?:seed=([^;]*))(?:;password=([^;]*))?(?:;path=([^;]*))?(?:;alias=([^;]*))?`)
 	errInvalidWalletDataFormat       = errors.New("invalid wallet format")
 	errInvalidWalletDataMnemonicSeed = errors.New("invalid mnemonic format")
 )
@@ -87,6 +434,7 @@ type WalletData struct {
 	Mnemonic       string
 	Password       string
 	DerivationPath string
+	Alias          string
 }
 
 func (wd *WalletData) UnmarshalText(raw []byte) error {
@@ -95,16 +443,22 @@ func (wd *WalletData) UnmarshalText(raw []byte) error {
 		return errInvalidWalletDataFormat
 	}
 
-	mnemonic, password, path := strings.TrimSpace(matches[1]), strings.TrimSpace(matches[2]), strings.TrimSpace(matches[3])
+	mnemonic, password, path, alias := strings.TrimSpace(matches[1]), strings.TrimSpace(matches[2]), strings.TrimSpace(matches[3]), strings.TrimSpace(matches[4])
 
 	if !bip39.IsMnemonicValid(mnemonic) {
 		return errInvalidWalletDataMnemonicSeed
 	}
+	if alias != "" {
+		if err := keystore.ValidateAlias(alias); err != nil {
+			return err
+		}
+	}
 
 	*wd = WalletData{
 		Mnemonic:       mnemonic,
 		Password:       password,
 		DerivationPath: path,
+		Alias:          alias,
 	}
 
 	return nil
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/aldoborrero/ethw/internal/keystore"
+	"github.com/aldoborrero/ethw/internal/utils/output"
+	"github.com/alecthomas/kong"
+	"github.com/charmbracelet/log"
+)
+
+type keystoreDiffCmd struct {
+	A        string `arg:"" type:"existingdir" help:"First keystore directory"`
+	B        string `arg:"" type:"existingdir" help:"Second keystore directory"`
+	ExitCode bool   `flag:"" optional:"" help:"Fail when the directories differ, like diff(1)"`
+}
+
+func (cmd *keystoreDiffCmd) Run() error {
+	entries, err := keystore.Diff(kong.ExpandPath(cmd.A), kong.ExpandPath(cmd.B))
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	var writer output.KeystoreOutputWriter
+	switch Cli.OutputFormat {
+	case "json":
+		writer = output.KeystoreJSONOutputWriter{}
+	case "csv":
+		writer = output.KeystoreCSVOutputWriter{}
+	case "table":
+		writer = output.KeystoreTableOutputWriter{}
+	default:
+		writer = output.KeystoreTextOutputWriter{}
+	}
+
+	if err := writer.WriteDiffOutput(entries); err != nil {
+		return fmt.Errorf("failed to generate output: %w", err)
+	}
+
+	if cmd.ExitCode {
+		differences := 0
+		for _, entry := range entries {
+			if entry.Status != keystore.DiffIdentical {
+				differences++
+			}
+		}
+		if differences > 0 {
+			return fmt.Errorf("%d of %d accounts differ", differences, len(entries))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/aldoborrero/ethw/internal/keystore"
+	"github.com/aldoborrero/ethw/internal/utils/output"
+	"github.com/aldoborrero/ethw/internal/wallet"
+	"github.com/alecthomas/kong"
+	"github.com/charmbracelet/log"
+)
+
+type keystoreExportCmd struct {
+	Addresses   []string        `arg:"" help:"Addresses of the accounts to export"`
+	KeystoreDir string          `flag:"" optional:"" type:"path" default:"./keystore" help:"Directory where the keystore is located"`
+	Password    passwordOptions `embed:""`
+}
+
+func (cmd *keystoreExportCmd) Run() error {
+	addresses, err := parseAddresses(cmd.Addresses)
+	if err != nil {
+		return err
+	}
+
+	passwords, err := resolvePasswordSource(cmd.Password, nil, false, false)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	ks := keystore.NewKeyStore(kong.ExpandPath(cmd.KeystoreDir))
+
+	wallets := make([]*wallet.Wallet, 0, len(addresses))
+	for i, address := range addresses {
+		walletPassword, err := passwordFor(passwords, i, address.Hex())
+		if err != nil {
+			return err
+		}
+
+		key, err := ks.DecryptKey(address, walletPassword)
+		if err != nil {
+			log.Errorf("Failed to unlock account %s: %v", address.Hex(), err)
+			return err
+		}
+		wallets = append(wallets, wallet.NewWalletFromPrivateKey(key.PrivateKey, ""))
+	}
+
+	var writer output.WalletOutputWriter
+	switch Cli.OutputFormat {
+	case "json":
+		writer = output.WalletJSONOutputWriter{}
+	case "csv":
+		writer = output.WalletCSVOutputWriter{}
+	case "table":
+		writer = output.WalletTableOutputWriter{}
+	default:
+		writer = output.WalletTextOuputWriter{}
+	}
+
+	if err := writer.WriteCreateOutput(wallets); err != nil {
+		return fmt.Errorf("failed to generate output: %w", err)
+	}
+
+	return nil
+}
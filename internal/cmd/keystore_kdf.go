@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/aldoborrero/ethw/internal/keystore"
+	"github.com/alecthomas/kong"
+)
+
+// kdfOptions groups the flags that select how new key files are encrypted.
+type kdfOptions struct {
+	KDF              string `name:"kdf" enum:"standard,light,scrypt,pbkdf2" default:"standard" help:"Key derivation function used to encrypt key files: geth's standard or light scrypt, custom scrypt or pbkdf2"`
+	ScryptN          int    `name:"scrypt-n" default:"262144" help:"Custom scrypt CPU/memory cost (N), must be a power of two (only with --kdf=scrypt)"`
+	ScryptR          int    `name:"scrypt-r" default:"8" help:"Custom scrypt block size (r) (only with --kdf=scrypt)"`
+	ScryptP          int    `name:"scrypt-p" default:"1" help:"Custom scrypt parallelization (p) (only with --kdf=scrypt)"`
+	PBKDF2Iterations int    `name:"pbkdf2-iterations" default:"262144" help:"Number of PBKDF2 iterations (only with --kdf=pbkdf2)"`
+}
+
+// toKDF maps the flags into a validated keystore KDF.
+func (o kdfOptions) toKDF() (keystore.KDF, error) {
+	var kdf keystore.KDF
+	switch o.KDF {
+	case "light":
+		kdf = keystore.LightScrypt
+	case "scrypt":
+		kdf = keystore.NewScryptKDF(o.ScryptN, o.ScryptR, o.ScryptP)
+	case "pbkdf2":
+		kdf = keystore.NewPBKDF2KDF(o.PBKDF2Iterations)
+	default:
+		kdf = keystore.StandardScrypt
+	}
+
+	if err := kdf.Validate(); err != nil {
+		return keystore.KDF{}, fmt.Errorf("invalid KDF parameters: %w", err)
+	}
+	return kdf, nil
+}
+
+// given reports whether any of the KDF flags was given on the command line, rather than left to its default.
+func (o kdfOptions) given(ctx *kong.Context) bool {
+	for _, path := range ctx.Path {
+		if path.Flag == nil {
+			continue
+		}
+		switch path.Flag.Name {
+		case "kdf", "scrypt-n", "scrypt-r", "scrypt-p", "pbkdf2-iterations":
+			return true
+		}
+	}
+	return false
+}
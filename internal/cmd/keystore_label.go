@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/aldoborrero/ethw/internal/keystore"
+	"github.com/charmbracelet/log"
+)
+
+type keystoreLabelCmd struct {
+	Account     string `arg:"" help:"Address or alias of the account"`
+	Alias       string `arg:"" optional:"" help:"New alias of the account"`
+	ClearAlias  bool   `flag:"" optional:"" help:"Remove the alias of the account"`
+	State       string `flag:"" optional:"" enum:",active,retired,compromised" default:"" help:"Lifecycle state of the account: active, retired or compromised"`
+	Environment string `flag:"" optional:"" help:"Environment the account belongs to, e.g. devnet"`
+	KeystoreDir string `flag:"" optional:"" type:"path" default:"./keystore" help:"Directory where the keystore is located"`
+}
+
+func (cmd *keystoreLabelCmd) Run() error {
+	if cmd.Alias == "" && !cmd.ClearAlias && cmd.State == "" && cmd.Environment == "" {
+		err := errors.New("nothing to change: give an alias, --clear-alias, --state or --environment")
+		log.Error(err.Error())
+		return err
+	}
+	if cmd.Alias != "" && cmd.ClearAlias {
+		err := errors.New("an alias can't be given together with --clear-alias")
+		log.Error(err.Error())
+		return err
+	}
+
+	ks := keystore.NewKeyStore(cmd.KeystoreDir)
+	metadata, err := ks.Metadata()
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+	info, err := resolveKeystoreAccount(ks, metadata, cmd.Account)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	err = metadata.Update(info.Address, func(meta *keystore.AccountMetadata) {
+		if cmd.Alias != "" || cmd.ClearAlias {
+			meta.Alias = cmd.Alias
+		}
+		if cmd.State != "" {
+			meta.State = cmd.State
+		}
+		if cmd.Environment != "" {
+			meta.Environment = cmd.Environment
+		}
+	})
+	if err == nil {
+		err = metadata.Save()
+	}
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	info.Metadata = metadata.Get(info.Address)
+	return writeAccountInfos([]keystore.AccountInfo{info})
+}
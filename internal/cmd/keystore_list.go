@@ -2,21 +2,44 @@ package cmd
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/aldoborrero/ethw/internal/keystore"
 	"github.com/aldoborrero/ethw/internal/utils/output"
+	"github.com/charmbracelet/log"
 )
 
 type keystoreListCmd struct {
-	KeystoreDir string `flag:"" optional:"" type:"path" default:"./keystore" help:"Directory where the keystore is located"`
+	KeystoreDir   string `flag:"" optional:"" type:"path" default:"./keystore" help:"Directory where the keystore is located"`
+	AddressPrefix string `flag:"" optional:"" help:"Only list accounts whose address starts with this prefix"`
+	KDF           string `flag:"" optional:"" name:"kdf" enum:",scrypt,pbkdf2" default:"" help:"Only list accounts encrypted with this KDF (scrypt or pbkdf2)"`
+	CreatedAfter  string `flag:"" optional:"" help:"Only list accounts created at or after this date (YYYY-MM-DD or RFC3339)"`
+	CreatedBefore string `flag:"" optional:"" help:"Only list accounts created before this date (YYYY-MM-DD or RFC3339)"`
+	Tag           string `flag:"" optional:"" help:"Only list accounts carrying this tag"`
+	State         string `flag:"" optional:"" enum:",active,retired,compromised" default:"" help:"Only list accounts in this lifecycle state (active, retired or compromised)"`
+	Sort          string `flag:"" optional:"" enum:"path,address,created,kdf,alias" default:"path" help:"Sort accounts by path, address, created, kdf or alias"`
+	Reverse       bool   `flag:"" optional:"" help:"Reverse the sort order"`
 }
 
 func (cmd *keystoreListCmd) Run() error {
+	filter, err := cmd.filter()
+	if err != nil {
+		return err
+	}
+
 	// Initialize the keystore
 	ks := keystore.NewKeyStore(cmd.KeystoreDir)
 
-	// Fetch all accounts (if any)
-	accounts := ks.Accounts()
+	// Fetch all accounts (if any) with their key file metadata
+	infos, err := ks.AccountInfos()
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+	infos = keystore.FilterAccountInfos(infos, filter)
+	if err := keystore.SortAccountInfos(infos, cmd.Sort, cmd.Reverse); err != nil {
+		return err
+	}
 
 	// Prepare output writer
 	var writer output.KeystoreOutputWriter
@@ -32,9 +55,44 @@ func (cmd *keystoreListCmd) Run() error {
 	}
 
 	// Write result
-	if err := writer.WriteListOutput(accounts); err != nil {
+	if err := writer.WriteListOutput(infos); err != nil {
 		return fmt.Errorf("failed to generate output: %w", err)
 	}
 
 	return nil
 }
+
+// filter maps the filtering flags into a keystore list filter.
+func (cmd *keystoreListCmd) filter() (keystore.ListFilter, error) {
+	filter := keystore.ListFilter{
+		AddressPrefix: cmd.AddressPrefix,
+		KDF:           cmd.KDF,
+		Tag:           cmd.Tag,
+		State:         cmd.State,
+	}
+
+	var err error
+	if filter.CreatedAfter, err = parseDate(cmd.CreatedAfter); err != nil {
+		return keystore.ListFilter{}, err
+	}
+	if filter.CreatedBefore, err = parseDate(cmd.CreatedBefore); err != nil {
+		return keystore.ListFilter{}, err
+	}
+
+	return filter, nil
+}
+
+// parseDate parses a YYYY-MM-DD date or a RFC3339 timestamp, returning the zero time for an empty string.
+func parseDate(raw string) (time.Time, error) {
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse("2006-01-02", raw); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q: expected YYYY-MM-DD or RFC3339", raw)
+	}
+	return t, nil
+}
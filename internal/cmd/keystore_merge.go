@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/aldoborrero/ethw/internal/keystore"
+	"github.com/aldoborrero/ethw/internal/utils/output"
+	"github.com/alecthomas/kong"
+	"github.com/charmbracelet/log"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type keystoreMergeCmd struct {
+	Dirs       []string `arg:"" type:"path" help:"Source keystore directories followed by the destination directory, e.g. staging/ prod/ merged/"`
+	OnConflict string   `flag:"" optional:"" enum:"fail,skip,replace" default:"fail" help:"What to do with key files conflicting with the destination: fail, skip (keep the destination) or replace it"`
+	DryRun     bool     `flag:"" optional:"" help:"Only show what would be merged, without writing anything"`
+}
+
+func (cmd *keystoreMergeCmd) Run() error {
+	if len(cmd.Dirs) < 2 {
+		err := errors.New("expected at least one source and a destination directory")
+		log.Error(err.Error())
+		return err
+	}
+	sources, destination := cmd.Dirs[:len(cmd.Dirs)-1], cmd.Dirs[len(cmd.Dirs)-1]
+
+	return mergeKeystores(sources, destination, nil, cmd.OnConflict, cmd.DryRun)
+}
+
+// mergeKeystores merges the accounts of the sources, or only the given addresses, into the destination keystore and
+// carries their metadata over, writing the results in the selected output format.
+func mergeKeystores(sources []string, destination string, addresses []common.Address, onConflict string, dryRun bool) error {
+	ks := keystore.NewKeyStore(kong.ExpandPath(destination))
+	metadata, err := ks.Metadata()
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	results, mergeErr := ks.Merge(sources, keystore.MergeOptions{
+		Addresses:  addresses,
+		OnConflict: onConflict,
+		DryRun:     dryRun,
+	})
+	if results == nil && mergeErr != nil {
+		log.Error(mergeErr.Error())
+		return mergeErr
+	}
+
+	var writer output.KeystoreOutputWriter
+	switch Cli.OutputFormat {
+	case "json":
+		writer = output.KeystoreJSONOutputWriter{}
+	case "csv":
+		writer = output.KeystoreCSVOutputWriter{}
+	case "table":
+		writer = output.KeystoreTableOutputWriter{}
+	default:
+		writer = output.KeystoreTextOutputWriter{}
+	}
+
+	if err := writer.WriteMergeOutput(results, dryRun); err != nil {
+		return fmt.Errorf("failed to generate output: %w", err)
+	}
+
+	if mergeErr != nil {
+		err := fmt.Errorf("%w, the destination was left untouched", mergeErr)
+		log.Error(err.Error())
+		return err
+	}
+	if dryRun {
+		return nil
+	}
+
+	if err := mergeMetadata(metadata, results); err != nil {
+		log.Error(err.Error())
+		return fmt.Errorf("key files were merged, but %w", err)
+	}
+	return nil
+}
+
+// mergeMetadata copies the metadata of the written accounts from their source keystore, for accounts without
+// metadata in the destination. Aliases already used in the destination are dropped.
+func mergeMetadata(metadata *keystore.Metadata, results []keystore.MergeResult) error {
+	sources := make(map[string]*keystore.Metadata)
+	for _, result := range results {
+		if !result.Written() {
+			continue
+		}
+		if _, ok := metadata.Accounts[result.Address]; ok {
+			continue
+		}
+
+		dir := filepath.Dir(result.Source)
+		source, ok := sources[dir]
+		if !ok {
+			var err error
+			if source, err = keystore.ReadMetadata(dir); err != nil {
+				return err
+			}
+			sources[dir] = source
+		}
+		meta, ok := source.Accounts[result.Address]
+		if !ok {
+			continue
+		}
+
+		if other, taken := metadata.FindAlias(meta.Alias); meta.Alias != "" && taken && other != result.Address {
+			log.Warnf("Dropping alias %q of %s, already used by %s", meta.Alias, result.Address.Hex(), other.Hex())
+			meta.Alias = ""
+		}
+		if err := metadata.Update(result.Address, func(m *keystore.AccountMetadata) { *m = meta }); err != nil {
+			return err
+		}
+	}
+
+	return metadata.Save()
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+
+	"github.com/aldoborrero/ethw/internal/build"
+	"github.com/aldoborrero/ethw/internal/keystore"
+	"github.com/aldoborrero/ethw/internal/utils/output"
+)
+
+// createdBy identifies who created an account, as <user>@<host> (ethw <version>).
+func createdBy() string {
+	username := "unknown"
+	if current, err := user.Current(); err == nil {
+		username = current.Username
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s@%s (%s %s)", username, hostname, build.Name, build.Version)
+}
+
+// resolveKeystoreAccount returns the account of the keystore designated by an address or an alias, with its metadata.
+func resolveKeystoreAccount(ks *keystore.KeystoreWrapper, metadata *keystore.Metadata, ref string) (keystore.AccountInfo, error) {
+	address, err := metadata.Resolve(ref)
+	if err != nil {
+		return keystore.AccountInfo{}, err
+	}
+	account, err := ks.Find(address)
+	if err != nil {
+		return keystore.AccountInfo{}, err
+	}
+
+	info := keystore.NewAccountInfo(account)
+	info.Metadata = metadata.Get(address)
+	return info, nil
+}
+
+// writeAccountInfos writes accounts in the selected output format, like keystore list.
+func writeAccountInfos(infos []keystore.AccountInfo) error {
+	var writer output.KeystoreOutputWriter
+	switch Cli.OutputFormat {
+	case "json":
+		writer = output.KeystoreJSONOutputWriter{}
+	case "csv":
+		writer = output.KeystoreCSVOutputWriter{}
+	case "table":
+		writer = output.KeystoreTableOutputWriter{}
+	default:
+		writer = output.KeystoreTextOutputWriter{}
+	}
+
+	if err := writer.WriteListOutput(infos); err != nil {
+		return fmt.Errorf("failed to generate output: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/aldoborrero/ethw/internal/keystore"
+	"github.com/aldoborrero/ethw/internal/password"
+	"github.com/aldoborrero/ethw/internal/utils/output"
+	"github.com/alecthomas/kong"
+	"github.com/charmbracelet/log"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type keystorePasswdCmd struct {
+	Addresses   []string        `arg:"" help:"Addresses of the accounts whose password will be changed"`
+	KeystoreDir string          `flag:"" optional:"" type:"path" default:"./keystore" help:"Directory where the keystore is located"`
+	KDF         kdfOptions      `embed:""`
+	Password    passwordOptions `embed:""`
+	NewPassword passwordOptions `embed:"" prefix:"new-"`
+}
+
+func (cmd *keystorePasswdCmd) Run(ctx *kong.Context) error {
+	addresses, err := parseAddresses(cmd.Addresses)
+	if err != nil {
+		return err
+	}
+
+	// Key files keep the KDF they're encrypted with, unless KDF flags are given
+	var kdf *keystore.KDF
+	if cmd.KDF.given(ctx) {
+		flagKDF, err := cmd.KDF.toKDF()
+		if err != nil {
+			log.Error(err.Error())
+			return err
+		}
+		kdf = &flagKDF
+	}
+
+	oldPasswords, err := resolvePasswordPrompt(cmd.Password, nil, false, password.Prompt{Label: "Current password"})
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+	newPasswords, err := resolvePasswordPrompt(cmd.NewPassword, nil, false, password.Prompt{Confirm: true, Label: "New password"})
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	ks := keystore.NewKeyStore(kong.ExpandPath(cmd.KeystoreDir))
+
+	updated := make([]keystore.AccountInfo, 0, len(addresses))
+	for i, address := range addresses {
+		oldPassword, err := passwordFor(oldPasswords, i, address.Hex())
+		if err != nil {
+			return err
+		}
+		newPassword, err := passwordFor(newPasswords, i, address.Hex())
+		if err != nil {
+			return err
+		}
+
+		log.Infof("Changing password of account %s", address.Hex())
+		if err := ks.ChangePassword(address, oldPassword, newPassword, kdf); err != nil {
+			log.Errorf("Failed to change password of account %s: %v", address.Hex(), err)
+			return err
+		}
+		account, err := ks.Find(address)
+		if err != nil {
+			return err
+		}
+		updated = append(updated, keystore.NewAccountInfo(account))
+	}
+
+	var writer output.KeystoreOutputWriter
+	switch Cli.OutputFormat {
+	case "json":
+		writer = output.KeystoreJSONOutputWriter{}
+	case "csv":
+		writer = output.KeystoreCSVOutputWriter{}
+	case "table":
+		writer = output.KeystoreTableOutputWriter{}
+	default:
+		writer = output.KeystoreTextOutputWriter{}
+	}
+
+	if err := writer.WriteListOutput(updated); err != nil {
+		return fmt.Errorf("failed to generate output: %w", err)
+	}
+
+	return nil
+}
+
+// parseAddresses validates and decodes hex encoded addresses given on the command line.
+func parseAddresses(raw []string) ([]common.Address, error) {
+	addresses := make([]common.Address, len(raw))
+	for i, address := range raw {
+		if !common.IsHexAddress(address) {
+			return nil, fmt.Errorf("invalid address: %q", address)
+		}
+		addresses[i] = common.HexToAddress(address)
+	}
+	return addresses, nil
+}
@@ -0,0 +1,300 @@
+package cmd
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/aldoborrero/ethw/internal/keystore"
+	"github.com/aldoborrero/ethw/internal/password"
+	"github.com/aldoborrero/ethw/internal/utils/output"
+	"github.com/aldoborrero/ethw/internal/utils/pool"
+	"github.com/aldoborrero/ethw/internal/wallet"
+	"github.com/alecthomas/kong"
+	"github.com/charmbracelet/log"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+type keystoreRestoreCmd struct {
+	MnemonicFile string          `flag:"" required:"" type:"existingfile" help:"File with the mnemonic the accounts are derived from"`
+	Scheme       string          `flag:"" optional:"" default:"bip44" help:"Derivation path scheme: bip44, ledger-live, ledger-legacy or a template such as \"m/44'/60'/0'/0/%d\""`
+	Range        string          `flag:"" required:"" help:"Inclusive range of account indexes to restore, e.g. 0-49"`
+	KeystoreDir  string          `flag:"" optional:"" type:"path" default:"./keystore" help:"Directory of the keystore to restore"`
+	DryRun       bool            `flag:"" optional:"" help:"Only compare the keystore with the derived accounts, without writing anything"`
+	Strict       bool            `flag:"" optional:"" help:"Fail when the keystore holds unexpected accounts or invalid files"`
+	Verify       bool            `flag:"" optional:"" default:"true" negatable:"" help:"Decrypt every written key file again to check it round-trips"`
+	Jobs         int             `flag:"" optional:"" short:"j" default:"0" help:"Number of key files encrypted in parallel, 0 uses every CPU"`
+	MemoryLimit  int             `flag:"" optional:"" default:"2048" help:"Memory, in MiB, the key derivations running in parallel may use, lowering --jobs for memory hungry scrypt parameters"`
+	KDF          kdfOptions      `embed:""`
+	Password     passwordOptions `embed:""`
+}
+
+func (cmd *keystoreRestoreCmd) Run() error {
+	mnemonic, err := readMnemonicFile(kong.ExpandPath(cmd.MnemonicFile))
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	start, end, err := wallet.ParseRange(cmd.Range)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	kdf, err := cmd.KDF.toKDF()
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	log.Infof("Deriving accounts %d to %d with scheme %s", start, end, cmd.Scheme)
+	wallets, err := wallet.NewWallets(mnemonic, cmd.Scheme, start, end)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	derived := make([]keystore.DesiredAccount, len(wallets))
+	keys := make([]*ecdsa.PrivateKey, len(wallets))
+	for i, w := range wallets {
+		if keys[i], err = crypto.HexToECDSA(w.PrivateKey); err != nil {
+			err = fmt.Errorf("failed to decode private key of account %d: %w", start+i, err)
+			log.Error(err.Error())
+			return err
+		}
+		derived[i] = keystore.DesiredAccount{Address: common.HexToAddress(w.Address), DerivationPath: w.DerivationPath}
+	}
+
+	ks := keystore.NewKeyStoreWithKDF(kong.ExpandPath(cmd.KeystoreDir), kdf)
+	entries, err := ks.RestorePlan(derived, start)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	missing := 0
+	for _, entry := range entries {
+		if entry.Status == keystore.RestoreMissing {
+			missing++
+		}
+	}
+
+	// A password is required to write key files, without any to write it only enables decryption checks
+	var passwords password.Source
+	if missing > 0 && !cmd.DryRun {
+		passwords, err = resolvePasswordSource(cmd.Password, nil, false, true)
+	} else {
+		passwords, err = cmd.Password.source(false)
+	}
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	cmd.verifyExisting(entries, mnemonic, passwords, start)
+
+	var metadata *keystore.Metadata
+	if missing > 0 && !cmd.DryRun {
+		if metadata, err = ks.Metadata(); err != nil {
+			log.Error(err.Error())
+			return err
+		}
+		if err := cmd.restore(ks, entries, keys, mnemonic, passwords, start); err != nil {
+			err = fmt.Errorf("%w, the keystore was left untouched", err)
+			log.Error(err.Error())
+			return err
+		}
+		if err := recordRestoreMetadata(metadata, entries); err != nil {
+			err = fmt.Errorf("key files were restored, but %w", err)
+			log.Error(err.Error())
+			return err
+		}
+	}
+
+	var writer output.KeystoreOutputWriter
+	switch Cli.OutputFormat {
+	case "json":
+		writer = output.KeystoreJSONOutputWriter{}
+	case "csv":
+		writer = output.KeystoreCSVOutputWriter{}
+	case "table":
+		writer = output.KeystoreTableOutputWriter{}
+	default:
+		writer = output.KeystoreTextOutputWriter{}
+	}
+
+	if err := writer.WriteRestoreOutput(entries, cmd.DryRun); err != nil {
+		return fmt.Errorf("failed to generate output: %w", err)
+	}
+
+	mismatches, unexpected := 0, 0
+	for _, entry := range entries {
+		switch entry.Status {
+		case keystore.RestoreMismatch:
+			mismatches++
+		case keystore.RestoreUnexpected, keystore.RestoreInvalid:
+			unexpected++
+		}
+	}
+	if unexpected > 0 {
+		log.Warnf("The keystore holds %d unexpected account(s) or invalid file(s)", unexpected)
+	}
+	if mismatches > 0 {
+		return fmt.Errorf("%d existing key file(s) don't match the derived accounts", mismatches)
+	}
+	if unexpected > 0 && cmd.Strict {
+		return fmt.Errorf("%d unexpected account(s) or invalid file(s) in the keystore", unexpected)
+	}
+
+	return nil
+}
+
+// verifyExisting checks the key files of the derived accounts already in the keystore, decrypting them when a
+// password is available, and marks those failing any check as mismatching.
+func (cmd *keystoreRestoreCmd) verifyExisting(entries []keystore.RestoreEntry, mnemonic string, passwords password.Source, start int) {
+	for i, entry := range entries {
+		if entry.Status != keystore.RestoreMatching {
+			continue
+		}
+
+		opts := keystore.VerifyOptions{Mnemonic: mnemonic, DerivationPath: entry.DerivationPath}
+		if passwords != nil {
+			pass, err := passwordFor(passwords, entry.Index-start, entry.Address.Hex())
+			if err != nil {
+				log.Warnf("No password for account %d, skipping decryption: %v", entry.Index, err)
+			} else {
+				opts.Password, opts.HasPassword = pass, true
+			}
+		}
+
+		log.Infof("Verifying key file %s of account %d", entry.KeyFile, entry.Index)
+		if verification := keystore.VerifyKeyFile(entry.KeyFile, opts); !verification.OK() {
+			entries[i].Status = keystore.RestoreMismatch
+			entries[i].Reason = strings.Join(verification.Failures(), "; ")
+		}
+	}
+}
+
+// restore encrypts the missing accounts on a pool of workers and commits their key files in a single transaction.
+// Passwords are read first, in index order, as they may be prompted for.
+func (cmd *keystoreRestoreCmd) restore(ks *keystore.KeystoreWrapper, entries []keystore.RestoreEntry, keys []*ecdsa.PrivateKey, mnemonic string, passwords password.Source, start int) error {
+	var writes []int
+	passwordsByEntry := make(map[int]string)
+	for i, entry := range entries {
+		if entry.Status != keystore.RestoreMissing {
+			continue
+		}
+		pass, err := passwordFor(passwords, entry.Index-start, entry.Address.Hex())
+		if err != nil {
+			return fmt.Errorf("failed to get password for account %d: %w", entry.Index, err)
+		}
+		passwordsByEntry[i] = pass
+		writes = append(writes, i)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	tx, err := ks.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil {
+			log.Error(err.Error())
+		}
+	}()
+
+	var (
+		mu       sync.Mutex
+		failures []string
+		keyFiles = make([]string, len(writes))
+	)
+	fail := func(err error) {
+		log.Error(err.Error())
+		mu.Lock()
+		failures = append(failures, err.Error())
+		mu.Unlock()
+	}
+
+	pool.Run(len(writes), kdfWorkers(cmd.Jobs, cmd.MemoryLimit, ks.KDF()), func(i int) {
+		entry := entries[writes[i]]
+		if ctx.Err() != nil {
+			return
+		}
+
+		log.Infof("Restoring account %d with address %s", entry.Index, entry.Address.Hex())
+		keyJSON, err := keystore.EncryptKey(keys[entry.Index-start], passwordsByEntry[writes[i]], ks.KDF())
+		if err != nil {
+			fail(fmt.Errorf("failed to encrypt private key of account %d: %w", entry.Index, err))
+			return
+		}
+		account, staged, err := tx.WriteKey(entry.Address, "", keyJSON, false)
+		if err != nil {
+			fail(fmt.Errorf("failed to stage key file of account %d: %w", entry.Index, err))
+			return
+		}
+
+		if cmd.Verify {
+			verification := keystore.VerifyKeyFile(staged, keystore.VerifyOptions{
+				Password:       passwordsByEntry[writes[i]],
+				HasPassword:    true,
+				Mnemonic:       mnemonic,
+				DerivationPath: entry.DerivationPath,
+			})
+			if !verification.OK() {
+				fail(fmt.Errorf("key file of account %d failed verification: %s", entry.Index, strings.Join(verification.Failures(), "; ")))
+				return
+			}
+		}
+		keyFiles[i] = account.URL.Path
+	})
+
+	if ctx.Err() != nil {
+		return errors.New("interrupted")
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("failed to restore %d of %d key files", len(failures), len(writes))
+	}
+
+	log.Infof("Committing %d key files into %s", len(writes), ks.Dir())
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	for i, index := range writes {
+		entries[index].Status = keystore.RestoreRestored
+		entries[index].KeyFile = keyFiles[i]
+	}
+	return nil
+}
+
+// recordRestoreMetadata records the derivation path of the derived accounts lacking a derivation source, and who
+// restored the written ones.
+func recordRestoreMetadata(metadata *keystore.Metadata, entries []keystore.RestoreEntry) error {
+	for _, entry := range entries {
+		if entry.Status != keystore.RestoreRestored && entry.Status != keystore.RestoreMatching {
+			continue
+		}
+		err := metadata.Update(entry.Address, func(meta *keystore.AccountMetadata) {
+			if entry.Status == keystore.RestoreRestored {
+				meta.CreatedBy = createdBy()
+			}
+			if meta.Source == nil {
+				meta.Source = &keystore.DerivationSource{Type: keystore.SourceMnemonic, Path: entry.DerivationPath}
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return metadata.Save()
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"github.com/aldoborrero/ethw/internal/keystore"
+	"github.com/charmbracelet/log"
+)
+
+type keystoreTagCmd struct {
+	Account     string   `arg:"" help:"Address or alias of the account"`
+	Tags        []string `arg:"" help:"Tags to add to the account, e.g. ci or team:infra"`
+	Remove      bool     `flag:"" optional:"" help:"Remove the tags instead of adding them"`
+	KeystoreDir string   `flag:"" optional:"" type:"path" default:"./keystore" help:"Directory where the keystore is located"`
+}
+
+func (cmd *keystoreTagCmd) Run() error {
+	ks := keystore.NewKeyStore(cmd.KeystoreDir)
+	metadata, err := ks.Metadata()
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+	info, err := resolveKeystoreAccount(ks, metadata, cmd.Account)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	err = metadata.Update(info.Address, func(meta *keystore.AccountMetadata) {
+		if cmd.Remove {
+			meta.RemoveTags(cmd.Tags...)
+		} else {
+			meta.AddTags(cmd.Tags...)
+		}
+	})
+	if err == nil {
+		err = metadata.Save()
+	}
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	info.Metadata = metadata.Get(info.Address)
+	return writeAccountInfos([]keystore.AccountInfo{info})
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/aldoborrero/ethw/internal/keystore"
+	"github.com/aldoborrero/ethw/internal/utils/output"
+	"github.com/aldoborrero/ethw/internal/wallet"
+	"github.com/alecthomas/kong"
+	"github.com/charmbracelet/log"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type keystoreVerifyCmd struct {
+	KeystoreDir  string          `flag:"" optional:"" type:"path" default:"./keystore" help:"Directory where the keystore is located"`
+	MnemonicFile string          `flag:"" optional:"" type:"path" help:"Confirm every account is derivable from the mnemonic stored in this file"`
+	Scheme       string          `flag:"" optional:"" default:"bip44" help:"Derivation path scheme used with --mnemonic-file: bip44, ledger-live, ledger-legacy or a template such as \"m/44'/60'/0'/0/%d\""`
+	Range        string          `flag:"" optional:"" default:"0-9" help:"Inclusive range of account indexes derived with --mnemonic-file"`
+	Password     passwordOptions `embed:""`
+}
+
+func (cmd *keystoreVerifyCmd) Run() error {
+	// Passwords are optional, decryption checks are skipped without them
+	passwords, err := cmd.Password.source(false)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	derivable, err := cmd.derivableAddresses()
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	files, err := keystore.KeyFiles(kong.ExpandPath(cmd.KeystoreDir))
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	results := make([]keystore.VerifyResult, 0, len(files))
+	for i, file := range files {
+		opts := keystore.VerifyOptions{Derivable: derivable}
+		if passwords != nil {
+			// The address is only known once the file is parsed, so look it up for keyring sources
+			address := ""
+			if keyFile, err := keystore.ReadKeyFile(file); err == nil {
+				address = keyFile.Address.Hex()
+			}
+			if opts.Password, err = passwordFor(passwords, i, address); err != nil {
+				log.Warnf("No password for key file %s, skipping decryption: %v", file, err)
+			} else {
+				opts.HasPassword = true
+			}
+		}
+
+		log.Infof("Verifying key file %s", file)
+		results = append(results, keystore.VerifyKeyFile(file, opts))
+	}
+
+	var writer output.KeystoreOutputWriter
+	switch Cli.OutputFormat {
+	case "json":
+		writer = output.KeystoreJSONOutputWriter{}
+	case "csv":
+		writer = output.KeystoreCSVOutputWriter{}
+	case "table":
+		writer = output.KeystoreTableOutputWriter{}
+	default:
+		writer = output.KeystoreTextOutputWriter{}
+	}
+
+	if err := writer.WriteVerifyOutput(results); err != nil {
+		return fmt.Errorf("failed to generate output: %w", err)
+	}
+
+	failed := 0
+	for _, result := range results {
+		if !result.OK() {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d key files failed verification", failed, len(results))
+	}
+
+	return nil
+}
+
+// derivableAddresses derives the accounts of the configured mnemonic and range, or returns nil without a mnemonic.
+func (cmd *keystoreVerifyCmd) derivableAddresses() (map[common.Address]string, error) {
+	if cmd.MnemonicFile == "" {
+		return nil, nil
+	}
+
+	mnemonic, err := readMnemonicFile(kong.ExpandPath(cmd.MnemonicFile))
+	if err != nil {
+		return nil, err
+	}
+
+	start, end, err := wallet.ParseRange(cmd.Range)
+	if err != nil {
+		return nil, err
+	}
+
+	wallets, err := wallet.NewWallets(mnemonic, cmd.Scheme, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	derivable := make(map[common.Address]string, len(wallets))
+	for _, w := range wallets {
+		derivable[common.HexToAddress(w.Address)] = w.DerivationPath
+	}
+	return derivable, nil
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/aldoborrero/ethw/internal/keystore"
+	"github.com/aldoborrero/ethw/internal/utils/output"
+	"github.com/charmbracelet/log"
+)
+
+type keystoreWatchCmd struct {
+	KeystoreDir string        `flag:"" optional:"" type:"path" default:"./keystore" help:"Directory where the keystore is located"`
+	Format      string        `flag:"" optional:"" enum:"ndjson,json" default:"ndjson" help:"Format of the event stream: ndjson (one event per line) or json (indented)"`
+	Existing    bool          `flag:"" optional:"" help:"Report the accounts already in the keystore as arrived when starting"`
+	Hook        string        `flag:"" optional:"" help:"Shell command run for every event, with the event as JSON on stdin and in the ETHW_EVENT, ETHW_WALLET_ADDRESS and ETHW_KEYSTORE_PATH environment variables"`
+	HookTimeout time.Duration `flag:"" optional:"" default:"30s" help:"Time after which a hook command is killed"`
+}
+
+func (cmd *keystoreWatchCmd) Run() error {
+	// Initialize the keystore
+	ks := keystore.NewKeyStore(cmd.KeystoreDir)
+
+	// Prepare output writer
+	var writer output.KeystoreWatchOutputWriter
+	switch cmd.Format {
+	case "json":
+		writer = output.KeystoreJSONWatchOutputWriter{}
+	default:
+		writer = output.KeystoreNDJSONWatchOutputWriter{}
+	}
+
+	// Stream events until interrupted
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	log.Infof("Watching keystore %s", ks.Dir())
+	err := ks.Watch(ctx, cmd.Existing, func(event keystore.WatchEvent) error {
+		if err := writer.WriteWatchEvent(event); err != nil {
+			return fmt.Errorf("failed to generate output: %w", err)
+		}
+		if cmd.Hook != "" {
+			// A failing hook is reported but doesn't stop the watch, later events may still be handled
+			if err := cmd.runHook(ctx, event); err != nil {
+				log.Error(err.Error())
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// runHook runs the hook command for the event, waiting for it to finish so events are handled in order. Its output
+// goes to stderr, keeping the event stream on stdout intact.
+func (cmd *keystoreWatchCmd) runHook(ctx context.Context, event keystore.WatchEvent) error {
+	eventJSON, err := output.WatchEventJSON(event)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cmd.HookTimeout)
+	defer cancel()
+
+	hook := exec.CommandContext(ctx, "sh", "-c", cmd.Hook)
+	hook.Env = append(os.Environ(),
+		fmt.Sprintf("ETHW_EVENT=%s", event.Type),
+		fmt.Sprintf("ETHW_WALLET_ADDRESS=%s", event.Address.Hex()),
+		fmt.Sprintf("ETHW_KEYSTORE_PATH=%s", event.Path),
+	)
+	hook.Stdin = bytes.NewReader(append(eventJSON, '\n'))
+	hook.Stdout = os.Stderr
+	hook.Stderr = os.Stderr
+
+	if err := hook.Run(); err != nil {
+		return fmt.Errorf("hook failed for %s event of %s: %w", event.Type, event.Address.Hex(), err)
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/tyler-smith/go-bip39"
+)
+
+// readMnemonicFile reads a BIP-39 mnemonic from a file, so it doesn't leak into the shell history.
+func readMnemonicFile(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read mnemonic file: %w", err)
+	}
+
+	mnemonic := strings.Join(strings.Fields(string(content)), " ")
+	if !bip39.IsMnemonicValid(mnemonic) {
+		return "", errInvalidSeedMnemonic
+	}
+
+	return mnemonic, nil
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aldoborrero/ethw/internal/password"
+)
+
+var (
+	errMultiplePasswordSources = errors.New("only one password source can be used at a time")
+	errInlinePasswordUnsafe    = errors.New("inline passwords leak into shell history and ps, pass --unsafe-inline-password to use them anyway")
+	errNoPasswordSource        = errors.New("no password source given and stdin is not a terminal")
+)
+
+// passwordOptions groups the flags that select where keystore passwords are read from.
+type passwordOptions struct {
+	PasswordFile    string `flag:"" optional:"" type:"path" help:"File with one password per line, mapped to wallets in order (the last line is reused)"`
+	PasswordEnv     string `flag:"" optional:"" help:"Environment variable holding the password, <NAME>_<n> overrides it for the n-th wallet"`
+	PasswordPrompt  bool   `flag:"" optional:"" help:"Prompt for passwords without echoing them"`
+	PasswordCommand string `flag:"" optional:"" help:"Shell command printing the password, e.g. 'pass show devnet/keystore'"`
+	PasswordKeyring string `flag:"" optional:"" help:"OS keyring service where passwords are stored under each wallet address"`
+}
+
+// source returns the configured password source, or nil when no source flag is set. Confirmation is required for
+// prompted passwords when confirm is set.
+func (o passwordOptions) source(confirm bool) (password.Source, error) {
+	return o.promptSource(password.Prompt{Confirm: confirm})
+}
+
+// promptSource is like source, but prompts for passwords with prompt when --password-prompt is set.
+func (o passwordOptions) promptSource(prompt password.Prompt) (password.Source, error) {
+	var sources []password.Source
+
+	if o.PasswordFile != "" {
+		file, err := password.NewFile(o.PasswordFile)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, file)
+	}
+	if o.PasswordEnv != "" {
+		sources = append(sources, password.Env{Name: o.PasswordEnv})
+	}
+	if o.PasswordPrompt {
+		sources = append(sources, prompt)
+	}
+	if o.PasswordCommand != "" {
+		sources = append(sources, password.Command{Command: o.PasswordCommand})
+	}
+	if o.PasswordKeyring != "" {
+		sources = append(sources, password.Keyring{Service: o.PasswordKeyring})
+	}
+
+	switch len(sources) {
+	case 0:
+		return nil, nil
+	case 1:
+		return sources[0], nil
+	default:
+		return nil, errMultiplePasswordSources
+	}
+}
+
+// resolvePasswordSource combines the inline passwords given in wallet specs with the configured password source.
+// Inline passwords take precedence but are only accepted when unsafe is set. Without source flag, passwords are
+// prompted for when stdin is a terminal, with confirmation when confirm is set.
+func resolvePasswordSource(opts passwordOptions, inline password.Static, unsafe, confirm bool) (password.Source, error) {
+	return resolvePasswordPrompt(opts, inline, unsafe, password.Prompt{Confirm: confirm})
+}
+
+// resolvePasswordPrompt is like resolvePasswordSource, but prompts for passwords with prompt.
+func resolvePasswordPrompt(opts passwordOptions, inline password.Static, unsafe bool, prompt password.Prompt) (password.Source, error) {
+	hasInline := false
+	for _, p := range inline {
+		if p != "" {
+			hasInline = true
+			break
+		}
+	}
+	if hasInline && !unsafe {
+		return nil, errInlinePasswordUnsafe
+	}
+
+	source, err := opts.promptSource(prompt)
+	if err != nil {
+		return nil, err
+	}
+	if source == nil {
+		if !password.IsTerminal() {
+			if hasInline {
+				return inline, nil
+			}
+			return nil, errNoPasswordSource
+		}
+		source = prompt
+	}
+
+	if hasInline {
+		return password.Fallback{inline, source}, nil
+	}
+	return source, nil
+}
+
+// passwordFor reads the password for the given wallet, wrapping errors with the wallet position.
+func passwordFor(source password.Source, index int, address string) (string, error) {
+	pass, err := source.Password(password.Request{Index: index, Address: address})
+	if err != nil {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+	return pass, nil
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/aldoborrero/ethw/internal/password"
+	"github.com/aldoborrero/ethw/internal/signer"
+	"github.com/charmbracelet/log"
+)
+
+type signerServeCmd struct {
+	Accounts    accountOptions  `embed:""`
+	ChainID     uint64          `flag:"" required:"" name:"chain-id" help:"Chain ID transactions are signed for, e.g. 1337 for geth --dev or 31337 for Anvil"`
+	HTTPAddr    string          `flag:"" optional:"" name:"http-addr" default:"localhost:8550" help:"Address of the HTTP endpoint, empty to disable it"`
+	HTTPCORS    []string        `flag:"" optional:"" name:"http-cors" help:"Origins browsers may call the HTTP endpoint from, repeatable, * allows any"`
+	HTTPVHosts  []string        `flag:"" optional:"" name:"http-vhosts" default:"localhost" help:"Host names accepted by the HTTP endpoint, repeatable, * allows any"`
+	IPCPath     string          `flag:"" optional:"" type:"path" name:"ipc-path" help:"Path of the Unix socket endpoint, e.g. ./signer.ipc"`
+	AutoApprove bool            `flag:"" optional:"" help:"Approve every request without asking, only for development networks"`
+	Password    passwordOptions `embed:""`
+}
+
+func (cmd *signerServeCmd) Run() error {
+	var approver signer.Approver = signer.AutoApprove{}
+	if !cmd.AutoApprove {
+		if !password.IsTerminal() {
+			err := errors.New("requests are approved interactively, which needs a terminal; pass --auto-approve to approve every request")
+			log.Error(err.Error())
+			return err
+		}
+		approver = signer.NewPrompt(os.Stdin, os.Stderr)
+	}
+
+	accounts, err := cmd.Accounts.accounts()
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+	if len(accounts) == 0 {
+		err := errors.New("no accounts to serve")
+		log.Error(err.Error())
+		return err
+	}
+	keys, err := cmd.Accounts.unlock(accounts, cmd.Password)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	s := signer.New(keys, new(big.Int).SetUint64(cmd.ChainID), approver)
+	server, err := signer.NewServer(s)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	for _, address := range s.Accounts() {
+		log.Infof("Serving account %s", address.Hex())
+	}
+	err = signer.Serve(ctx, server, cmd.serveOptions(), func(endpoint string) {
+		fmt.Printf("Serving %d accounts for chain %d on %s\n", len(s.Accounts()), cmd.ChainID, endpoint)
+	})
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// serveOptions returns the endpoints to serve on. The IPC path was already expanded by kong when given, and stays
+// empty otherwise, disabling the IPC endpoint.
+func (cmd *signerServeCmd) serveOptions() signer.ServeOptions {
+	return signer.ServeOptions{
+		HTTPAddr: cmd.HTTPAddr,
+		CORS:     cmd.HTTPCORS,
+		VHosts:   cmd.HTTPVHosts,
+		IPCPath:  cmd.IPCPath,
+	}
+}
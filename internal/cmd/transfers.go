@@ -0,0 +1,250 @@
+package cmd
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aldoborrero/ethw/internal/chain"
+	"github.com/aldoborrero/ethw/internal/keystore"
+	"github.com/aldoborrero/ethw/internal/utils/output"
+	"github.com/alecthomas/kong"
+	"github.com/charmbracelet/log"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// errInlineKeyUnsafe is returned for private keys given on the command line without --unsafe-inline-key.
+var errInlineKeyUnsafe = errors.New("inline private keys leak into shell history and ps, pass --unsafe-inline-key to use them anyway")
+
+// hexKeyRegex matches hex encoded private keys, as opposed to addresses and aliases.
+var hexKeyRegex = regexp.MustCompile(`^(0x)?[0-9a-fA-F]{64}$`)
+
+// feeOptions groups the flags overriding the suggested transaction fees.
+type feeOptions struct {
+	PriorityFee string `flag:"" optional:"" help:"Priority fee per gas, in gwei unless a unit is given, e.g. 2 (suggested by the node by default)"`
+	MaxFee      string `flag:"" optional:"" help:"Maximum fee per gas, in gwei unless a unit is given, e.g. 50 (twice the base fee plus the priority fee by default), the gas price on chains without EIP-1559"`
+}
+
+// fees suggests the transaction fees, applying the overrides.
+func (o feeOptions) fees(ctx context.Context, client *chain.Client) (chain.Fees, error) {
+	var tipCap, feeCap *big.Int
+	var err error
+	if o.PriorityFee != "" {
+		if tipCap, err = chain.ParseFee(o.PriorityFee); err != nil {
+			return chain.Fees{}, fmt.Errorf("invalid priority fee: %w", err)
+		}
+	}
+	if o.MaxFee != "" {
+		if feeCap, err = chain.ParseFee(o.MaxFee); err != nil {
+			return chain.Fees{}, fmt.Errorf("invalid maximum fee: %w", err)
+		}
+	}
+	return client.SuggestFees(ctx, tipCap, feeCap)
+}
+
+// unlockSigner returns the key of a keystore account given by address or alias, or of a hex encoded private key when
+// unsafe is set.
+func unlockSigner(ref, keystoreDir string, unsafe bool, passwordOpts passwordOptions) (*ecdsa.PrivateKey, error) {
+	if hexKeyRegex.MatchString(ref) {
+		if !unsafe {
+			return nil, errInlineKeyUnsafe
+		}
+		key, err := crypto.HexToECDSA(strings.TrimPrefix(ref, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid private key: %w", err)
+		}
+		return key, nil
+	}
+
+	ks := keystore.NewKeyStore(kong.ExpandPath(keystoreDir))
+	metadata, err := ks.Metadata()
+	if err != nil {
+		return nil, err
+	}
+	info, err := resolveKeystoreAccount(ks, metadata, ref)
+	if err != nil {
+		return nil, err
+	}
+	passwords, err := resolvePasswordSource(passwordOpts, nil, false, false)
+	if err != nil {
+		return nil, err
+	}
+	walletPassword, err := passwordFor(passwords, 0, info.Address.Hex())
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("Unlocking account %s", info.Address.Hex())
+	key, err := ks.DecryptKey(info.Address, walletPassword)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unlock account %s: %w", info.Address.Hex(), err)
+	}
+	return key.PrivateKey, nil
+}
+
+// transferRun sends transfers in batches, recording them in a journal so an interrupted run resumes them.
+type transferRun struct {
+	client    *chain.Client
+	chainID   *big.Int
+	fees      chain.Fees
+	journal   *chain.Journal
+	batchSize int
+	timeout   time.Duration
+	nonces    map[common.Address]uint64
+}
+
+// resume settles the transfers an interrupted run left pending, sending them again if needed, and waits for them.
+// Failed transfers are dropped from the journal to be planned again.
+func (r *transferRun) resume(ctx context.Context) error {
+	var pending []*chain.Transfer
+	for _, t := range append([]*chain.Transfer{}, r.journal.Transfers...) {
+		switch t.Status {
+		case chain.TransferPending:
+			log.Infof("Resuming transfer %s to %s", t.Hash.Hex(), t.To.Hex())
+			if err := r.client.Resume(ctx, t); err != nil {
+				return err
+			}
+			pending = append(pending, t)
+		case chain.TransferFailed:
+			r.journal.Drop(t)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	if err := r.journal.Save(); err != nil {
+		return err
+	}
+	return r.wait(ctx, pending)
+}
+
+// send signs and sends the transfers batch by batch with the keys of their senders, waiting for the receipts of a
+// batch before sending the next one. Transfers are recorded in the journal before being sent.
+func (r *transferRun) send(ctx context.Context, transfers []*chain.Transfer, keys map[common.Address]*ecdsa.PrivateKey) error {
+	if r.nonces == nil {
+		r.nonces = map[common.Address]uint64{}
+	}
+	for start := 0; start < len(transfers); start += r.batchSize {
+		end := start + r.batchSize
+		if end > len(transfers) {
+			end = len(transfers)
+		}
+		batch := transfers[start:end]
+
+		for _, t := range batch {
+			nonce, ok := r.nonces[t.From]
+			if !ok {
+				var err error
+				if nonce, err = r.client.PendingNonce(ctx, t.From); err != nil {
+					return fmt.Errorf("failed to read nonce of %s: %w", t.From.Hex(), err)
+				}
+			}
+			if err := t.Sign(r.chainID, r.fees, nonce, keys[t.From]); err != nil {
+				return err
+			}
+			// Signed transfers are pending from now on, a run interrupted while sending them submits them again
+			t.Status = chain.TransferPending
+			r.nonces[t.From] = nonce + 1
+			r.journal.Add(t)
+		}
+		if err := r.journal.Save(); err != nil {
+			return err
+		}
+
+		log.Infof("Sending transfers %d to %d of %d", start+1, end, len(transfers))
+		for i, t := range batch {
+			if err := r.client.Submit(ctx, t); err != nil {
+				// The nonces of the transfers left are now out of sequence, they're planned again by the next run
+				for _, unsent := range batch[i:] {
+					unsent.Status, unsent.Hash, unsent.Raw = chain.TransferPlanned, common.Hash{}, nil
+					r.journal.Drop(unsent)
+				}
+				if saveErr := r.journal.Save(); saveErr != nil {
+					log.Error(saveErr.Error())
+				}
+				if waitErr := r.wait(ctx, batch[:i]); waitErr != nil {
+					log.Error(waitErr.Error())
+				}
+				return err
+			}
+		}
+		if err := r.journal.Save(); err != nil {
+			return err
+		}
+		if err := r.wait(ctx, batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// wait waits up to the timeout for the receipts of the transfers, saving the journal.
+func (r *transferRun) wait(ctx context.Context, transfers []*chain.Transfer) error {
+	waitCtx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	err := r.client.Wait(waitCtx, transfers, time.Second)
+	if saveErr := r.journal.Save(); saveErr != nil {
+		return saveErr
+	}
+	if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+		return fmt.Errorf("transfers are still pending after %s, run the command again to resume them", r.timeout)
+	}
+	return err
+}
+
+// finish removes the journal once every transfer is confirmed, or tells how to resume the run.
+func (r *transferRun) finish() {
+	if r.journal.Done() {
+		if err := r.journal.Remove(); err != nil {
+			log.Error(err.Error())
+		}
+		return
+	}
+	if err := r.journal.Save(); err != nil {
+		log.Error(err.Error())
+		return
+	}
+	log.Warnf("Transfers are recorded in %s, run the same command again to resume", r.journal.Path())
+}
+
+// writeTransfers writes transfers in the selected output format.
+func writeTransfers(transfers []chain.WalletTransfer, dryRun bool) error {
+	var writer output.WalletOutputWriter
+	switch Cli.OutputFormat {
+	case "json":
+		writer = output.WalletJSONOutputWriter{}
+	case "csv":
+		writer = output.WalletCSVOutputWriter{}
+	case "table":
+		writer = output.WalletTableOutputWriter{}
+	default:
+		writer = output.WalletTextOuputWriter{}
+	}
+
+	if err := writer.WriteTransferOutput(transfers, dryRun); err != nil {
+		return fmt.Errorf("failed to generate output: %w", err)
+	}
+	return nil
+}
+
+// failedTransfers returns an error when transfers failed or weren't sent.
+func failedTransfers(transfers []chain.WalletTransfer) error {
+	failed, unsent := 0, 0
+	for _, t := range transfers {
+		switch t.Status {
+		case chain.TransferFailed:
+			failed++
+		case chain.TransferPlanned, chain.TransferPending:
+			unsent++
+		}
+	}
+	if failed > 0 || unsent > 0 {
+		return fmt.Errorf("%d of %d transfers failed and %d aren't confirmed", failed, len(transfers), unsent)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/aldoborrero/ethw/internal/signer"
+	"github.com/aldoborrero/ethw/internal/utils/output"
+	"github.com/alecthomas/kong"
+	"github.com/charmbracelet/log"
+)
+
+type txSignCmd struct {
+	Spec         string          `flag:"" optional:"" type:"existingfile" help:"JSON file with the transaction, in the format of eth_signTransaction, which the other flags override"`
+	To           string          `flag:"" optional:"" help:"Recipient of the transaction, a contract is created when omitted"`
+	Value        string          `flag:"" optional:"" help:"Ether sent, in ether unless a unit is given, e.g. 0.5 or 30gwei"`
+	Data         string          `flag:"" optional:"" help:"Hex encoded call data, or code of the contract created"`
+	Nonce        *uint64         `flag:"" optional:"" help:"Nonce of the sender (0 by default)"`
+	Gas          uint64          `flag:"" optional:"" help:"Gas limit"`
+	GasPrice     string          `flag:"" optional:"" help:"Gas price of a legacy or access list transaction, in gwei unless a unit is given, e.g. 20"`
+	MaxFee       string          `flag:"" optional:"" help:"Maximum fee per gas of an EIP-1559 transaction, in gwei unless a unit is given, e.g. 50"`
+	PriorityFee  string          `flag:"" optional:"" help:"Priority fee per gas of an EIP-1559 transaction, in gwei unless a unit is given, e.g. 2"`
+	ChainID      uint64          `flag:"" optional:"" name:"chain-id" help:"Chain ID the transaction is signed for, e.g. 1 for mainnet"`
+	AccessList   []string        `flag:"" optional:"" help:"Access list entry as <address>[:<storage key>...], repeatable"`
+	MnemonicFile string          `flag:"" optional:"" type:"existingfile" help:"Sign with an account derived from the mnemonic stored in this file"`
+	Scheme       string          `flag:"" optional:"" default:"bip44" help:"Derivation path scheme used with --mnemonic-file: bip44, ledger-live, ledger-legacy or a template such as \"m/44'/60'/0'/0/%d\""`
+	Index        int             `flag:"" optional:"" default:"0" help:"Index of the account derived with --mnemonic-file"`
+	KeystoreDir  string          `flag:"" optional:"" type:"path" help:"Keystore directory of the --account (./keystore by default)"`
+	Account      string          `flag:"" optional:"" help:"Sign with this keystore account, by address or alias"`
+	Password     passwordOptions `embed:""`
+}
+
+func (cmd *txSignCmd) Run() error {
+	var spec []byte
+	if cmd.Spec != "" {
+		var err error
+		if spec, err = os.ReadFile(kong.ExpandPath(cmd.Spec)); err != nil {
+			err = fmt.Errorf("failed to read transaction spec: %w", err)
+			log.Error(err.Error())
+			return err
+		}
+	}
+	args, err := signer.TxArgs(spec, signer.TxFields{
+		To:          cmd.To,
+		Value:       cmd.Value,
+		Data:        cmd.Data,
+		Nonce:       cmd.Nonce,
+		Gas:         cmd.Gas,
+		GasPrice:    cmd.GasPrice,
+		MaxFee:      cmd.MaxFee,
+		PriorityFee: cmd.PriorityFee,
+		ChainID:     cmd.ChainID,
+		AccessList:  cmd.AccessList,
+	})
+	switch {
+	case errors.Is(err, signer.ErrMissingChainID):
+		err = fmt.Errorf("%w, set chainId in the spec or pass --chain-id", err)
+	case errors.Is(err, signer.ErrMissingGas):
+		err = fmt.Errorf("%w, set gas in the spec or pass --gas", err)
+	}
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	accounts := accountOptions{KeystoreDir: cmd.KeystoreDir, MnemonicFile: cmd.MnemonicFile, Scheme: cmd.Scheme, Range: strconv.Itoa(cmd.Index)}
+	if cmd.Account != "" {
+		accounts.Account = []string{cmd.Account}
+	} else if cmd.MnemonicFile == "" {
+		err := errors.New("no signing account, pass --account or --mnemonic-file")
+		log.Error(err.Error())
+		return err
+	}
+	selected, err := accounts.accounts()
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+	from := selected[0].Address
+	if err := signer.SetSender(&args, from); err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	keys, err := accounts.unlock(selected, cmd.Password)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+	tx, err := signer.New(keys, args.ChainID.ToInt(), signer.AutoApprove{}).SignTransaction(args)
+	if err != nil {
+		err = fmt.Errorf("failed to sign transaction: %w", err)
+		log.Error(err.Error())
+		return err
+	}
+
+	var writer output.TxOutputWriter
+	switch Cli.OutputFormat {
+	case "json":
+		writer = output.TxJSONOutputWriter{}
+	case "csv":
+		writer = output.TxCSVOutputWriter{}
+	case "table":
+		writer = output.TxTableOutputWriter{}
+	default:
+		writer = output.TxTextOutputWriter{}
+	}
+
+	if err := writer.WriteSignOutput(tx, from); err != nil {
+		err = fmt.Errorf("failed to generate output: %w", err)
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
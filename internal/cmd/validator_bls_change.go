@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aldoborrero/ethw/internal/utils/output"
+	"github.com/aldoborrero/ethw/internal/validator"
+	"github.com/aldoborrero/ethw/internal/wallet"
+	"github.com/alecthomas/kong"
+	"github.com/charmbracelet/log"
+)
+
+type validatorBLSChangeCmd struct {
+	MnemonicFile          string                  `flag:"" required:"" type:"path" help:"File holding the mnemonic validator keys are derived from"`
+	Range                 string                  `flag:"" optional:"" default:"0" help:"Inclusive range of validator key indexes (derivation), e.g. 0-49"`
+	ValidatorIndexes      string                  `flag:"" required:"" help:"Beacon chain indexes of the validators, in key order, e.g. 1000-1049 or 1000,1004,1010"`
+	WithdrawalCredentials []string                `flag:"" optional:"" help:"Current withdrawal credentials of the validators, in key order, checked before signing"`
+	Execution             executionAddressOptions `embed:"" prefix:"execution-"`
+	OutputDir             string                  `flag:"" optional:"" type:"path" default:"./bls_to_execution_changes" help:"Directory to save the signed changes"`
+	Network               networkOptions          `embed:""`
+}
+
+func (cmd *validatorBLSChangeCmd) Run() error {
+	network, err := cmd.Network.toNetwork()
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+	if network.GenesisValidatorsRoot == [32]byte{} {
+		err := errors.New("BLS to execution changes require the --genesis-validators-root of the network")
+		log.Error(err.Error())
+		return err
+	}
+
+	address, err := cmd.Execution.address(cmd.MnemonicFile, true)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	keys, err := deriveValidatorKeys(cmd.MnemonicFile, cmd.Range)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	validatorIndexes, err := parseIndexList(cmd.ValidatorIndexes)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+	if len(validatorIndexes) != len(keys) {
+		err := fmt.Errorf("got %d validator indexes for %d keys", len(validatorIndexes), len(keys))
+		log.Error(err.Error())
+		return err
+	}
+	if len(cmd.WithdrawalCredentials) > 0 && len(cmd.WithdrawalCredentials) != len(keys) {
+		err := fmt.Errorf("got %d withdrawal credentials for %d keys", len(cmd.WithdrawalCredentials), len(keys))
+		log.Error(err.Error())
+		return err
+	}
+
+	changes := make([]validator.SignedBLSToExecutionChange, 0, len(keys))
+	for i, key := range keys {
+		if len(cmd.WithdrawalCredentials) > 0 {
+			if err := validator.CheckBLSWithdrawalCredentials(key.WithdrawalKey.PublicKey(), cmd.WithdrawalCredentials[i]); err != nil {
+				err = fmt.Errorf("validator %d: %w", validatorIndexes[i], err)
+				log.Error(err.Error())
+				return err
+			}
+		}
+
+		log.Infof("Signing BLS to execution change of validator %d (%s)", validatorIndexes[i], key.WithdrawalPath)
+		changes = append(changes, *validator.NewSignedBLSToExecutionChange(key.WithdrawalKey, validatorIndexes[i], *address, network))
+	}
+
+	path, err := validator.WriteBLSToExecutionChanges(kong.ExpandPath(cmd.OutputDir), changes, time.Now())
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	var writer output.ValidatorOutputWriter
+	switch Cli.OutputFormat {
+	case "json":
+		writer = output.ValidatorJSONOutputWriter{}
+	case "csv":
+		writer = output.ValidatorCSVOutputWriter{}
+	case "table":
+		writer = output.ValidatorTableOutputWriter{}
+	default:
+		writer = output.ValidatorTextOutputWriter{}
+	}
+
+	if err := writer.WriteBLSChangeOutput(path, changes); err != nil {
+		return fmt.Errorf("failed to generate output: %w", err)
+	}
+
+	return nil
+}
+
+// parseIndexList parses a comma separated list of indexes and inclusive index ranges, e.g. "1000-1009,1020".
+func parseIndexList(raw string) ([]uint64, error) {
+	var indexes []uint64
+	for _, part := range strings.Split(raw, ",") {
+		start, end, err := wallet.ParseRange(part)
+		if err != nil {
+			return nil, err
+		}
+		for index := start; index <= end; index++ {
+			indexes = append(indexes, uint64(index))
+		}
+	}
+	return indexes, nil
+}
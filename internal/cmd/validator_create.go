@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aldoborrero/ethw/internal/keystore"
+	"github.com/aldoborrero/ethw/internal/utils/output"
+	"github.com/aldoborrero/ethw/internal/validator"
+	"github.com/aldoborrero/ethw/internal/wallet"
+	"github.com/alecthomas/kong"
+	"github.com/charmbracelet/log"
+)
+
+type validatorCreateCmd struct {
+	MnemonicFile        string          `flag:"" required:"" type:"path" help:"File holding the mnemonic validator keys are derived from"`
+	Range               string          `flag:"" optional:"" default:"0" help:"Inclusive range of validator indexes, e.g. 0-49"`
+	KeystoreDir         string          `flag:"" optional:"" type:"path" default:"./validator_keys" help:"Directory to save the EIP-2335 keystores"`
+	WithdrawalKeystores bool            `flag:"" optional:"" help:"Also write keystores for the withdrawal keys (m/12381/3600/i/0)"`
+	KDF                 kdfOptions      `embed:""`
+	Password            passwordOptions `embed:""`
+}
+
+func (cmd *validatorCreateCmd) Run() error {
+	kdf, err := cmd.KDF.toKDF()
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	keys, err := deriveValidatorKeys(cmd.MnemonicFile, cmd.Range)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	passwords, err := resolvePasswordSource(cmd.Password, nil, false, true)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	absKeystoreDir := kong.ExpandPath(cmd.KeystoreDir)
+	now := time.Now()
+
+	results := make([]validator.CreateResult, 0, len(keys))
+	for i, key := range keys {
+		result := validator.CreateResult{
+			Index:            key.Index,
+			Pubkey:           key.Pubkey(),
+			SigningPath:      key.SigningPath,
+			WithdrawalPubkey: key.WithdrawalPubkey(),
+			WithdrawalPath:   key.WithdrawalPath,
+		}
+
+		keystorePassword, err := passwordFor(passwords, i, "0x"+result.Pubkey)
+		if err != nil {
+			log.Error(err.Error())
+			return err
+		}
+
+		log.Infof("Writing keystore of validator %d (%s)", key.Index, key.SigningPath)
+		if result.KeystorePath, err = writeValidatorKeystore(absKeystoreDir, key.SigningKey, keystorePassword, kdf, key.SigningPath, now); err != nil {
+			log.Error(err.Error())
+			return err
+		}
+		if cmd.WithdrawalKeystores {
+			if result.WithdrawalKeystorePath, err = writeValidatorKeystore(absKeystoreDir, key.WithdrawalKey, keystorePassword, kdf, key.WithdrawalPath, now); err != nil {
+				log.Error(err.Error())
+				return err
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	var writer output.ValidatorOutputWriter
+	switch Cli.OutputFormat {
+	case "json":
+		writer = output.ValidatorJSONOutputWriter{}
+	case "csv":
+		writer = output.ValidatorCSVOutputWriter{}
+	case "table":
+		writer = output.ValidatorTableOutputWriter{}
+	default:
+		writer = output.ValidatorTextOutputWriter{}
+	}
+
+	if err := writer.WriteCreateOutput(results); err != nil {
+		return fmt.Errorf("failed to generate output: %w", err)
+	}
+
+	return nil
+}
+
+// deriveValidatorKeys derives the validator keys of the given index range from the mnemonic stored in a file.
+func deriveValidatorKeys(mnemonicFile, indexRange string) ([]*validator.Key, error) {
+	mnemonic, err := readMnemonicFile(kong.ExpandPath(mnemonicFile))
+	if err != nil {
+		return nil, err
+	}
+
+	start, end, err := wallet.ParseRange(indexRange)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Deriving validator keys %d to %d", start, end)
+	return validator.NewKeys(mnemonic, "", start, end)
+}
+
+// writeValidatorKeystore encrypts the key into an EIP-2335 keystore and writes it into dir.
+func writeValidatorKeystore(dir string, key *validator.SecretKey, keystorePassword string, kdf keystore.KDF, path string, now time.Time) (string, error) {
+	ks, err := validator.EncryptKeystore(key, keystorePassword, kdf, path, "")
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt keystore of %s: %w", path, err)
+	}
+	return validator.WriteKeystore(dir, ks, now)
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aldoborrero/ethw/internal/utils/output"
+	"github.com/aldoborrero/ethw/internal/validator"
+	"github.com/alecthomas/kong"
+	"github.com/charmbracelet/log"
+)
+
+type validatorDepositCmd struct {
+	MnemonicFile string                  `flag:"" required:"" type:"path" help:"File holding the mnemonic validator keys are derived from"`
+	Range        string                  `flag:"" optional:"" default:"0" help:"Inclusive range of validator indexes, e.g. 0-49"`
+	Amount       uint64                  `flag:"" optional:"" default:"32000000000" help:"Amount of every deposit, in gwei"`
+	Withdrawal   executionAddressOptions `embed:"" prefix:"withdrawal-"`
+	OutputDir    string                  `flag:"" optional:"" type:"path" default:"./validator_keys" help:"Directory to save the deposit data file"`
+	Network      networkOptions          `embed:""`
+}
+
+func (cmd *validatorDepositCmd) Run() error {
+	network, err := cmd.Network.toNetwork()
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	// Without an execution address, 0x00 credentials bound to the withdrawal key are used
+	withdrawalAddress, err := cmd.Withdrawal.address(cmd.MnemonicFile, false)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	keys, err := deriveValidatorKeys(cmd.MnemonicFile, cmd.Range)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	deposits := make([]validator.DepositData, 0, len(keys))
+	for _, key := range keys {
+		credentials := validator.BLSWithdrawalCredentials(key.WithdrawalKey.PublicKey())
+		if withdrawalAddress != nil {
+			credentials = validator.ExecutionWithdrawalCredentials(*withdrawalAddress)
+		}
+
+		log.Infof("Signing deposit of validator %d for %s", key.Index, network.Name)
+		deposit, err := validator.NewDepositData(key.SigningKey, credentials, cmd.Amount, network)
+		if err != nil {
+			log.Error(err.Error())
+			return err
+		}
+		deposits = append(deposits, *deposit)
+	}
+
+	path, err := validator.WriteDepositData(kong.ExpandPath(cmd.OutputDir), deposits, time.Now())
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	var writer output.ValidatorOutputWriter
+	switch Cli.OutputFormat {
+	case "json":
+		writer = output.ValidatorJSONOutputWriter{}
+	case "csv":
+		writer = output.ValidatorCSVOutputWriter{}
+	case "table":
+		writer = output.ValidatorTableOutputWriter{}
+	default:
+		writer = output.ValidatorTextOutputWriter{}
+	}
+
+	if err := writer.WriteDepositOutput(path, deposits); err != nil {
+		return fmt.Errorf("failed to generate output: %w", err)
+	}
+
+	return nil
+}
+
+type validatorDepositVerifyCmd struct {
+	Files []string `arg:"" type:"existingfile" help:"Deposit data files to verify"`
+}
+
+func (cmd *validatorDepositVerifyCmd) Run() error {
+	var results []validator.DepositVerifyResult
+	for _, file := range cmd.Files {
+		deposits, err := validator.ReadDepositData(file)
+		if err != nil {
+			log.Error(err.Error())
+			return err
+		}
+
+		log.Infof("Verifying %d deposits of %s", len(deposits), file)
+		for i, deposit := range deposits {
+			result := validator.VerifyDepositData(i, deposit)
+			result.File = file
+			results = append(results, result)
+		}
+	}
+
+	var writer output.ValidatorOutputWriter
+	switch Cli.OutputFormat {
+	case "json":
+		writer = output.ValidatorJSONOutputWriter{}
+	case "csv":
+		writer = output.ValidatorCSVOutputWriter{}
+	case "table":
+		writer = output.ValidatorTableOutputWriter{}
+	default:
+		writer = output.ValidatorTextOutputWriter{}
+	}
+
+	if err := writer.WriteDepositVerifyOutput(results); err != nil {
+		return fmt.Errorf("failed to generate output: %w", err)
+	}
+
+	failed := 0
+	for _, result := range results {
+		if !result.OK() {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d deposits failed verification", failed, len(results))
+	}
+
+	return nil
+}
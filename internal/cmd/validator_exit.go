@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aldoborrero/ethw/internal/utils/output"
+	"github.com/aldoborrero/ethw/internal/validator"
+	"github.com/alecthomas/kong"
+	"github.com/charmbracelet/log"
+)
+
+type validatorExitCmd struct {
+	MnemonicFile     string          `flag:"" optional:"" type:"path" xor:"source" help:"File holding the mnemonic validator keys are derived from"`
+	Range            string          `flag:"" optional:"" default:"0" help:"Inclusive range of validator key indexes (derivation) used with --mnemonic-file, e.g. 0-49"`
+	Keystores        []string        `flag:"" optional:"" type:"existingfile" name:"keystore" xor:"source" help:"EIP-2335 keystores of the validators, can be repeated"`
+	ValidatorIndexes string          `flag:"" required:"" help:"Beacon chain indexes of the validators, in key order, e.g. 1000-1049 or 1000,1004,1010"`
+	Epoch            uint64          `flag:"" optional:"" default:"0" help:"Earliest epoch the exits can be processed at"`
+	OutputDir        string          `flag:"" optional:"" type:"path" default:"./exit_transactions" help:"Directory to save the signed exits"`
+	Network          networkOptions  `embed:""`
+	Password         passwordOptions `embed:""`
+}
+
+func (cmd *validatorExitCmd) Run() error {
+	network, err := cmd.Network.toNetwork()
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+	if network.GenesisValidatorsRoot == [32]byte{} || (network.Name == validator.CustomNetwork && network.CapellaForkVersion == [4]byte{}) {
+		err := errors.New("voluntary exits require the --genesis-validators-root and --capella-fork-version of the network")
+		log.Error(err.Error())
+		return err
+	}
+
+	keys, err := cmd.signingKeys()
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	validatorIndexes, err := parseIndexList(cmd.ValidatorIndexes)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+	if len(validatorIndexes) != len(keys) {
+		err := fmt.Errorf("got %d validator indexes for %d keys", len(validatorIndexes), len(keys))
+		log.Error(err.Error())
+		return err
+	}
+
+	absOutputDir := kong.ExpandPath(cmd.OutputDir)
+	now := time.Now()
+
+	results := make([]validator.ExitResult, 0, len(keys))
+	for i, key := range keys {
+		log.Infof("Signing voluntary exit of validator %d at epoch %d", validatorIndexes[i], cmd.Epoch)
+		exit := validator.NewSignedVoluntaryExit(key, validatorIndexes[i], cmd.Epoch, network)
+
+		path, err := validator.WriteVoluntaryExit(absOutputDir, exit, now)
+		if err != nil {
+			log.Error(err.Error())
+			return err
+		}
+		results = append(results, validator.ExitResult{Pubkey: hex.EncodeToString(key.PublicKey()), Path: path, Exit: *exit})
+	}
+
+	var writer output.ValidatorOutputWriter
+	switch Cli.OutputFormat {
+	case "json":
+		writer = output.ValidatorJSONOutputWriter{}
+	case "csv":
+		writer = output.ValidatorCSVOutputWriter{}
+	case "table":
+		writer = output.ValidatorTableOutputWriter{}
+	default:
+		writer = output.ValidatorTextOutputWriter{}
+	}
+
+	if err := writer.WriteExitOutput(results); err != nil {
+		return fmt.Errorf("failed to generate output: %w", err)
+	}
+
+	return nil
+}
+
+// signingKeys returns the signing keys of the validators, derived from the mnemonic or decrypted from keystores.
+func (cmd *validatorExitCmd) signingKeys() ([]*validator.SecretKey, error) {
+	if cmd.MnemonicFile != "" {
+		derived, err := deriveValidatorKeys(cmd.MnemonicFile, cmd.Range)
+		if err != nil {
+			return nil, err
+		}
+		keys := make([]*validator.SecretKey, len(derived))
+		for i, key := range derived {
+			keys[i] = key.SigningKey
+		}
+		return keys, nil
+	}
+
+	if len(cmd.Keystores) == 0 {
+		return nil, errors.New("either --mnemonic-file or --keystore is required")
+	}
+
+	passwords, err := resolvePasswordSource(cmd.Password, nil, false, false)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]*validator.SecretKey, len(cmd.Keystores))
+	for i, path := range cmd.Keystores {
+		ks, err := validator.ReadKeystore(path)
+		if err != nil {
+			return nil, err
+		}
+		keystorePassword, err := passwordFor(passwords, i, "0x"+ks.Pubkey)
+		if err != nil {
+			return nil, err
+		}
+		log.Infof("Decrypting keystore %s", path)
+		if keys[i], err = ks.Decrypt(keystorePassword); err != nil {
+			return nil, fmt.Errorf("failed to decrypt keystore %s: %w", path, err)
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/aldoborrero/ethw/internal/utils/output"
+	"github.com/aldoborrero/ethw/internal/validator"
+	"github.com/alecthomas/kong"
+	"github.com/charmbracelet/log"
+)
+
+type validatorExportCmd struct {
+	Client      string          `flag:"" required:"" enum:"lighthouse,prysm,teku,nimbus,lodestar" help:"Consensus client the layout is written for: lighthouse, prysm, teku, nimbus or lodestar"`
+	KeystoreDir string          `flag:"" optional:"" type:"path" default:"./validator_keys" help:"Directory holding the EIP-2335 keystores to export"`
+	OutputDir   string          `flag:"" required:"" type:"path" help:"Directory the client layout is written to, existing files are never overwritten"`
+	Verify      bool            `flag:"" optional:"" default:"true" negatable:"" help:"Decrypt every keystore to check its password before exporting it"`
+	Password    passwordOptions `embed:""`
+}
+
+func (cmd *validatorExportCmd) Run() error {
+	files, err := validator.KeystoreFiles(kong.ExpandPath(cmd.KeystoreDir))
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+	if len(files) == 0 {
+		err := fmt.Errorf("no validator keystores found in %s", cmd.KeystoreDir)
+		log.Error(err.Error())
+		return err
+	}
+
+	passwords, err := resolvePasswordSource(cmd.Password, nil, false, false)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	// Prysm wallets re-encrypt keys, so they're decrypted even with --no-verify
+	decrypt := cmd.Verify || validator.ClientNeedsSecretKey(cmd.Client)
+
+	keys := make([]validator.ExportKey, 0, len(files))
+	for i, file := range files {
+		ks, err := validator.ReadKeystore(file)
+		if err != nil {
+			log.Error(err.Error())
+			return err
+		}
+
+		key := validator.ExportKey{Path: file, Keystore: ks}
+		if key.Password, err = passwordFor(passwords, i, "0x"+ks.Pubkey); err != nil {
+			log.Error(err.Error())
+			return err
+		}
+		if decrypt {
+			log.Infof("Decrypting keystore %s", file)
+			if key.SecretKey, err = ks.Decrypt(key.Password); err != nil {
+				err = fmt.Errorf("failed to decrypt keystore %s: %w", file, err)
+				log.Error(err.Error())
+				return err
+			}
+		}
+		keys = append(keys, key)
+	}
+
+	log.Infof("Exporting %d validators for %s", len(keys), cmd.Client)
+	results, err := validator.ExportLayout(cmd.Client, kong.ExpandPath(cmd.OutputDir), keys)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	var writer output.ValidatorOutputWriter
+	switch Cli.OutputFormat {
+	case "json":
+		writer = output.ValidatorJSONOutputWriter{}
+	case "csv":
+		writer = output.ValidatorCSVOutputWriter{}
+	case "table":
+		writer = output.ValidatorTableOutputWriter{}
+	default:
+		writer = output.ValidatorTextOutputWriter{}
+	}
+
+	if err := writer.WriteExportOutput(cmd.Client, results); err != nil {
+		return fmt.Errorf("failed to generate output: %w", err)
+	}
+
+	return nil
+}
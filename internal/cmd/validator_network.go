@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aldoborrero/ethw/internal/validator"
+	"github.com/aldoborrero/ethw/internal/wallet"
+	"github.com/alecthomas/kong"
+	"github.com/charmbracelet/log"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// networkOptions groups the flags that select the consensus layer network validator messages are signed for.
+type networkOptions struct {
+	Network               string `flag:"" optional:"" enum:"mainnet,holesky,sepolia,custom" default:"mainnet" help:"Network messages are signed for: mainnet, holesky, sepolia or custom"`
+	GenesisForkVersion    string `flag:"" optional:"" help:"Genesis fork version of a custom network, e.g. 0x10000038"`
+	GenesisValidatorsRoot string `flag:"" optional:"" help:"Genesis validators root of a custom network"`
+	CapellaForkVersion    string `flag:"" optional:"" help:"Capella fork version of a custom network, used to sign voluntary exits"`
+}
+
+// toNetwork returns the parameters of the selected network. Parameters of custom networks which aren't given are
+// left zeroed, callers check the ones they need.
+func (o networkOptions) toNetwork() (validator.Network, error) {
+	if o.Network != validator.CustomNetwork {
+		if o.GenesisForkVersion != "" || o.GenesisValidatorsRoot != "" || o.CapellaForkVersion != "" {
+			return validator.Network{}, errors.New("fork parameters can only be given with --network=custom")
+		}
+		return validator.Networks[o.Network], nil
+	}
+
+	if o.GenesisForkVersion == "" {
+		return validator.Network{}, errors.New("--network=custom requires --genesis-fork-version")
+	}
+
+	var err error
+	network := validator.Network{Name: validator.CustomNetwork}
+	if network.GenesisForkVersion, err = validator.ParseForkVersion(o.GenesisForkVersion); err != nil {
+		return validator.Network{}, err
+	}
+	if o.GenesisValidatorsRoot != "" {
+		if network.GenesisValidatorsRoot, err = validator.ParseRoot(o.GenesisValidatorsRoot); err != nil {
+			return validator.Network{}, err
+		}
+	}
+	if o.CapellaForkVersion != "" {
+		if network.CapellaForkVersion, err = validator.ParseForkVersion(o.CapellaForkVersion); err != nil {
+			return validator.Network{}, err
+		}
+	}
+	return network, nil
+}
+
+// executionAddressOptions groups the flags that select an execution layer address, either given or derived from the
+// mnemonic of the validators.
+type executionAddressOptions struct {
+	Address string `flag:"" optional:"" help:"Execution layer address"`
+	Account int    `flag:"" optional:"" default:"-1" help:"Use the account at this index, derived from the same mnemonic, as execution layer address"`
+	Scheme  string `flag:"" optional:"" default:"bip44" help:"Derivation path scheme of the account: bip44, ledger-live, ledger-legacy or a template such as \"m/44'/60'/0'/0/%d\""`
+}
+
+// address returns the selected execution address, or nil when none is selected and required is not set.
+func (o executionAddressOptions) address(mnemonicFile string, required bool) (*common.Address, error) {
+	switch {
+	case o.Address != "" && o.Account >= 0:
+		return nil, errors.New("an execution address can't be both given and derived")
+	case o.Address != "":
+		if !common.IsHexAddress(o.Address) {
+			return nil, fmt.Errorf("invalid execution address: %q", o.Address)
+		}
+		address := common.HexToAddress(o.Address)
+		return &address, nil
+	case o.Account >= 0:
+		mnemonic, err := readMnemonicFile(kong.ExpandPath(mnemonicFile))
+		if err != nil {
+			return nil, err
+		}
+		wallets, err := wallet.NewWallets(mnemonic, o.Scheme, o.Account, o.Account)
+		if err != nil {
+			return nil, err
+		}
+		log.Infof("Using execution address %s (%s)", wallets[0].Address, wallets[0].DerivationPath)
+		address := common.HexToAddress(wallets[0].Address)
+		return &address, nil
+	case required:
+		return nil, errors.New("an execution address is required")
+	default:
+		return nil, nil
+	}
+}
@@ -0,0 +1,245 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aldoborrero/ethw/internal/utils/output"
+	"github.com/aldoborrero/ethw/internal/validator"
+	"github.com/alecthomas/kong"
+	"github.com/charmbracelet/log"
+)
+
+type validatorSlashingProtectionCmd struct {
+	Generate validatorSlashingProtectionGenerateCmd `cmd:"" help:"Generate an empty interchange file for validators which haven't signed anything yet"`
+	Merge    validatorSlashingProtectionMergeCmd    `cmd:"" help:"Merge interchange files, keeping the highest slots and epochs signed by every validator"`
+	Validate validatorSlashingProtectionValidateCmd `cmd:"" help:"Validate interchange files"`
+	Minify   validatorSlashingProtectionMinifyCmd   `cmd:"" help:"Reduce an interchange file to the highest slot and epochs signed by every validator"`
+}
+
+type validatorSlashingProtectionGenerateCmd struct {
+	MnemonicFile string         `flag:"" optional:"" type:"path" help:"File holding the mnemonic validator keys are derived from"`
+	Range        string         `flag:"" optional:"" default:"0" help:"Inclusive range of validator key indexes (derivation) used with --mnemonic-file, e.g. 0-49"`
+	Pubkeys      []string       `flag:"" optional:"" name:"pubkey" help:"Public key of a validator, can be repeated"`
+	OutputFile   string         `flag:"" required:"" type:"path" help:"Interchange file to write, existing files are never overwritten"`
+	Network      networkOptions `embed:""`
+}
+
+func (cmd *validatorSlashingProtectionGenerateCmd) Run() error {
+	network, err := cmd.Network.toNetwork()
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+	if network.GenesisValidatorsRoot == [32]byte{} {
+		err := errors.New("interchange files require the --genesis-validators-root of the network")
+		log.Error(err.Error())
+		return err
+	}
+
+	pubkeys, err := validatorPubkeys(cmd.MnemonicFile, cmd.Range, cmd.Pubkeys)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+	if len(pubkeys) == 0 {
+		err := errors.New("either --mnemonic-file or --pubkey is required")
+		log.Error(err.Error())
+		return err
+	}
+
+	log.Infof("Generating interchange file for %d validators on %s", len(pubkeys), network.Name)
+	ic := validator.NewInterchange(network.GenesisValidatorsRoot, pubkeys)
+	path := kong.ExpandPath(cmd.OutputFile)
+	if err := validator.WriteInterchange(path, ic); err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	var writer output.ValidatorOutputWriter
+	switch Cli.OutputFormat {
+	case "json":
+		writer = output.ValidatorJSONOutputWriter{}
+	case "csv":
+		writer = output.ValidatorCSVOutputWriter{}
+	case "table":
+		writer = output.ValidatorTableOutputWriter{}
+	default:
+		writer = output.ValidatorTextOutputWriter{}
+	}
+
+	if err := writer.WriteSlashingProtectionOutput(path, validator.Watermarks(ic)); err != nil {
+		return fmt.Errorf("failed to generate output: %w", err)
+	}
+
+	return nil
+}
+
+type validatorSlashingProtectionMergeCmd struct {
+	Files      []string `arg:"" type:"existingfile" help:"Interchange files to merge"`
+	OutputFile string   `flag:"" required:"" type:"path" help:"Interchange file to write, existing files are never overwritten"`
+}
+
+func (cmd *validatorSlashingProtectionMergeCmd) Run() error {
+	ics := make([]*validator.Interchange, len(cmd.Files))
+	for i, file := range cmd.Files {
+		ic, err := validator.ReadInterchange(file)
+		if err != nil {
+			log.Error(err.Error())
+			return err
+		}
+		ics[i] = ic
+	}
+
+	log.Infof("Merging %d interchange files", len(ics))
+	merged, err := validator.MergeInterchanges(ics)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+	path := kong.ExpandPath(cmd.OutputFile)
+	if err := validator.WriteInterchange(path, merged); err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	var writer output.ValidatorOutputWriter
+	switch Cli.OutputFormat {
+	case "json":
+		writer = output.ValidatorJSONOutputWriter{}
+	case "csv":
+		writer = output.ValidatorCSVOutputWriter{}
+	case "table":
+		writer = output.ValidatorTableOutputWriter{}
+	default:
+		writer = output.ValidatorTextOutputWriter{}
+	}
+
+	if err := writer.WriteSlashingProtectionOutput(path, validator.Watermarks(merged)); err != nil {
+		return fmt.Errorf("failed to generate output: %w", err)
+	}
+
+	return nil
+}
+
+type validatorSlashingProtectionValidateCmd struct {
+	Files        []string `arg:"" type:"existingfile" help:"Interchange files to validate"`
+	MnemonicFile string   `flag:"" optional:"" type:"path" help:"Also check that the validators derived from this mnemonic are present"`
+	Range        string   `flag:"" optional:"" default:"0" help:"Inclusive range of validator key indexes (derivation) used with --mnemonic-file, e.g. 0-49"`
+	Pubkeys      []string `flag:"" optional:"" name:"pubkey" help:"Also check that the validator with this public key is present, can be repeated"`
+}
+
+func (cmd *validatorSlashingProtectionValidateCmd) Run() error {
+	pubkeys, err := validatorPubkeys(cmd.MnemonicFile, cmd.Range, cmd.Pubkeys)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	results := make([]validator.InterchangeValidateResult, 0, len(cmd.Files))
+	for _, file := range cmd.Files {
+		log.Infof("Validating %s", file)
+		result := validator.InterchangeValidateResult{File: file}
+		ic, err := validator.ReadInterchange(file)
+		if err != nil {
+			result.Problems = []string{err.Error()}
+		} else {
+			result.Validators = len(ic.Data)
+			result.Problems = validator.ValidateInterchange(ic, pubkeys)
+		}
+		results = append(results, result)
+	}
+
+	var writer output.ValidatorOutputWriter
+	switch Cli.OutputFormat {
+	case "json":
+		writer = output.ValidatorJSONOutputWriter{}
+	case "csv":
+		writer = output.ValidatorCSVOutputWriter{}
+	case "table":
+		writer = output.ValidatorTableOutputWriter{}
+	default:
+		writer = output.ValidatorTextOutputWriter{}
+	}
+
+	if err := writer.WriteSlashingProtectionValidateOutput(results); err != nil {
+		return fmt.Errorf("failed to generate output: %w", err)
+	}
+
+	failed := 0
+	for _, result := range results {
+		if !result.OK() {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d interchange files failed validation", failed, len(results))
+	}
+
+	return nil
+}
+
+type validatorSlashingProtectionMinifyCmd struct {
+	File       string `arg:"" type:"existingfile" help:"Interchange file to minify"`
+	OutputFile string `flag:"" required:"" type:"path" help:"Interchange file to write, existing files are never overwritten"`
+}
+
+func (cmd *validatorSlashingProtectionMinifyCmd) Run() error {
+	ic, err := validator.ReadInterchange(cmd.File)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	log.Infof("Minifying %s", cmd.File)
+	minified, err := validator.MinifyInterchange(ic)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+	path := kong.ExpandPath(cmd.OutputFile)
+	if err := validator.WriteInterchange(path, minified); err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	var writer output.ValidatorOutputWriter
+	switch Cli.OutputFormat {
+	case "json":
+		writer = output.ValidatorJSONOutputWriter{}
+	case "csv":
+		writer = output.ValidatorCSVOutputWriter{}
+	case "table":
+		writer = output.ValidatorTableOutputWriter{}
+	default:
+		writer = output.ValidatorTextOutputWriter{}
+	}
+
+	if err := writer.WriteSlashingProtectionOutput(path, validator.Watermarks(minified)); err != nil {
+		return fmt.Errorf("failed to generate output: %w", err)
+	}
+
+	return nil
+}
+
+// validatorPubkeys returns the public keys of the validators derived from the mnemonic, if any, followed by the given
+// ones.
+func validatorPubkeys(mnemonicFile, indexRange string, pubkeys []string) ([]string, error) {
+	var result []string
+	if mnemonicFile != "" {
+		keys, err := deriveValidatorKeys(mnemonicFile, indexRange)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			result = append(result, key.Pubkey())
+		}
+	}
+	for _, pubkey := range pubkeys {
+		if _, err := validator.ParsePubkey(pubkey); err != nil {
+			return nil, err
+		}
+		result = append(result, pubkey)
+	}
+	return result, nil
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/aldoborrero/ethw/internal/chain"
+	"github.com/aldoborrero/ethw/internal/utils/output"
+	"github.com/aldoborrero/ethw/internal/wallet"
+	"github.com/alecthomas/kong"
+	"github.com/charmbracelet/log"
+)
+
+type walletDiscoverCmd struct {
+	MnemonicFile string  `flag:"" optional:"" type:"existingfile" help:"File with the mnemonic the accounts are derived from"`
+	Xpub         string  `flag:"" optional:"" help:"Extended public key the accounts are derived from instead of a mnemonic, e.g. exported from a hardware wallet"`
+	XpubPath     string  `flag:"" optional:"" default:"m/44'/60'/0'/0" help:"Derivation path of the extended public key, the accounts being its children"`
+	Scheme       string  `flag:"" optional:"" default:"bip44" help:"Derivation path scheme used with --mnemonic-file: bip44, ledger-live, ledger-legacy or a template such as \"m/44'/60'/0'/0/%d\""`
+	Start        int     `flag:"" optional:"" default:"0" help:"First account index to check"`
+	Gap          int     `flag:"" optional:"" default:"20" help:"Number of consecutive unused accounts after which the walk stops"`
+	RPC          string  `flag:"" optional:"" name:"rpc" help:"JSON-RPC endpoint of the node checking accounts, used when they have a nonce or a balance"`
+	Addresses    string  `flag:"" optional:"" type:"existingfile" help:"File listing the used addresses, checked offline instead of a node"`
+	StateDump    string  `flag:"" optional:"" type:"existingfile" help:"State dump written by geth dump, checked offline instead of a node"`
+	Concurrency  int     `flag:"" optional:"" default:"8" help:"Number of accounts checked in parallel"`
+	Rate         float64 `flag:"" optional:"" default:"20" help:"Maximum number of requests sent per second, 0 for no limit"`
+}
+
+func (cmd *walletDiscoverCmd) Run() error {
+	deriver, err := cmd.deriver()
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	used, closeUsage, err := cmd.usage(ctx)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+	defer closeUsage()
+
+	log.Infof("Walking accounts from index %d until %d consecutive ones are unused", cmd.Start, cmd.Gap)
+	accounts, err := wallet.Discover(deriver, used, cmd.Start, cmd.Gap, cmd.Concurrency)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	var writer output.WalletOutputWriter
+	switch Cli.OutputFormat {
+	case "json":
+		writer = output.WalletJSONOutputWriter{}
+	case "csv":
+		writer = output.WalletCSVOutputWriter{}
+	case "table":
+		writer = output.WalletTableOutputWriter{}
+	default:
+		writer = output.WalletTextOuputWriter{}
+	}
+
+	if err := writer.WriteDiscoverOutput(accounts); err != nil {
+		return fmt.Errorf("failed to generate output: %w", err)
+	}
+
+	return nil
+}
+
+// deriver returns the deriver of the mnemonic or of the extended public key.
+func (cmd *walletDiscoverCmd) deriver() (wallet.Deriver, error) {
+	switch {
+	case cmd.MnemonicFile != "" && cmd.Xpub != "":
+		return nil, errors.New("accounts are derived either from --mnemonic-file or from --xpub")
+	case cmd.MnemonicFile != "":
+		mnemonic, err := readMnemonicFile(kong.ExpandPath(cmd.MnemonicFile))
+		if err != nil {
+			return nil, err
+		}
+		if _, err := wallet.DerivationPath(cmd.Scheme, 0); err != nil {
+			return nil, err
+		}
+		return wallet.MnemonicDeriver(mnemonic, cmd.Scheme), nil
+	case cmd.Xpub != "":
+		return wallet.XpubDeriver(cmd.Xpub, cmd.XpubPath)
+	default:
+		return nil, errors.New("pass --mnemonic-file or --xpub to derive accounts")
+	}
+}
+
+// usage returns the function checking whether accounts were used, against a node, an address list or a state dump,
+// and the function releasing it.
+func (cmd *walletDiscoverCmd) usage(ctx context.Context) (wallet.UsageFunc, func(), error) {
+	sources := 0
+	for _, source := range []string{cmd.RPC, cmd.Addresses, cmd.StateDump} {
+		if source != "" {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return nil, nil, errors.New("pass one of --rpc, --addresses or --state-dump to check whether accounts were used")
+	}
+
+	switch {
+	case cmd.Addresses != "":
+		addresses, err := chain.ReadAddressList(kong.ExpandPath(cmd.Addresses))
+		if err != nil {
+			return nil, nil, err
+		}
+		return func(account *wallet.Discovered) (bool, error) {
+			return addresses[account.Address], nil
+		}, func() {}, nil
+
+	case cmd.StateDump != "":
+		log.Infof("Reading state dump %s", cmd.StateDump)
+		accounts, err := chain.ReadStateDump(kong.ExpandPath(cmd.StateDump))
+		if err != nil {
+			return nil, nil, err
+		}
+		return func(account *wallet.Discovered) (bool, error) {
+			state, ok := accounts[account.Address]
+			if !ok {
+				return false, nil
+			}
+			account.Balance, account.Nonce = state.Balance, state.Nonce
+			return state.Used(), nil
+		}, func() {}, nil
+
+	default:
+		client, err := chain.Dial(ctx, cmd.RPC, cmd.Rate)
+		if err != nil {
+			return nil, nil, err
+		}
+		return func(account *wallet.Discovered) (bool, error) {
+			status := client.Status(ctx, account.Address, nil)
+			if status.Err != nil {
+				return false, fmt.Errorf("failed to look up %s: %w", account.Address.Hex(), status.Err)
+			}
+			account.Balance, account.Nonce = status.Balance, status.Nonce
+			return status.Used(), nil
+		}, client.Close, nil
+	}
+}
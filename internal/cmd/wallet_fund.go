@@ -0,0 +1,267 @@
+package cmd
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/aldoborrero/ethw/internal/chain"
+	"github.com/alecthomas/kong"
+	"github.com/charmbracelet/log"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+type walletFundCmd struct {
+	RPC             string          `flag:"" required:"" name:"rpc" help:"JSON-RPC endpoint of the node, e.g. http://localhost:8545"`
+	From            string          `flag:"" required:"" help:"Account paying the transfers: a keystore account by address or alias, or a hex private key with --unsafe-inline-key"`
+	FromKeystoreDir string          `flag:"" optional:"" type:"path" default:"./keystore" help:"Keystore directory of the --from account"`
+	UnsafeInlineKey bool            `flag:"" optional:"" help:"Allow a private key as --from (it leaks into shell history and ps)"`
+	Amount          string          `flag:"" required:"" help:"Amount sent to every account, e.g. 0.5, 30gwei, or token units with --token"`
+	Token           string          `flag:"" optional:"" help:"Address of an ERC-20 token contract to send instead of ether"`
+	BatchSize       int             `flag:"" optional:"" default:"20" help:"Number of transactions sent before waiting for their receipts"`
+	Timeout         time.Duration   `flag:"" optional:"" default:"2m" help:"Time to wait for the receipts of a batch"`
+	Journal         string          `flag:"" optional:"" type:"path" default:"./ethw-fund-journal.json" help:"File recording sent transfers, so an interrupted run resumes them; removed once every transfer is confirmed"`
+	DryRun          bool            `flag:"" optional:"" help:"Only print the planned transfers, without sending anything"`
+	Rate            float64         `flag:"" optional:"" default:"20" help:"Maximum number of requests sent per second, 0 for no limit"`
+	Fees            feeOptions      `embed:""`
+	Accounts        accountOptions  `embed:""`
+	Password        passwordOptions `embed:""`
+}
+
+func (cmd *walletFundCmd) Run() error {
+	if cmd.BatchSize < 1 {
+		err := errors.New("--batch-size must be at least 1")
+		log.Error(err.Error())
+		return err
+	}
+
+	accounts, err := cmd.Accounts.accounts()
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+	if len(accounts) == 0 {
+		err := errors.New("no accounts to fund")
+		log.Error(err.Error())
+		return err
+	}
+
+	var token *chain.Token
+	var tokenAddress common.Address
+	if cmd.Token != "" {
+		addresses, err := parseAddresses([]string{cmd.Token})
+		if err != nil {
+			log.Error(err.Error())
+			return err
+		}
+		tokenAddress = addresses[0]
+	}
+
+	key, err := unlockSigner(cmd.From, cmd.FromKeystoreDir, cmd.UnsafeInlineKey, cmd.Password)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+	from := crypto.PubkeyToAddress(key.PublicKey)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	client, err := chain.Dial(ctx, cmd.RPC, cmd.Rate)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+	defer client.Close()
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		err = fmt.Errorf("failed to read chain ID: %w", err)
+		log.Error(err.Error())
+		return err
+	}
+
+	decimals := uint8(chain.EtherDecimals)
+	if cmd.Token != "" {
+		resolved, err := client.Token(ctx, tokenAddress)
+		if err != nil {
+			log.Error(err.Error())
+			return err
+		}
+		token, decimals = &resolved, resolved.Decimals
+	}
+	amount, err := chain.ParseUnits(cmd.Amount, decimals)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+	if amount.Sign() == 0 {
+		err := errors.New("--amount must be greater than 0")
+		log.Error(err.Error())
+		return err
+	}
+
+	journal, err := chain.OpenJournal(kong.ExpandPath(cmd.Journal), chainID.Uint64())
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+	if err := checkFundJournal(journal, from, accounts, token); err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	run := &transferRun{client: client, chainID: chainID, journal: journal, batchSize: cmd.BatchSize, timeout: cmd.Timeout}
+	if !cmd.DryRun {
+		if err := run.resume(ctx); err != nil {
+			run.finish()
+			log.Error(err.Error())
+			return err
+		}
+	}
+
+	transfers, planned, err := planFunding(journal, from, accounts, token, amount)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	if len(planned) > 0 {
+		if token != nil {
+			if err := checkTokenFunds(ctx, client, from, token, planned); err != nil {
+				log.Error(err.Error())
+				return err
+			}
+		}
+		if run.fees, err = cmd.Fees.fees(ctx, client); err != nil {
+			log.Error(err.Error())
+			return err
+		}
+		for _, t := range planned {
+			if err := client.EstimateTransfer(ctx, t.Transfer); err != nil {
+				log.Error(err.Error())
+				return err
+			}
+			t.MaxFee = run.fees.Max()
+		}
+		if err := checkFunds(ctx, client, from, planned); err != nil {
+			if !cmd.DryRun {
+				log.Error(err.Error())
+				return err
+			}
+			log.Warn(err.Error())
+		}
+	}
+
+	if cmd.DryRun {
+		return writeTransfers(transfers, true)
+	}
+
+	log.Infof("Funding %d accounts from %s", len(planned), from.Hex())
+	sendErr := run.send(ctx, toChainTransfers(planned), map[common.Address]*ecdsa.PrivateKey{from: key})
+	run.finish()
+
+	if err := writeTransfers(transfers, false); err != nil {
+		return err
+	}
+	if sendErr != nil {
+		log.Error(sendErr.Error())
+		return sendErr
+	}
+	if err := failedTransfers(transfers); err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// checkFundJournal checks the journal only records transfers of the asset from the account to the accounts funded.
+func checkFundJournal(journal *chain.Journal, from common.Address, accounts []selectedAccount, token *chain.Token) error {
+	recipients := map[common.Address]bool{}
+	for _, account := range accounts {
+		recipients[account.Address] = true
+	}
+	for _, t := range journal.Transfers {
+		if t.From != from || !recipients[t.To] || journal.Find(from, t.To, token) != t {
+			return fmt.Errorf("journal %s records transfers of another run, finish that run or pass another --journal", journal.Path())
+		}
+	}
+	return nil
+}
+
+// planFunding returns the transfer funding every account, reusing the transfers recorded in the journal, and the
+// transfers left to send.
+func planFunding(journal *chain.Journal, from common.Address, accounts []selectedAccount, token *chain.Token, amount *big.Int) ([]chain.WalletTransfer, []chain.WalletTransfer, error) {
+	var transfers, planned []chain.WalletTransfer
+	for _, account := range accounts {
+		t := journal.Find(from, account.Address, token)
+		if t != nil && t.Status == chain.TransferFailed {
+			// Failed transfers are sent again
+			journal.Drop(t)
+			t = nil
+		}
+		if t != nil && t.Amount.Cmp(amount) != 0 {
+			return nil, nil, fmt.Errorf("journal %s records a transfer of another amount to %s, finish that run or pass another --journal", journal.Path(), account.Address.Hex())
+		}
+
+		transfer := chain.WalletTransfer{Alias: account.Alias, DerivationPath: account.DerivationPath, Transfer: t}
+		if t == nil {
+			transfer.Transfer = &chain.Transfer{From: from, To: account.Address, Token: token, Amount: new(big.Int).Set(amount), Status: chain.TransferPlanned}
+			planned = append(planned, transfer)
+		}
+		transfers = append(transfers, transfer)
+	}
+	return transfers, planned, nil
+}
+
+// checkTokenFunds checks the account holds the tokens sent by the planned transfers, before their gas is estimated.
+func checkTokenFunds(ctx context.Context, client *chain.Client, from common.Address, token *chain.Token, planned []chain.WalletTransfer) error {
+	total := new(big.Int)
+	for _, t := range planned {
+		total.Add(total, t.Amount)
+	}
+	balance, err := client.TokenBalance(ctx, token.Address, from)
+	if err != nil {
+		return fmt.Errorf("failed to read %s balance of %s: %w", token.Symbol, from.Hex(), err)
+	}
+	if balance.Cmp(total) < 0 {
+		return fmt.Errorf("%s holds %s %s, the transfers need %s %s", from.Hex(), chain.FormatUnits(balance, token.Decimals), token.Symbol, chain.FormatUnits(total, token.Decimals), token.Symbol)
+	}
+	return nil
+}
+
+// checkFunds checks the account holds the ether sent by the planned transfers and their maximum fees.
+func checkFunds(ctx context.Context, client *chain.Client, from common.Address, planned []chain.WalletTransfer) error {
+	total := new(big.Int)
+	for _, t := range planned {
+		total.Add(total, t.MaxCost())
+		if t.Token == nil {
+			total.Add(total, t.Amount)
+		}
+	}
+	balance, err := client.Balance(ctx, from)
+	if err != nil {
+		return fmt.Errorf("failed to read balance of %s: %w", from.Hex(), err)
+	}
+	if balance.Cmp(total) < 0 {
+		return fmt.Errorf("%s holds %s ETH, the transfers need up to %s ETH", from.Hex(), chain.FormatUnits(balance, chain.EtherDecimals), chain.FormatUnits(total, chain.EtherDecimals))
+	}
+	return nil
+}
+
+// toChainTransfers returns the chain transfers of wallet transfers.
+func toChainTransfers(transfers []chain.WalletTransfer) []*chain.Transfer {
+	result := make([]*chain.Transfer, len(transfers))
+	for i, t := range transfers {
+		result[i] = t.Transfer
+	}
+	return result
+}
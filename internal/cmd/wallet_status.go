@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/aldoborrero/ethw/internal/chain"
+	"github.com/aldoborrero/ethw/internal/utils/output"
+	"github.com/aldoborrero/ethw/internal/utils/pool"
+	"github.com/charmbracelet/log"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type walletStatusCmd struct {
+	RPC         string         `flag:"" required:"" name:"rpc" help:"JSON-RPC endpoint of the node, e.g. http://localhost:8545"`
+	Token       []string       `flag:"" optional:"" help:"Address of an ERC-20 token contract to look up balances in, repeatable"`
+	Concurrency int            `flag:"" optional:"" default:"8" help:"Number of accounts looked up in parallel"`
+	Rate        float64        `flag:"" optional:"" default:"20" help:"Maximum number of requests sent per second, 0 for no limit"`
+	Accounts    accountOptions `embed:""`
+}
+
+func (cmd *walletStatusCmd) Run() error {
+	accounts, err := cmd.Accounts.accounts()
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+	if len(accounts) == 0 {
+		err := errors.New("no accounts to look up")
+		log.Error(err.Error())
+		return err
+	}
+
+	tokenAddresses, err := parseAddresses(cmd.Token)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	client, err := chain.Dial(ctx, cmd.RPC, cmd.Rate)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+	defer client.Close()
+
+	tokens, err := resolveTokens(ctx, client, tokenAddresses)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	log.Infof("Looking up %d accounts on %s", len(accounts), cmd.RPC)
+	statuses := make([]chain.WalletStatus, len(accounts))
+	pool.Run(len(accounts), cmd.Concurrency, func(i int) {
+		statuses[i] = chain.WalletStatus{
+			Alias:          accounts[i].Alias,
+			DerivationPath: accounts[i].DerivationPath,
+			AccountStatus:  client.Status(ctx, accounts[i].Address, tokens),
+		}
+	})
+	if err := ctx.Err(); err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	var writer output.WalletOutputWriter
+	switch Cli.OutputFormat {
+	case "json":
+		writer = output.WalletJSONOutputWriter{}
+	case "csv":
+		writer = output.WalletCSVOutputWriter{}
+	case "table":
+		writer = output.WalletTableOutputWriter{}
+	default:
+		writer = output.WalletTextOuputWriter{}
+	}
+
+	if err := writer.WriteStatusOutput(statuses, tokens); err != nil {
+		return fmt.Errorf("failed to generate output: %w", err)
+	}
+
+	failed := 0
+	for _, status := range statuses {
+		if status.Err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		err := fmt.Errorf("failed to look up %d of %d accounts", failed, len(statuses))
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// resolveTokens reads the symbol and decimals of the ERC-20 token contracts.
+func resolveTokens(ctx context.Context, client *chain.Client, addresses []common.Address) ([]chain.Token, error) {
+	tokens := make([]chain.Token, len(addresses))
+	for i, address := range addresses {
+		token, err := client.Token(ctx, address)
+		if err != nil {
+			return nil, err
+		}
+		tokens[i] = token
+	}
+	return tokens, nil
+}
@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/aldoborrero/ethw/internal/chain"
+	"github.com/alecthomas/kong"
+	"github.com/charmbracelet/log"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type walletSweepCmd struct {
+	RPC       string          `flag:"" required:"" name:"rpc" help:"JSON-RPC endpoint of the node, e.g. http://localhost:8545"`
+	To        string          `flag:"" required:"" help:"Address receiving the balances of the accounts"`
+	Token     []string        `flag:"" optional:"" help:"Address of an ERC-20 token contract to sweep before ether, repeatable"`
+	BatchSize int             `flag:"" optional:"" default:"20" help:"Number of transactions sent before waiting for their receipts"`
+	Timeout   time.Duration   `flag:"" optional:"" default:"2m" help:"Time to wait for the receipts of a batch"`
+	Journal   string          `flag:"" optional:"" type:"path" default:"./ethw-sweep-journal.json" help:"File recording sent transfers, so an interrupted run resumes them; removed once every transfer is confirmed"`
+	DryRun    bool            `flag:"" optional:"" help:"Only print the planned transfers, without unlocking accounts nor sending anything"`
+	Rate      float64         `flag:"" optional:"" default:"20" help:"Maximum number of requests sent per second, 0 for no limit"`
+	Fees      feeOptions      `embed:""`
+	Accounts  accountOptions  `embed:""`
+	Password  passwordOptions `embed:""`
+}
+
+func (cmd *walletSweepCmd) Run() error {
+	if cmd.BatchSize < 1 {
+		err := errors.New("--batch-size must be at least 1")
+		log.Error(err.Error())
+		return err
+	}
+
+	addresses, err := parseAddresses(append([]string{cmd.To}, cmd.Token...))
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+	to, tokenAddresses := addresses[0], addresses[1:]
+
+	selected, err := cmd.Accounts.accounts()
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+	var accounts []selectedAccount
+	for _, account := range selected {
+		if account.Address == to {
+			log.Infof("Skipping %s, which receives the balances", to.Hex())
+			continue
+		}
+		accounts = append(accounts, account)
+	}
+	if len(accounts) == 0 {
+		err := errors.New("no accounts to sweep")
+		log.Error(err.Error())
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	client, err := chain.Dial(ctx, cmd.RPC, cmd.Rate)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+	defer client.Close()
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		err = fmt.Errorf("failed to read chain ID: %w", err)
+		log.Error(err.Error())
+		return err
+	}
+
+	tokens, err := resolveTokens(ctx, client, tokenAddresses)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	journal, err := chain.OpenJournal(kong.ExpandPath(cmd.Journal), chainID.Uint64())
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+	if err := checkSweepJournal(journal, accounts, to); err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	run := &transferRun{client: client, chainID: chainID, journal: journal, batchSize: cmd.BatchSize, timeout: cmd.Timeout}
+	if !cmd.DryRun {
+		if err := run.resume(ctx); err != nil {
+			run.finish()
+			log.Error(err.Error())
+			return err
+		}
+	}
+
+	if run.fees, err = cmd.Fees.fees(ctx, client); err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	log.Infof("Planning the sweep of %d accounts to %s", len(accounts), to.Hex())
+	var transfers, planned []chain.WalletTransfer
+	for _, account := range accounts {
+		accountTransfers, err := planSweep(ctx, client, journal, run.fees, account, to, tokens)
+		if err != nil {
+			log.Error(err.Error())
+			return err
+		}
+		for _, t := range accountTransfers {
+			if t.Status == chain.TransferPlanned {
+				planned = append(planned, t)
+			}
+		}
+		transfers = append(transfers, accountTransfers...)
+	}
+
+	if cmd.DryRun {
+		return writeTransfers(transfers, true)
+	}
+
+	var sendErr error
+	if len(planned) > 0 {
+		keys, err := cmd.senderKeys(accounts, planned)
+		if err != nil {
+			log.Error(err.Error())
+			return err
+		}
+		log.Infof("Sending %d transfers to %s", len(planned), to.Hex())
+		sendErr = run.send(ctx, toChainTransfers(planned), keys)
+	}
+	run.finish()
+
+	if err := writeTransfers(transfers, false); err != nil {
+		return err
+	}
+	if sendErr != nil {
+		log.Error(sendErr.Error())
+		return sendErr
+	}
+	if err := failedTransfers(transfers); err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// senderKeys unlocks the accounts with planned transfers.
+func (cmd *walletSweepCmd) senderKeys(accounts []selectedAccount, planned []chain.WalletTransfer) (map[common.Address]*ecdsa.PrivateKey, error) {
+	sending := map[common.Address]bool{}
+	for _, t := range planned {
+		sending[t.From] = true
+	}
+	var senders []selectedAccount
+	for _, account := range accounts {
+		if sending[account.Address] {
+			senders = append(senders, account)
+		}
+	}
+
+	unlocked, err := cmd.Accounts.unlock(senders, cmd.Password)
+	if err != nil {
+		return nil, err
+	}
+	keys := make(map[common.Address]*ecdsa.PrivateKey, len(senders))
+	for i, account := range senders {
+		keys[account.Address] = unlocked[i]
+	}
+	return keys, nil
+}
+
+// checkSweepJournal checks the journal only records transfers from the swept accounts to the target.
+func checkSweepJournal(journal *chain.Journal, accounts []selectedAccount, to common.Address) error {
+	senders := map[common.Address]bool{}
+	for _, account := range accounts {
+		senders[account.Address] = true
+	}
+	for _, t := range journal.Transfers {
+		if t.To != to || !senders[t.From] {
+			return fmt.Errorf("journal %s records transfers of another run, finish that run or pass another --journal", journal.Path())
+		}
+	}
+	return nil
+}
+
+// planSweep plans the sweep of the account to the target, see chain.Client.PlanSweep.
+func planSweep(ctx context.Context, client *chain.Client, journal *chain.Journal, fees chain.Fees, account selectedAccount, to common.Address, tokens []chain.Token) ([]chain.WalletTransfer, error) {
+	planned, err := client.PlanSweep(ctx, journal, fees, account.Address, to, tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	transfers := make([]chain.WalletTransfer, len(planned))
+	sweepsEther := false
+	for i, t := range planned {
+		transfers[i] = chain.WalletTransfer{Alias: account.Alias, DerivationPath: account.DerivationPath, Transfer: t}
+		sweepsEther = sweepsEther || t.Token == nil
+	}
+	if !sweepsEther {
+		log.Infof("Skipping the ether of %s, its balance doesn't cover the gas", account.Address.Hex())
+	}
+	return transfers, nil
+}
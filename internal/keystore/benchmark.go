@@ -0,0 +1,106 @@
+package keystore
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// minBenchmarkScryptN and maxBenchmarkScryptN bound the scrypt cost search (128KB to 1GB of memory with r=8).
+	minBenchmarkScryptN = 1 << 7
+	maxBenchmarkScryptN = 1 << 20
+
+	// minBenchmarkPBKDF2Iterations is the starting point of the PBKDF2 iteration search.
+	minBenchmarkPBKDF2Iterations = 1 << 12
+)
+
+// BenchmarkResult holds the time it took to derive a key with a given KDF on the current machine.
+type BenchmarkResult struct {
+	KDF      KDF           `json:"kdf"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Benchmark measures how long it takes to derive a single key with the given KDF.
+func Benchmark(kdf KDF) (BenchmarkResult, error) {
+	if err := kdf.Validate(); err != nil {
+		return BenchmarkResult{}, err
+	}
+
+	salt := make([]byte, kdfSaltLen)
+	start := time.Now()
+	if _, err := kdf.DeriveKey([]byte("ethw-benchmark"), salt); err != nil {
+		return BenchmarkResult{}, fmt.Errorf("failed to derive key with %s: %w", kdf, err)
+	}
+
+	return BenchmarkResult{KDF: kdf, Duration: time.Since(start)}, nil
+}
+
+// Calibrate searches for the KDF parameters whose unlock time on the current machine is closest to,
+// without exceeding, the target duration. It returns every measurement taken and the recommended KDF.
+func Calibrate(name string, target time.Duration) ([]BenchmarkResult, KDF, error) {
+	if target <= 0 {
+		return nil, KDF{}, fmt.Errorf("target duration must be positive, got %s", target)
+	}
+
+	switch name {
+	case KDFScrypt:
+		return calibrateScrypt(target)
+	case KDFPBKDF2:
+		return calibratePBKDF2(target)
+	default:
+		return nil, KDF{}, fmt.Errorf("unsupported KDF: %q", name)
+	}
+}
+
+// calibrateScrypt doubles N (keeping r=8 and p=1) until the derivation time goes over the target.
+func calibrateScrypt(target time.Duration) ([]BenchmarkResult, KDF, error) {
+	var results []BenchmarkResult
+	best := NewScryptKDF(minBenchmarkScryptN, DefaultScryptR, 1)
+
+	for n := minBenchmarkScryptN; n <= maxBenchmarkScryptN; n <<= 1 {
+		result, err := Benchmark(NewScryptKDF(n, DefaultScryptR, 1))
+		if err != nil {
+			return nil, KDF{}, err
+		}
+		results = append(results, result)
+
+		if result.Duration > target {
+			break
+		}
+		best = result.KDF
+	}
+
+	return results, best, nil
+}
+
+// calibratePBKDF2 doubles the iteration count until the target is reached and then scales it linearly,
+// as PBKDF2 cost grows proportionally to the number of iterations.
+func calibratePBKDF2(target time.Duration) ([]BenchmarkResult, KDF, error) {
+	var results []BenchmarkResult
+
+	for c := minBenchmarkPBKDF2Iterations; ; c <<= 1 {
+		result, err := Benchmark(NewPBKDF2KDF(c))
+		if err != nil {
+			return nil, KDF{}, err
+		}
+		results = append(results, result)
+
+		// Keep doubling until the measurement is long enough to extrapolate from reliably.
+		if result.Duration < target/4 && result.Duration < time.Second {
+			continue
+		}
+
+		iterations := int(float64(c) * float64(target) / float64(result.Duration))
+		if iterations < 1 {
+			iterations = 1
+		}
+
+		verified, err := Benchmark(NewPBKDF2KDF(iterations))
+		if err != nil {
+			return nil, KDF{}, err
+		}
+		results = append(results, verified)
+
+		return results, verified.KDF, nil
+	}
+}
@@ -0,0 +1,220 @@
+package keystore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	k "github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// KDFScrypt is the name of the scrypt key derivation function.
+	KDFScrypt = "scrypt"
+
+	// KDFPBKDF2 is the name of the PBKDF2 (HMAC-SHA256) key derivation function.
+	KDFPBKDF2 = "pbkdf2"
+
+	// DefaultScryptR is the block size parameter used by geth for every scrypt key file.
+	DefaultScryptR = 8
+
+	// DefaultPBKDF2Iterations is the iteration count used when PBKDF2 is selected without an explicit value.
+	DefaultPBKDF2Iterations = 262144
+
+	kdfDKLen    = 32
+	kdfSaltLen  = 32
+	pbkdf2PRF   = "hmac-sha256"
+	cipherName  = "aes-128-ctr"
+	keyVersion3 = 3
+)
+
+var (
+	// StandardScrypt mirrors geth's standard parameters (256MB of memory and ~1s of CPU time).
+	StandardScrypt = KDF{Name: KDFScrypt, N: k.StandardScryptN, R: DefaultScryptR, P: k.StandardScryptP}
+
+	// LightScrypt mirrors geth's light parameters (4MB of memory and ~100ms of CPU time).
+	LightScrypt = KDF{Name: KDFScrypt, N: k.LightScryptN, R: DefaultScryptR, P: k.LightScryptP}
+)
+
+// KDF describes the key derivation function, and its parameters, used to encrypt key files.
+type KDF struct {
+	Name string `json:"name"`
+	N    int    `json:"n,omitempty"`
+	R    int    `json:"r,omitempty"`
+	P    int    `json:"p,omitempty"`
+	C    int    `json:"c,omitempty"`
+}
+
+// NewScryptKDF returns a scrypt KDF with the given cost (N), block size (r) and parallelization (p) parameters.
+func NewScryptKDF(n, r, p int) KDF {
+	return KDF{Name: KDFScrypt, N: n, R: r, P: p}
+}
+
+// NewPBKDF2KDF returns a PBKDF2 KDF with the given number of iterations.
+func NewPBKDF2KDF(iterations int) KDF {
+	return KDF{Name: KDFPBKDF2, C: iterations}
+}
+
+// Validate checks the KDF parameters are acceptable for the selected function.
+func (kdf KDF) Validate() error {
+	switch kdf.Name {
+	case KDFScrypt:
+		if kdf.N <= 1 || kdf.N&(kdf.N-1) != 0 {
+			return fmt.Errorf("scrypt N must be a power of two greater than 1, got %d", kdf.N)
+		}
+		if kdf.R <= 0 || kdf.P <= 0 {
+			return fmt.Errorf("scrypt r and p must be positive, got r=%d p=%d", kdf.R, kdf.P)
+		}
+		if uint64(kdf.R)*uint64(kdf.P) >= 1<<30 {
+			return fmt.Errorf("scrypt parameters r=%d p=%d are too large", kdf.R, kdf.P)
+		}
+	case KDFPBKDF2:
+		if kdf.C <= 0 {
+			return fmt.Errorf("pbkdf2 iterations must be positive, got %d", kdf.C)
+		}
+	default:
+		return fmt.Errorf("unsupported KDF: %q", kdf.Name)
+	}
+	return nil
+}
+
+// String returns a human readable description of the KDF and its parameters.
+func (kdf KDF) String() string {
+	switch kdf.Name {
+	case KDFPBKDF2:
+		return fmt.Sprintf("pbkdf2(c=%d)", kdf.C)
+	case KDFScrypt:
+		return fmt.Sprintf("scrypt(n=%d,r=%d,p=%d)", kdf.N, kdf.R, kdf.P)
+	default:
+		return "unknown"
+	}
+}
+
+// Memory returns the approximate number of bytes a single key derivation allocates, about 256MB for geth's standard
+// scrypt parameters. PBKDF2 needs a negligible amount of memory.
+func (kdf KDF) Memory() uint64 {
+	if kdf.Name != KDFScrypt {
+		return 0
+	}
+	return 128 * uint64(kdf.R) * (uint64(kdf.N) + uint64(kdf.P))
+}
+
+// gethNative reports whether geth's keystore is able to encrypt keys with these parameters on its own.
+func (kdf KDF) gethNative() bool {
+	return kdf.Name == KDFScrypt && kdf.R == DefaultScryptR
+}
+
+// DeriveKey derives the encryption key for the given password and salt.
+func (kdf KDF) DeriveKey(password, salt []byte) ([]byte, error) {
+	switch kdf.Name {
+	case KDFScrypt:
+		return scrypt.Key(password, salt, kdf.N, kdf.R, kdf.P, kdfDKLen)
+	case KDFPBKDF2:
+		return pbkdf2.Key(password, salt, kdf.C, kdfDKLen, sha256.New), nil
+	default:
+		return nil, fmt.Errorf("unsupported KDF: %q", kdf.Name)
+	}
+}
+
+// Params returns the "kdfparams" section of a key file for the given salt.
+func (kdf KDF) Params(salt []byte) map[string]interface{} {
+	params := map[string]interface{}{
+		"dklen": kdfDKLen,
+		"salt":  hex.EncodeToString(salt),
+	}
+	if kdf.Name == KDFPBKDF2 {
+		params["c"] = kdf.C
+		params["prf"] = pbkdf2PRF
+	} else {
+		params["n"] = kdf.N
+		params["r"] = kdf.R
+		params["p"] = kdf.P
+	}
+	return params
+}
+
+type cipherParamsJSON struct {
+	IV string `json:"iv"`
+}
+
+type cryptoJSON struct {
+	Cipher       string                 `json:"cipher"`
+	CipherText   string                 `json:"ciphertext"`
+	CipherParams cipherParamsJSON       `json:"cipherparams"`
+	KDF          string                 `json:"kdf"`
+	KDFParams    map[string]interface{} `json:"kdfparams"`
+	MAC          string                 `json:"mac"`
+}
+
+type encryptedKeyJSONV3 struct {
+	Address string     `json:"address"`
+	Crypto  cryptoJSON `json:"crypto"`
+	ID      string     `json:"id"`
+	Version int        `json:"version"`
+}
+
+// EncryptKey encrypts a private key into a version 3 key file using the given KDF.
+// The resulting JSON can be decrypted by geth regardless of the KDF parameters used.
+func EncryptKey(key *ecdsa.PrivateKey, password string, kdf KDF) ([]byte, error) {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key id: %w", err)
+	}
+	return encryptKey(key, id, password, kdf)
+}
+
+// encryptKey encrypts a private key into a version 3 key file keeping the given key id.
+func encryptKey(key *ecdsa.PrivateKey, id uuid.UUID, password string, kdf KDF) ([]byte, error) {
+	if err := kdf.Validate(); err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, kdfSaltLen)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	derivedKey, err := kdf.DeriveKey([]byte(password), salt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+
+	iv := make([]byte, aes.BlockSize)
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return nil, fmt.Errorf("failed to generate iv: %w", err)
+	}
+
+	block, err := aes.NewCipher(derivedKey[:16])
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize cipher: %w", err)
+	}
+	keyBytes := math.PaddedBigBytes(key.D, 32)
+	cipherText := make([]byte, len(keyBytes))
+	cipher.NewCTR(block, iv).XORKeyStream(cipherText, keyBytes)
+
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	return json.Marshal(encryptedKeyJSONV3{
+		Address: hex.EncodeToString(address[:]),
+		Crypto: cryptoJSON{
+			Cipher:       cipherName,
+			CipherText:   hex.EncodeToString(cipherText),
+			CipherParams: cipherParamsJSON{IV: hex.EncodeToString(iv)},
+			KDF:          kdf.Name,
+			KDFParams:    kdf.Params(salt),
+			MAC:          hex.EncodeToString(crypto.Keccak256(derivedKey[16:32], cipherText)),
+		},
+		ID:      id.String(),
+		Version: keyVersion3,
+	})
+}
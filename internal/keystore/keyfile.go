@@ -0,0 +1,237 @@
+package keystore
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	// ErrInvalidMAC is returned when the MAC of a key file doesn't match, either because the password is wrong
+	// or because the ciphertext is corrupt.
+	ErrInvalidMAC = errors.New("could not decrypt key with given password (MAC mismatch)")
+)
+
+// KeyFile is the parsed, still encrypted, content of a version 3 key file.
+type KeyFile struct {
+	Path    string
+	Address common.Address
+	ID      string
+	Version int
+	Cipher  string
+	KDF     KDF
+
+	salt       []byte
+	iv         []byte
+	cipherText []byte
+	mac        []byte
+}
+
+// ReadKeyFile reads and parses the key file at the given path.
+func ReadKeyFile(path string) (*KeyFile, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+
+	keyFile, err := ParseKeyFile(content)
+	if err != nil {
+		return nil, err
+	}
+	keyFile.Path = path
+
+	return keyFile, nil
+}
+
+// ParseKeyFile parses the content of a version 3 key file, checking every field is present and well formed.
+func ParseKeyFile(content []byte) (*KeyFile, error) {
+	var raw encryptedKeyJSONV3
+	if err := json.Unmarshal(content, &raw); err != nil {
+		return nil, fmt.Errorf("malformed key file: %w", err)
+	}
+
+	if raw.Version != keyVersion3 {
+		return nil, fmt.Errorf("unsupported key file version: %d", raw.Version)
+	}
+	if !common.IsHexAddress(raw.Address) {
+		return nil, fmt.Errorf("invalid address: %q", raw.Address)
+	}
+	if raw.Crypto.Cipher != cipherName {
+		return nil, fmt.Errorf("unsupported cipher: %q", raw.Crypto.Cipher)
+	}
+
+	kdf, salt, err := ParseKDFParams(raw.Crypto.KDF, raw.Crypto.KDFParams)
+	if err != nil {
+		return nil, err
+	}
+
+	iv, err := decodeHexField("cipherparams.iv", raw.Crypto.CipherParams.IV, aes.BlockSize)
+	if err != nil {
+		return nil, err
+	}
+	cipherText, err := decodeHexField("ciphertext", raw.Crypto.CipherText, 32)
+	if err != nil {
+		return nil, err
+	}
+	mac, err := decodeHexField("mac", raw.Crypto.MAC, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	return &KeyFile{
+		Address:    common.HexToAddress(raw.Address),
+		ID:         raw.ID,
+		Version:    raw.Version,
+		Cipher:     raw.Crypto.Cipher,
+		KDF:        kdf,
+		salt:       salt,
+		iv:         iv,
+		cipherText: cipherText,
+		mac:        mac,
+	}, nil
+}
+
+// Compare reports whether two key files hold the same encrypted key with the same parameters. Both files can still
+// hold the same private key when they differ, encrypted with another password or salt, which can't be told without
+// decrypting them. The reason of the difference is returned otherwise.
+func (kf *KeyFile) Compare(other *KeyFile) (bool, string) {
+	switch {
+	case kf.Address != other.Address:
+		return false, "different address"
+	case !bytes.Equal(kf.cipherText, other.cipherText):
+		return false, "different ciphertext"
+	case kf.KDF != other.KDF || !bytes.Equal(kf.salt, other.salt):
+		return false, "different KDF parameters"
+	case kf.Cipher != other.Cipher || !bytes.Equal(kf.iv, other.iv):
+		return false, "different cipher parameters"
+	case !bytes.Equal(kf.mac, other.mac):
+		return false, "different MAC"
+	default:
+		return true, ""
+	}
+}
+
+// DeriveKey derives the 32 bytes encryption key from the password with the KDF parameters of the file.
+func (kf *KeyFile) DeriveKey(password string) ([]byte, error) {
+	return kf.KDF.DeriveKey([]byte(password), kf.salt)
+}
+
+// VerifyMAC checks the MAC of the file against the given derived key.
+func (kf *KeyFile) VerifyMAC(derivedKey []byte) error {
+	if !bytes.Equal(crypto.Keccak256(derivedKey[16:32], kf.cipherText), kf.mac) {
+		return ErrInvalidMAC
+	}
+	return nil
+}
+
+// DecryptWithKey decrypts the private key with an already derived (and MAC verified) key.
+func (kf *KeyFile) DecryptWithKey(derivedKey []byte) (*ecdsa.PrivateKey, error) {
+	block, err := aes.NewCipher(derivedKey[:16])
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize cipher: %w", err)
+	}
+
+	plainText := make([]byte, len(kf.cipherText))
+	cipher.NewCTR(block, kf.iv).XORKeyStream(plainText, kf.cipherText)
+
+	key, err := crypto.ToECDSA(plainText)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+	return key, nil
+}
+
+// Decrypt derives the key from the password, verifies the MAC and decrypts the private key.
+func (kf *KeyFile) Decrypt(password string) (*ecdsa.PrivateKey, error) {
+	derivedKey, err := kf.DeriveKey(password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	if err := kf.VerifyMAC(derivedKey); err != nil {
+		return nil, err
+	}
+	return kf.DecryptWithKey(derivedKey)
+}
+
+// ParseKDFParams maps the "kdf" and "kdfparams" sections of a key file into a KDF and its salt.
+func ParseKDFParams(name string, params map[string]interface{}) (KDF, []byte, error) {
+	intParam := func(key string) (int, error) {
+		value, ok := params[key].(float64)
+		if !ok {
+			return 0, fmt.Errorf("missing or invalid kdfparams.%s", key)
+		}
+		return int(value), nil
+	}
+
+	dkLen, err := intParam("dklen")
+	if err != nil {
+		return KDF{}, nil, err
+	}
+	if dkLen != kdfDKLen {
+		return KDF{}, nil, fmt.Errorf("unsupported kdfparams.dklen: %d", dkLen)
+	}
+
+	rawSalt, ok := params["salt"].(string)
+	if !ok {
+		return KDF{}, nil, errors.New("missing or invalid kdfparams.salt")
+	}
+	salt, err := hex.DecodeString(rawSalt)
+	if err != nil {
+		return KDF{}, nil, fmt.Errorf("invalid kdfparams.salt: %w", err)
+	}
+
+	var kdf KDF
+	switch name {
+	case KDFScrypt:
+		n, err := intParam("n")
+		if err != nil {
+			return KDF{}, nil, err
+		}
+		r, err := intParam("r")
+		if err != nil {
+			return KDF{}, nil, err
+		}
+		p, err := intParam("p")
+		if err != nil {
+			return KDF{}, nil, err
+		}
+		kdf = NewScryptKDF(n, r, p)
+	case KDFPBKDF2:
+		if prf, _ := params["prf"].(string); prf != pbkdf2PRF {
+			return KDF{}, nil, fmt.Errorf("unsupported PBKDF2 PRF: %q", prf)
+		}
+		c, err := intParam("c")
+		if err != nil {
+			return KDF{}, nil, err
+		}
+		kdf = NewPBKDF2KDF(c)
+	default:
+		return KDF{}, nil, fmt.Errorf("unsupported KDF: %q", name)
+	}
+
+	if err := kdf.Validate(); err != nil {
+		return KDF{}, nil, err
+	}
+	return kdf, salt, nil
+}
+
+// decodeHexField decodes a hex encoded field of the key file, checking its length.
+func decodeHexField(name, value string, length int) ([]byte, error) {
+	decoded, err := hex.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	if len(decoded) != length {
+		return nil, fmt.Errorf("invalid %s: expected %d bytes, got %d", name, length, len(decoded))
+	}
+	return decoded, nil
+}
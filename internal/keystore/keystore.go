@@ -1,10 +1,12 @@
 package keystore
 
 import (
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
+	"time"
 
 	"github.com/ethereum/go-ethereum/accounts"
 	k "github.com/ethereum/go-ethereum/accounts/keystore"
@@ -12,75 +14,226 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 )
 
+var (
+	// ErrAccountExists is returned when importing a key whose address is already present in the keystore.
+	ErrAccountExists = errors.New("address already exists")
+)
+
 // keystore encapsulates a keystore directory and the underlying Ethereum keystore.
 type KeystoreWrapper struct {
 	ks  *k.KeyStore
 	dir string
+	kdf KDF
 }
 
 // NewKeyStore initializes a new Ethereum keystore and the directory where it's stored.
 func NewKeyStore(dir string) *KeystoreWrapper {
-	ks := k.NewKeyStore(dir, k.StandardScryptN, k.StandardScryptP)
-	return &KeystoreWrapper{
-		ks:  ks,
+	return NewKeyStoreWithKDF(dir, StandardScrypt)
+}
+
+// NewKeyStoreWithKDF initializes a new Ethereum keystore that encrypts new key files with the given KDF.
+// Existing key files are unlocked with the parameters stored in them, whatever those are.
+func NewKeyStoreWithKDF(dir string, kdf KDF) *KeystoreWrapper {
+	kst := &KeystoreWrapper{
 		dir: dir,
+		kdf: kdf,
 	}
+	kst.reload()
+	return kst
 }
 
-// ImportPrivateKey imports a private key into the keystore, optionally overwriting an existing account.
-func (kst *KeystoreWrapper) ImportPrivateKey(privateKeyHex string, password string, overwrite bool) error {
+// reload recreates the underlying keystore, forcing a rescan of the directory.
+func (kst *KeystoreWrapper) reload() {
+	n, p := k.StandardScryptN, k.StandardScryptP
+	if kst.kdf.gethNative() {
+		n, p = kst.kdf.N, kst.kdf.P
+	}
+	kst.ks = k.NewKeyStore(kst.dir, n, p)
+}
+
+// ImportPrivateKey imports a private key into the keystore, failing with ErrAccountExists if the account is already
+// present. Use a Transaction to replace accounts. It returns the account pointing to the newly written key file.
+func (kst *KeystoreWrapper) ImportPrivateKey(privateKeyHex string, password string) (accounts.Account, error) {
 	// Decode the private key.
 	key, err := crypto.HexToECDSA(privateKeyHex)
 	if err != nil {
-		return fmt.Errorf("failed to decode private key: %w", err)
+		return accounts.Account{}, fmt.Errorf("failed to decode private key: %w", err)
 	}
 
 	address := crypto.PubkeyToAddress(key.PublicKey)
-	if kst.ks.HasAddress(address) && !overwrite {
-		return fmt.Errorf("address already exists")
+	if kst.ks.HasAddress(address) {
+		return accounts.Account{}, ErrAccountExists
 	}
 
-	if kst.ks.HasAddress(address) && overwrite {
-		if err := kst.UnsafeDeleteAccount(address); err != nil {
-			return fmt.Errorf("failed to delete existing account: %w", err)
+	// Import the new account into the keystore, geth only knows how to encrypt with r=8 scrypt.
+	if kst.kdf.gethNative() {
+		account, err := kst.ks.ImportECDSA(key, password)
+		if err != nil {
+			return accounts.Account{}, fmt.Errorf("failed to import private key: %w", err)
 		}
+		return account, nil
 	}
 
-	// Import the new account into the keystore.
-	if _, err := kst.ks.ImportECDSA(key, password); err != nil {
-		return fmt.Errorf("failed to import private key: %w", err)
+	keyJSON, err := EncryptKey(key, password, kst.kdf)
+	if err != nil {
+		return accounts.Account{}, fmt.Errorf("failed to encrypt private key: %w", err)
 	}
+	file := filepath.Join(kst.dir, keyFileName(address, ""))
+	if err := writeKeyFile(file, keyJSON); err != nil {
+		return accounts.Account{}, fmt.Errorf("failed to write key file: %w", err)
+	}
+	kst.reload()
 
-	return nil
+	return accounts.Account{Address: address, URL: accounts.URL{Scheme: k.KeyStoreScheme, Path: file}}, nil
+}
+
+// Reload rescans the keystore directory, picking up key files written by other processes.
+func (kst *KeystoreWrapper) Reload() {
+	kst.reload()
+}
+
+// HasAddress reports whether a key file for the given address is present in the keystore.
+func (kst *KeystoreWrapper) HasAddress(address common.Address) bool {
+	return kst.ks.HasAddress(address)
+}
+
+// Find returns the account, including its key file path, for the given address.
+func (kst *KeystoreWrapper) Find(address common.Address) (accounts.Account, error) {
+	account, err := kst.ks.Find(accounts.Account{Address: address})
+	if err != nil {
+		return accounts.Account{}, fmt.Errorf("failed to find account %s: %w", address.Hex(), err)
+	}
+	return account, nil
+}
+
+// DecryptKey unlocks the key file of the given address, using the KDF parameters stored in the file.
+func (kst *KeystoreWrapper) DecryptKey(address common.Address, password string) (*k.Key, error) {
+	account, err := kst.Find(address)
+	if err != nil {
+		return nil, err
+	}
+
+	keyJSON, err := os.ReadFile(account.URL.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+
+	key, err := k.DecryptKey(keyJSON, password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt key file: %w", err)
+	}
+	if key.Address != address {
+		return nil, fmt.Errorf("key content mismatch: have account %s, want %s", key.Address.Hex(), address.Hex())
+	}
+
+	return key, nil
 }
 
-// UnsafeDeleteAccount deletes an Ethereum account without requiring its password.
-// TODO: Probably will be removed but leaving it for now
-func (kst *KeystoreWrapper) UnsafeDeleteAccount(address common.Address) error {
-	// Check if the address exists in the keystore.
-	if !kst.ks.HasAddress(address) {
-		return fmt.Errorf("address does not exist")
+// ChangePassword re-encrypts the key file of the given address with a new password, using the given KDF, or the KDF
+// and parameters the key file is already encrypted with when kdf is nil. The key file is replaced atomically and
+// keeps its name and key id.
+func (kst *KeystoreWrapper) ChangePassword(address common.Address, oldPassword, newPassword string, kdf *KDF) error {
+	account, err := kst.Find(address)
+	if err != nil {
+		return err
 	}
 
-	// Read filenames in the keystore directory.
-	files, err := os.ReadDir(kst.dir)
+	key, err := kst.DecryptKey(address, oldPassword)
 	if err != nil {
-		return fmt.Errorf("failed to read keystore directory: %w", err)
-	}
-
-	// Search for the file corresponding to the address and delete it.
-	for _, file := range files {
-		if strings.Contains(file.Name(), strings.ToLower(address.Hex()[2:])) {
-			err := os.Remove(filepath.Join(kst.dir, file.Name()))
-			if err != nil {
-				return fmt.Errorf("failed to delete keystore file: %w", err)
-			}
-			kst.ks.Wallets() // Calling this, will refresh internally the list of wallets (inneficient but works)
-			return nil
+		return err
+	}
+
+	if kdf == nil {
+		keyFile, err := ReadKeyFile(account.URL.Path)
+		if err != nil {
+			return err
 		}
+		kdf = &keyFile.KDF
+	}
+	keyJSON, err := encryptKey(key.PrivateKey, key.Id, newPassword, *kdf)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt private key: %w", err)
+	}
+	if err := writeKeyFile(account.URL.Path, keyJSON); err != nil {
+		return fmt.Errorf("failed to write key file: %w", err)
+	}
+
+	return nil
+}
+
+// Dir returns the directory where the key files are stored.
+func (kst *KeystoreWrapper) Dir() string {
+	return kst.dir
+}
+
+// KDF returns the key derivation function used to encrypt new key files.
+func (kst *KeystoreWrapper) KDF() KDF {
+	return kst.kdf
+}
+
+// keyFileName returns the geth-style file name for a key file, i.e. UTC--<created_at UTC ISO8601>--<address hex>,
+// prefixed with <alias>-- when an alias is given.
+func keyFileName(address common.Address, alias string) string {
+	ts := time.Now().UTC()
+	name := fmt.Sprintf("UTC--%s--%s", toISO8601(ts), hex.EncodeToString(address[:]))
+	if alias != "" {
+		name = alias + "--" + name
 	}
+	return name
+}
+
+func toISO8601(t time.Time) string {
+	var tz string
+	name, offset := t.Zone()
+	if name == "UTC" {
+		tz = "Z"
+	} else {
+		tz = fmt.Sprintf("%03d00", offset/3600)
+	}
+	return fmt.Sprintf("%04d-%02d-%02dT%02d-%02d-%02d.%09d%s",
+		t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), tz)
+}
 
-	return fmt.Errorf("keystore file for the address %s not found", strings.ToLower(address.Hex()))
+// writeKeyFile writes the key file through a temporary file in the same directory, renaming it into place. The file
+// and the directory are synced, so the key file is either fully written or absent after a crash.
+func writeKeyFile(file string, content []byte) error {
+	dir := filepath.Dir(file)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, "."+filepath.Base(file)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), file); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return syncDir(dir)
+}
+
+// syncDir flushes the entries of a directory, making renames into it durable.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
 }
 
 // Accounts returns all key files present in the directory.
@@ -1,9 +1,18 @@
 package keystore
 
 import (
+	"context"
+	"encoding/hex"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/aldoborrero/ethw/internal/wallet"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 )
@@ -39,21 +48,573 @@ func (suite *KeystoreTestSuite) TestImportPrivateKey() {
 	password := "1234"
 
 	// Try importing a private key
-	err := suite.kst.ImportPrivateKey(privateKeyHex, password, false)
+	_, err := suite.kst.ImportPrivateKey(privateKeyHex, password)
 	assert.NoError(suite.T(), err, "Importing private key should succeed")
 
 	// Validate the account is created
 	accounts := suite.kst.Accounts()
 	assert.Equal(suite.T(), 1, len(accounts), "One account should exist")
 
-	// Try importing the same key again, should fail as overwrite is false
-	err = suite.kst.ImportPrivateKey(privateKeyHex, password, false)
-	assert.Error(suite.T(), err, "Importing same private key without overwrite should fail")
+	// Try importing the same key again, should fail as the account exists
+	_, err = suite.kst.ImportPrivateKey(privateKeyHex, password)
+	assert.Error(suite.T(), err, "Importing same private key twice should fail")
+}
+
+func (suite *KeystoreTestSuite) TestImportPrivateKeyExisting() {
+	privateKeyHex := "8e46b439b30731a639a3d94a9016b040a87b3027da8c932af7e1560862d11b58"
+	kst := NewKeyStoreWithKDF(suite.tempDir, LightScrypt)
+
+	first, err := kst.ImportPrivateKey(privateKeyHex, "1234")
+	assert.NoError(suite.T(), err, "Importing private key should succeed")
+	assert.FileExists(suite.T(), first.URL.Path, "The returned account should point to the key file")
+
+	_, err = kst.ImportPrivateKey(privateKeyHex, "5678")
+	assert.ErrorIs(suite.T(), err, ErrAccountExists)
+	assert.Equal(suite.T(), 1, len(kst.Accounts()), "The existing account should be left untouched")
+
+	_, err = kst.DecryptKey(first.Address, "1234")
+	assert.NoError(suite.T(), err, "The existing key file should keep its password")
+}
+
+func (suite *KeystoreTestSuite) TestImportPrivateKeyWithCustomKDF() {
+	privateKeyHex := "8e46b439b30731a639a3d94a9016b040a87b3027da8c932af7e1560862d11b58"
+	password := "1234"
+
+	kdfs := []KDF{
+		LightScrypt,
+		NewScryptKDF(1<<10, 4, 2),
+		NewPBKDF2KDF(1024),
+	}
+
+	for _, kdf := range kdfs {
+		dir := suite.T().TempDir()
+		kst := NewKeyStoreWithKDF(dir, kdf)
+
+		_, err := kst.ImportPrivateKey(privateKeyHex, password)
+		assert.NoError(suite.T(), err, "Importing private key with %s should succeed", kdf)
+
+		accounts := kst.Accounts()
+		if assert.Equal(suite.T(), 1, len(accounts), "One account should exist with %s", kdf) {
+			// Unlocking must work with a keystore configured with different parameters
+			key, err := NewKeyStore(dir).DecryptKey(accounts[0].Address, password)
+			assert.NoError(suite.T(), err, "Decrypting key encrypted with %s should succeed", kdf)
+			assert.Equal(suite.T(), accounts[0].Address, key.Address)
+
+			_, err = kst.DecryptKey(accounts[0].Address, "wrong")
+			assert.Error(suite.T(), err, "Decrypting with a wrong password should fail")
+		}
+	}
+}
+
+func (suite *KeystoreTestSuite) TestVerifyKeyFile() {
+	privateKeyHex := "8e46b439b30731a639a3d94a9016b040a87b3027da8c932af7e1560862d11b58"
+	kst := NewKeyStoreWithKDF(suite.tempDir, LightScrypt)
+
+	account, err := kst.ImportPrivateKey(privateKeyHex, "1234")
+	assert.NoError(suite.T(), err, "Importing private key should succeed")
+
+	result := VerifyKeyFile(account.URL.Path, VerifyOptions{
+		Password:    "1234",
+		HasPassword: true,
+		Derivable:   map[common.Address]string{account.Address: "m/44'/60'/0'/0"},
+	})
+	assert.True(suite.T(), result.OK(), "A freshly written key file should verify: %v", result.Failures())
+	assert.Equal(suite.T(), "m/44'/60'/0'/0", result.DerivationPath)
+
+	result = VerifyKeyFile(account.URL.Path, VerifyOptions{Password: "wrong", HasPassword: true})
+	assert.Equal(suite.T(), CheckFailed, result.Status(CheckMAC), "A wrong password should fail the MAC check")
+	assert.Equal(suite.T(), CheckSkipped, result.Status(CheckDecrypt))
+
+	result = VerifyKeyFile(account.URL.Path, VerifyOptions{Derivable: map[common.Address]string{}})
+	assert.Equal(suite.T(), CheckSkipped, result.Status(CheckMAC), "MAC can't be checked without a password")
+	assert.Equal(suite.T(), CheckFailed, result.Status(CheckMnemonic))
+
+	// A valid key file with a misleading name
+	content, err := os.ReadFile(account.URL.Path)
+	assert.NoError(suite.T(), err)
+	renamed := filepath.Join(suite.T().TempDir(), "UTC--2023-01-01T00-00-00.000000000Z--0000000000000000000000000000000000000000")
+	assert.NoError(suite.T(), os.WriteFile(renamed, content, 0o600))
+	result = VerifyKeyFile(renamed, VerifyOptions{})
+	assert.Equal(suite.T(), CheckFailed, result.Status(CheckFilename))
+
+	// A truncated key file
+	corrupt := filepath.Join(suite.T().TempDir(), "corrupt")
+	assert.NoError(suite.T(), os.WriteFile(corrupt, content[:len(content)/2], 0o600))
+	result = VerifyKeyFile(corrupt, VerifyOptions{Password: "1234", HasPassword: true})
+	assert.Equal(suite.T(), CheckFailed, result.Status(CheckJSON))
+	assert.False(suite.T(), result.OK())
+}
+
+func (suite *KeystoreTestSuite) TestVerifyKeyFileDerivation() {
+	mnemonic := "install puzzle strike suit boil skate find address thrive reopen outdoor churn"
+	path := "m/44'/60'/0'/0/0"
+	derived, err := wallet.NewWallet(mnemonic, "", path)
+	assert.NoError(suite.T(), err)
+	kst := NewKeyStoreWithKDF(suite.tempDir, LightScrypt)
+
+	account, err := kst.ImportPrivateKey(derived.PrivateKey, "1234")
+	assert.NoError(suite.T(), err)
+	result := VerifyKeyFile(account.URL.Path, VerifyOptions{Password: "1234", HasPassword: true, Mnemonic: mnemonic, DerivationPath: path})
+	assert.True(suite.T(), result.OK(), "The key derived from the mnemonic should verify: %v", result.Failures())
+	assert.Equal(suite.T(), path, result.DerivationPath)
+
+	// A key file holding another key, even one whose address was expected, fails the round trip
+	other, err := kst.ImportPrivateKey("8e46b439b30731a639a3d94a9016b040a87b3027da8c932af7e1560862d11b58", "1234")
+	assert.NoError(suite.T(), err)
+	result = VerifyKeyFile(other.URL.Path, VerifyOptions{
+		Password:       "1234",
+		HasPassword:    true,
+		Derivable:      map[common.Address]string{other.Address: path},
+		Mnemonic:       mnemonic,
+		DerivationPath: path,
+	})
+	assert.Equal(suite.T(), CheckOK, result.Status(CheckAddress))
+	assert.Equal(suite.T(), CheckFailed, result.Status(CheckMnemonic))
+	assert.False(suite.T(), result.OK())
+
+	result = VerifyKeyFile(account.URL.Path, VerifyOptions{Mnemonic: mnemonic, DerivationPath: "m/44'/60'/0'/0/1"})
+	assert.Equal(suite.T(), CheckFailed, result.Status(CheckMnemonic), "Another derivation path derives another key")
+}
+
+func (suite *KeystoreTestSuite) TestListFilterAndSort() {
+	created, ok := ParseKeyFileTime("UTC--2023-10-05T08-30-12.123456789Z--8d86d515fbee6a364c96cf60f3220826f13a64f3")
+	assert.True(suite.T(), ok, "geth file names should be parsed")
+	assert.Equal(suite.T(), time.Date(2023, 10, 5, 8, 30, 12, 123456789, time.UTC), created)
+
+	_, ok = ParseKeyFileTime("my-key.json")
+	assert.False(suite.T(), ok, "other file names have no creation date")
+
+	infos := []AccountInfo{
+		{Address: common.HexToAddress("0xbb00000000000000000000000000000000000000"), Path: "a", KDF: LightScrypt, CreatedAt: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Address: common.HexToAddress("0xaa00000000000000000000000000000000000000"), Path: "b", KDF: NewPBKDF2KDF(10), CreatedAt: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Address: common.HexToAddress("0xab00000000000000000000000000000000000000"), Path: "c", KDF: StandardScrypt},
+	}
+
+	filtered := FilterAccountInfos(infos, ListFilter{AddressPrefix: "0xA"})
+	assert.Len(suite.T(), filtered, 2, "Address prefixes are case insensitive")
+
+	filtered = FilterAccountInfos(infos, ListFilter{KDF: KDFScrypt, CreatedAfter: time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)})
+	assert.Len(suite.T(), filtered, 1, "Accounts without creation date don't match date ranges")
+	assert.Equal(suite.T(), "a", filtered[0].Path)
+
+	assert.NoError(suite.T(), SortAccountInfos(infos, SortByAddress, false))
+	assert.Equal(suite.T(), []string{"b", "c", "a"}, []string{infos[0].Path, infos[1].Path, infos[2].Path})
+
+	assert.NoError(suite.T(), SortAccountInfos(infos, SortByCreated, true))
+	assert.Equal(suite.T(), "a", infos[0].Path, "The newest account should come first in reverse order")
+
+	assert.Error(suite.T(), SortAccountInfos(infos, "size", false))
+}
+
+func (suite *KeystoreTestSuite) TestKDFValidate() {
+	assert.NoError(suite.T(), StandardScrypt.Validate())
+	assert.NoError(suite.T(), NewPBKDF2KDF(1).Validate())
+	assert.Error(suite.T(), NewScryptKDF(1000, 8, 1).Validate(), "N must be a power of two")
+	assert.Error(suite.T(), NewScryptKDF(1024, 0, 1).Validate(), "r must be positive")
+	assert.Error(suite.T(), NewPBKDF2KDF(0).Validate(), "iterations must be positive")
+	assert.Error(suite.T(), KDF{Name: "argon2"}.Validate(), "unknown KDFs are rejected")
+}
+
+func (suite *KeystoreTestSuite) TestChangePassword() {
+	privateKeyHex := "8e46b439b30731a639a3d94a9016b040a87b3027da8c932af7e1560862d11b58"
+	kst := NewKeyStoreWithKDF(suite.tempDir, NewPBKDF2KDF(1024))
+	account, err := kst.ImportPrivateKey(privateKeyHex, "1234")
+	assert.NoError(suite.T(), err)
+
+	// A keystore configured with another KDF keeps the KDF of the key file
+	kst = NewKeyStore(suite.tempDir)
+	assert.NoError(suite.T(), kst.ChangePassword(account.Address, "1234", "5678", nil))
+	keyFile, err := ReadKeyFile(account.URL.Path)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), NewPBKDF2KDF(1024), keyFile.KDF)
+	_, err = kst.DecryptKey(account.Address, "5678")
+	assert.NoError(suite.T(), err)
+
+	light := LightScrypt
+	assert.NoError(suite.T(), kst.ChangePassword(account.Address, "5678", "9012", &light))
+	keyFile, err = ReadKeyFile(account.URL.Path)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), LightScrypt, keyFile.KDF)
+
+	assert.Error(suite.T(), kst.ChangePassword(account.Address, "wrong", "1234", nil))
+}
+
+func (suite *KeystoreTestSuite) TestTransaction() {
+	kdf := NewPBKDF2KDF(2)
+	encrypt := func(hexKey string) (common.Address, []byte) {
+		key, err := crypto.HexToECDSA(hexKey)
+		assert.NoError(suite.T(), err)
+		keyJSON, err := EncryptKey(key, "1234", kdf)
+		assert.NoError(suite.T(), err)
+		return crypto.PubkeyToAddress(key.PublicKey), keyJSON
+	}
+	existing, err := suite.kst.ImportPrivateKey("8e46b439b30731a639a3d94a9016b040a87b3027da8c932af7e1560862d11b58", "1234")
+	assert.NoError(suite.T(), err)
+
+	// Staged key files are written concurrently, and only show up once committed
+	tx, err := suite.kst.Begin()
+	assert.NoError(suite.T(), err)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			key, err := crypto.GenerateKey()
+			assert.NoError(suite.T(), err)
+			address, keyJSON := encrypt(hex.EncodeToString(crypto.FromECDSA(key)))
+			_, _, err = tx.WriteKey(address, "", keyJSON, false)
+			assert.NoError(suite.T(), err)
+		}()
+	}
+	wg.Wait()
+
+	address, keyJSON := encrypt("8e46b439b30731a639a3d94a9016b040a87b3027da8c932af7e1560862d11b58")
+	_, _, err = tx.WriteKey(address, "", keyJSON, false)
+	assert.ErrorIs(suite.T(), err, ErrAccountExists)
+	replaced, staged, err := tx.WriteKey(address, "", keyJSON, true)
+	assert.NoError(suite.T(), err)
+	assert.FileExists(suite.T(), staged)
+
+	suite.kst.Reload()
+	assert.Len(suite.T(), suite.kst.Accounts(), 1)
+	assert.NoError(suite.T(), tx.Commit())
+	assert.Len(suite.T(), suite.kst.Accounts(), 9)
+	assert.NoFileExists(suite.T(), existing.URL.Path)
+	assert.FileExists(suite.T(), replaced.URL.Path)
+
+	// Rolled back transactions leave the keystore untouched, even when replacing and removing accounts
+	tx, err = suite.kst.Begin()
+	assert.NoError(suite.T(), err)
+	_, _, err = tx.WriteKey(address, "", keyJSON, true)
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), tx.Remove(suite.kst.Accounts()[0]))
+	assert.NoError(suite.T(), tx.Rollback())
+	assert.ErrorIs(suite.T(), tx.Commit(), ErrTransactionDone)
+	suite.kst.Reload()
+	assert.Len(suite.T(), suite.kst.Accounts(), 9)
+
+	// Removed accounts are dropped on commit
+	tx, err = suite.kst.Begin()
+	assert.NoError(suite.T(), err)
+	for _, account := range suite.kst.Accounts() {
+		if account.Address != address {
+			assert.NoError(suite.T(), tx.Remove(account))
+		}
+	}
+	assert.NoError(suite.T(), tx.Commit())
+	assert.Len(suite.T(), suite.kst.Accounts(), 1)
+	entries, err := os.ReadDir(suite.tempDir)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), entries, 1, "staging and backup directories must be removed")
+}
+
+func (suite *KeystoreTestSuite) TestWatch() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	events := make(chan WatchEvent)
+	done := make(chan error, 1)
+	go func() {
+		done <- suite.kst.Watch(ctx, false, func(event WatchEvent) error {
+			events <- event
+			return nil
+		})
+	}()
+
+	// Key files written by another process show up as arrived, and as dropped once removed
+	writer := NewKeyStoreWithKDF(suite.tempDir, LightScrypt)
+	account, err := writer.ImportPrivateKey("8e46b439b30731a639a3d94a9016b040a87b3027da8c932af7e1560862d11b58", "1234")
+	assert.NoError(suite.T(), err)
+
+	next := func() WatchEvent {
+		select {
+		case event := <-events:
+			return event
+		case <-ctx.Done():
+			suite.FailNow("Timed out waiting for a watch event")
+			return WatchEvent{}
+		}
+	}
+
+	event := next()
+	assert.Equal(suite.T(), WatchEventArrived, event.Type)
+	assert.Equal(suite.T(), account.Address, event.Address)
+	assert.Equal(suite.T(), account.URL.Path, event.Path)
+
+	assert.NoError(suite.T(), os.Remove(account.URL.Path))
+	event = next()
+	assert.Equal(suite.T(), WatchEventDropped, event.Type)
+	assert.Equal(suite.T(), account.Address, event.Address)
+
+	cancel()
+	assert.NoError(suite.T(), <-done)
+}
+
+func (suite *KeystoreTestSuite) TestPlan() {
+	manifestPath := filepath.Join(suite.T().TempDir(), "manifest.yaml")
+	err := os.WriteFile(manifestPath, []byte(`
+keystore_dir: ./keystore
+mnemonics:
+  devnet:
+    env: DEVNET_MNEMONIC
+accounts:
+  - alias: faucet
+    mnemonic: devnet
+    index: 0
+    password:
+      file: faucet.pw
+  - mnemonic: devnet
+    path: "m/44'/60'/0'/0/1"
+    on_conflict: replace
+`), 0o600)
+	assert.NoError(suite.T(), err)
+
+	manifest, err := ReadManifest(manifestPath)
+	if assert.NoError(suite.T(), err) {
+		assert.Equal(suite.T(), filepath.Join(filepath.Dir(manifestPath), "keystore"), manifest.KeystoreDir)
+		assert.Equal(suite.T(), filepath.Join(filepath.Dir(manifestPath), "faucet.pw"), manifest.Accounts[0].Password.File)
+		assert.Equal(suite.T(), ConflictKeep, manifest.Accounts[0].OnConflict)
+	}
+
+	manifest.Accounts[1].Mnemonic = "unknown"
+	assert.ErrorContains(suite.T(), manifest.Validate(), "unknown mnemonic")
+
+	existing, err := suite.kst.ImportPrivateKey("8e46b439b30731a639a3d94a9016b040a87b3027da8c932af7e1560862d11b58", "1234")
+	assert.NoError(suite.T(), err)
+	extra, err := suite.kst.ImportPrivateKey("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291", "1234")
+	assert.NoError(suite.T(), err)
+	missing := common.HexToAddress("0x0000000000000000000000000000000000000001")
+
+	desired := []DesiredAccount{
+		{Alias: "existing", Address: existing.Address, OnConflict: ConflictKeep},
+		{Alias: "missing", Address: missing, OnConflict: ConflictFail},
+	}
+	plan := suite.kst.Plan(desired, false)
+	if assert.Len(suite.T(), plan, 2) {
+		assert.Equal(suite.T(), ActionKeep, plan[0].Action)
+		assert.Equal(suite.T(), existing.URL.Path, plan[0].KeyFile)
+		assert.Equal(suite.T(), ActionCreate, plan[1].Action)
+	}
+	assert.Equal(suite.T(), 1, PlanChanges(plan))
+
+	desired[0].OnConflict = ConflictReplace
+	desired = append(desired, DesiredAccount{Address: missing})
+	plan = suite.kst.Plan(desired, true)
+	if assert.Len(suite.T(), plan, 4) {
+		assert.Equal(suite.T(), ActionReplace, plan[0].Action)
+		assert.Equal(suite.T(), ActionConflict, plan[2].Action, "accounts declared twice conflict")
+		assert.Equal(suite.T(), ActionRemoveExtra, plan[3].Action)
+		assert.Equal(suite.T(), extra.Address, plan[3].Address)
+		assert.Equal(suite.T(), -1, plan[3].Index)
+	}
+
+	desired[0].OnConflict = ConflictFail
+	plan = suite.kst.Plan(desired[:1], false)
+	assert.Equal(suite.T(), ActionConflict, plan[0].Action)
+	assert.ErrorIs(suite.T(), plan[0].Err, ErrAccountExists)
+}
+
+func (suite *KeystoreTestSuite) TestMetadata() {
+	account, err := suite.kst.ImportPrivateKey("8e46b439b30731a639a3d94a9016b040a87b3027da8c932af7e1560862d11b58", "1234")
+	assert.NoError(suite.T(), err)
+	other := common.HexToAddress("0x0000000000000000000000000000000000000001")
+
+	md, err := suite.kst.Metadata()
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), md.Save())
+	assert.NoFileExists(suite.T(), filepath.Join(suite.tempDir, MetadataFileName), "empty metadata isn't written")
+
+	err = md.Update(account.Address, func(meta *AccountMetadata) {
+		meta.Alias = "faucet"
+		meta.AddTags("ci", "team:infra", "ci")
+		meta.State = StateRetired
+	})
+	assert.NoError(suite.T(), err)
+	assert.Error(suite.T(), md.Update(other, func(meta *AccountMetadata) { meta.Alias = "faucet" }), "aliases are unique")
+	assert.Error(suite.T(), md.Update(other, func(meta *AccountMetadata) { meta.Alias = "0xfaucet" }))
+	assert.Error(suite.T(), md.Update(other, func(meta *AccountMetadata) { meta.State = "lost" }))
+	assert.NoError(suite.T(), md.Save())
+
+	// The sidecar file is neither an account nor a key file
+	md, err = ReadMetadata(suite.tempDir)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"ci", "team:infra"}, md.Get(account.Address).Tags)
+	address, err := md.Resolve("faucet")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), account.Address, address)
+	files, err := KeyFiles(suite.tempDir)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), files, 1)
+
+	suite.kst.Reload()
+	infos, err := suite.kst.AccountInfos()
+	if assert.NoError(suite.T(), err) && assert.Len(suite.T(), infos, 1) {
+		assert.Equal(suite.T(), "faucet", infos[0].Metadata.Alias)
+		assert.Len(suite.T(), FilterAccountInfos(infos, ListFilter{Tag: "ci", State: StateRetired}), 1)
+		assert.Empty(suite.T(), FilterAccountInfos(infos, ListFilter{State: StateActive}))
+	}
+
+	// Key files can be named after their alias
+	key, err := crypto.GenerateKey()
+	assert.NoError(suite.T(), err)
+	keyJSON, err := EncryptKey(key, "1234", NewPBKDF2KDF(2))
+	assert.NoError(suite.T(), err)
+	tx, err := suite.kst.Begin()
+	assert.NoError(suite.T(), err)
+	named, _, err := tx.WriteKey(crypto.PubkeyToAddress(key.PublicKey), "deployer", keyJSON, false)
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), tx.Commit())
+	assert.True(suite.T(), strings.HasPrefix(filepath.Base(named.URL.Path), "deployer--UTC--"))
+	_, ok := ParseKeyFileTime(filepath.Base(named.URL.Path))
+	assert.True(suite.T(), ok)
+	assert.True(suite.T(), suite.kst.HasAddress(named.Address))
+}
+
+func (suite *KeystoreTestSuite) TestMergeAndDiff() {
+	const shared = "8e46b439b30731a639a3d94a9016b040a87b3027da8c932af7e1560862d11b58"
+	a := NewKeyStoreWithKDF(suite.T().TempDir(), NewPBKDF2KDF(2))
+	b := NewKeyStoreWithKDF(suite.T().TempDir(), NewPBKDF2KDF(2))
+	account, err := a.ImportPrivateKey(shared, "1234")
+	assert.NoError(suite.T(), err)
+	onlyA, err := a.ImportPrivateKey("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291", "1234")
+	assert.NoError(suite.T(), err)
+	// The same key encrypted again conflicts, its ciphertext differs
+	_, err = b.ImportPrivateKey(shared, "1234")
+	assert.NoError(suite.T(), err)
+
+	entries, err := Diff(a.Dir(), b.Dir())
+	assert.NoError(suite.T(), err)
+	statuses := make(map[common.Address]string)
+	for _, entry := range entries {
+		statuses[entry.Address] = entry.Status
+	}
+	assert.Equal(suite.T(), map[common.Address]string{account.Address: DiffConflict, onlyA.Address: DiffOnlyA}, statuses)
+
+	// Conflicts fail the whole merge by default
+	results, err := suite.kst.Merge([]string{a.Dir(), b.Dir()}, MergeOptions{OnConflict: MergeOnConflictFail})
+	assert.ErrorIs(suite.T(), err, ErrMergeConflict)
+	assert.Len(suite.T(), results, 3)
+	suite.kst.Reload()
+	assert.Empty(suite.T(), suite.kst.Accounts())
+
+	results, err = suite.kst.Merge([]string{a.Dir(), b.Dir()}, MergeOptions{OnConflict: MergeOnConflictSkip})
+	assert.NoError(suite.T(), err)
+	if assert.Len(suite.T(), results, 3) {
+		assert.Equal(suite.T(), MergeSkippedConflict, results[2].Status)
+	}
+	assert.Len(suite.T(), suite.kst.Accounts(), 2)
+	entries, err = Diff(a.Dir(), suite.kst.Dir())
+	assert.NoError(suite.T(), err)
+	for _, entry := range entries {
+		assert.Equal(suite.T(), DiffIdentical, entry.Status, "merged key files keep their content")
+	}
+
+	// Copies replace conflicting key files on request, and fail on missing accounts
+	results, err = suite.kst.Merge([]string{b.Dir()}, MergeOptions{Addresses: []common.Address{account.Address}, OnConflict: MergeOnConflictReplace})
+	assert.NoError(suite.T(), err)
+	if assert.Len(suite.T(), results, 1) {
+		assert.Equal(suite.T(), MergeReplaced, results[0].Status)
+	}
+	entries, err = Diff(b.Dir(), suite.kst.Dir())
+	assert.NoError(suite.T(), err)
+	for _, entry := range entries {
+		if entry.Address == account.Address {
+			assert.Equal(suite.T(), DiffIdentical, entry.Status)
+		}
+	}
+
+	_, err = suite.kst.Merge([]string{b.Dir()}, MergeOptions{Addresses: []common.Address{onlyA.Address}, OnConflict: MergeOnConflictFail})
+	assert.ErrorIs(suite.T(), err, ErrMergeConflict)
+}
+
+// TestMergeSameFileName merges accounts whose key files share a name, which must never overwrite each other.
+func (suite *KeystoreTestSuite) TestMergeSameFileName() {
+	keys := []string{
+		"8e46b439b30731a639a3d94a9016b040a87b3027da8c932af7e1560862d11b58",
+		"b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291",
+		"4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318",
+	}
+	dirs := make([]string, len(keys))
+	addresses := make([]common.Address, len(keys))
+	for i, key := range keys {
+		source := NewKeyStoreWithKDF(suite.T().TempDir(), NewPBKDF2KDF(2))
+		account, err := source.ImportPrivateKey(key, "1234")
+		assert.NoError(suite.T(), err)
+		assert.NoError(suite.T(), os.Rename(account.URL.Path, filepath.Join(source.Dir(), "keystore.json")))
+		dirs[i], addresses[i] = source.Dir(), account.Address
+	}
+
+	results, err := suite.kst.Merge(dirs[:2], MergeOptions{OnConflict: MergeOnConflictFail})
+	assert.NoError(suite.T(), err)
+	if assert.Len(suite.T(), results, 2) {
+		assert.Equal(suite.T(), "keystore.json", filepath.Base(results[0].Destination))
+		assert.NotEqual(suite.T(), "keystore.json", filepath.Base(results[1].Destination))
+	}
+	suite.kst.Reload()
+	assert.Len(suite.T(), suite.kst.Accounts(), 2)
+
+	// The key file of an account already in the keystore isn't overwritten either
+	results, err = suite.kst.Merge(dirs[2:], MergeOptions{OnConflict: MergeOnConflictFail})
+	assert.NoError(suite.T(), err)
+	if assert.Len(suite.T(), results, 1) {
+		assert.Equal(suite.T(), MergeCopied, results[0].Status)
+		assert.NotEqual(suite.T(), "keystore.json", filepath.Base(results[0].Destination))
+	}
+	suite.kst.Reload()
+	assert.Len(suite.T(), suite.kst.Accounts(), 3)
+	keyFile, err := ReadKeyFile(filepath.Join(suite.kst.Dir(), "keystore.json"))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), addresses[0], keyFile.Address)
+
+	// Transactions refuse names used by files they don't replace, at staging and at commit time
+	content, err := os.ReadFile(filepath.Join(dirs[2], "keystore.json"))
+	assert.NoError(suite.T(), err)
+	other := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	tx, err := suite.kst.Begin()
+	assert.NoError(suite.T(), err)
+	_, _, err = tx.WriteKeyFile(other, "keystore.json", content, false)
+	assert.ErrorIs(suite.T(), err, ErrKeyFileNameTaken)
+	_, _, err = tx.WriteKeyFile(other, "late.json", content, false)
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), os.WriteFile(filepath.Join(suite.kst.Dir(), "late.json"), []byte("{}"), 0o600))
+	assert.ErrorIs(suite.T(), tx.Commit(), ErrKeyFileNameTaken)
+	late, err := os.ReadFile(filepath.Join(suite.kst.Dir(), "late.json"))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "{}", string(late))
+}
+
+func (suite *KeystoreTestSuite) TestRestorePlan() {
+	existing, err := suite.kst.ImportPrivateKey("8e46b439b30731a639a3d94a9016b040a87b3027da8c932af7e1560862d11b58", "1234")
+	assert.NoError(suite.T(), err)
+	extra, err := suite.kst.ImportPrivateKey("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291", "1234")
+	assert.NoError(suite.T(), err)
+	broken := filepath.Join(suite.kst.Dir(), "broken")
+	assert.NoError(suite.T(), os.WriteFile(broken, []byte("{"), 0o600))
+	missing := common.HexToAddress("0x0000000000000000000000000000000000000001")
+
+	derived := []DesiredAccount{
+		{Address: missing, DerivationPath: "m/44'/60'/0'/0/5"},
+		{Address: existing.Address, DerivationPath: "m/44'/60'/0'/0/6"},
+	}
+	entries, err := suite.kst.RestorePlan(derived, 5)
+	assert.NoError(suite.T(), err)
+	if assert.Len(suite.T(), entries, 4) {
+		assert.Equal(suite.T(), RestoreEntry{Status: RestoreMissing, Index: 5, Address: missing, DerivationPath: "m/44'/60'/0'/0/5"}, entries[0])
+		assert.Equal(suite.T(), RestoreMatching, entries[1].Status)
+		assert.Equal(suite.T(), 6, entries[1].Index)
+		assert.Equal(suite.T(), existing.URL.Path, entries[1].KeyFile)
+		assert.Equal(suite.T(), RestoreUnexpected, entries[2].Status)
+		assert.Equal(suite.T(), extra.Address, entries[2].Address)
+		assert.False(suite.T(), entries[2].Derived())
+		assert.Equal(suite.T(), RestoreInvalid, entries[3].Status)
+		assert.Equal(suite.T(), broken, entries[3].KeyFile)
+	}
 
-	// TODO: Decide if it's worthwhile to implement this method
-	// Try importing the same key with overwrite enabled
-	// err = suite.kst.ImportPrivateKey(privateKeyHex, password, true)
-	// assert.NoError(suite.T(), err, "Importing same private key with overwrite should succeed")
+	// A lost keystore may not exist at all
+	lost := NewKeyStore(filepath.Join(suite.T().TempDir(), "lost"))
+	entries, err = lost.RestorePlan(derived, 5)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), entries, 2)
 }
 
 // Execute the test suite
@@ -0,0 +1,174 @@
+package keystore
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Sort orders supported by SortAccountInfos.
+const (
+	SortByPath    = "path"
+	SortByAddress = "address"
+	SortByCreated = "created"
+	SortByKDF     = "kdf"
+	SortByAlias   = "alias"
+)
+
+// AccountInfo holds the metadata of a key file, as found on disk.
+type AccountInfo struct {
+	Address common.Address
+	Path    string
+	ID      string
+	Version int
+	Cipher  string
+	KDF     KDF
+	// CreatedAt is parsed from geth's UTC--<timestamp>--<address> file name, it's zero for other names.
+	CreatedAt time.Time
+	// Metadata comes from the metadata sidecar file of the keystore.
+	Metadata AccountMetadata
+}
+
+// AccountInfos returns the metadata of every account in the keystore, including the one of the metadata sidecar
+// file. Key files which can't be parsed are still reported with their address and path.
+func (kst *KeystoreWrapper) AccountInfos() ([]AccountInfo, error) {
+	md, err := kst.Metadata()
+	if err != nil {
+		return nil, err
+	}
+
+	accounts := kst.Accounts()
+	infos := make([]AccountInfo, len(accounts))
+	for i, account := range accounts {
+		infos[i] = NewAccountInfo(account)
+		infos[i].Metadata = md.Get(account.Address)
+	}
+	return infos, nil
+}
+
+// NewAccountInfo reads the metadata of the key file of the given account.
+func NewAccountInfo(account accounts.Account) AccountInfo {
+	info := AccountInfo{
+		Address: account.Address,
+		Path:    account.URL.Path,
+	}
+	info.CreatedAt, _ = ParseKeyFileTime(filepath.Base(account.URL.Path))
+
+	if keyFile, err := ReadKeyFile(account.URL.Path); err == nil {
+		info.ID = keyFile.ID
+		info.Version = keyFile.Version
+		info.Cipher = keyFile.Cipher
+		info.KDF = keyFile.KDF
+	}
+
+	return info
+}
+
+// ParseKeyFileTime parses the creation timestamp of geth's UTC--<timestamp>--<address> key file names, optionally
+// prefixed with <alias>--.
+func ParseKeyFileTime(name string) (time.Time, bool) {
+	parts := strings.Split(name, "--")
+	if len(parts) == 4 {
+		parts = parts[1:]
+	}
+	if len(parts) != 3 || parts[0] != "UTC" {
+		return time.Time{}, false
+	}
+
+	for _, layout := range []string{"2006-01-02T15-04-05.999999999Z", "2006-01-02T15-04-05.999999999-0700"} {
+		if t, err := time.Parse(layout, parts[1]); err == nil {
+			return t.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// ListFilter selects accounts by address prefix, KDF, creation date, tag and lifecycle state. Zero values match
+// everything.
+type ListFilter struct {
+	AddressPrefix string
+	KDF           string
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	Tag           string
+	State         string
+}
+
+// Match reports whether the account matches every criteria of the filter.
+func (f ListFilter) Match(info AccountInfo) bool {
+	if f.AddressPrefix != "" {
+		prefix := strings.TrimPrefix(strings.ToLower(f.AddressPrefix), "0x")
+		if !strings.HasPrefix(strings.ToLower(info.Address.Hex()[2:]), prefix) {
+			return false
+		}
+	}
+	if f.KDF != "" && info.KDF.Name != f.KDF {
+		return false
+	}
+	// Accounts without a known creation date never match a date range
+	if !f.CreatedAfter.IsZero() && (info.CreatedAt.IsZero() || info.CreatedAt.Before(f.CreatedAfter)) {
+		return false
+	}
+	if !f.CreatedBefore.IsZero() && (info.CreatedAt.IsZero() || !info.CreatedAt.Before(f.CreatedBefore)) {
+		return false
+	}
+	if f.Tag != "" && !info.Metadata.HasTag(f.Tag) {
+		return false
+	}
+	if f.State != "" && info.Metadata.LifecycleState() != f.State {
+		return false
+	}
+	return true
+}
+
+// FilterAccountInfos returns the accounts matching the filter, keeping their order.
+func FilterAccountInfos(infos []AccountInfo, filter ListFilter) []AccountInfo {
+	filtered := make([]AccountInfo, 0, len(infos))
+	for _, info := range infos {
+		if filter.Match(info) {
+			filtered = append(filtered, info)
+		}
+	}
+	return filtered
+}
+
+// SortAccountInfos sorts the accounts in place by the given order, ties being broken by path.
+func SortAccountInfos(infos []AccountInfo, by string, reverse bool) error {
+	var less func(a, b AccountInfo) bool
+	switch by {
+	case SortByPath:
+		less = func(a, b AccountInfo) bool { return a.Path < b.Path }
+	case SortByAddress:
+		less = func(a, b AccountInfo) bool {
+			return strings.ToLower(a.Address.Hex()) < strings.ToLower(b.Address.Hex())
+		}
+	case SortByCreated:
+		less = func(a, b AccountInfo) bool { return a.CreatedAt.Before(b.CreatedAt) }
+	case SortByKDF:
+		less = func(a, b AccountInfo) bool { return a.KDF.String() < b.KDF.String() }
+	case SortByAlias:
+		less = func(a, b AccountInfo) bool { return a.Metadata.Alias < b.Metadata.Alias }
+	default:
+		return fmt.Errorf("unsupported sort order: %q", by)
+	}
+
+	sort.SliceStable(infos, func(i, j int) bool {
+		a, b := infos[i], infos[j]
+		if reverse {
+			a, b = b, a
+		}
+		if less(a, b) {
+			return true
+		}
+		if less(b, a) {
+			return false
+		}
+		return a.Path < b.Path
+	})
+	return nil
+}
@@ -0,0 +1,160 @@
+package keystore
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Conflict policies of manifest accounts, applied when the account is already present in the keystore.
+const (
+	// ConflictKeep leaves the existing key file untouched, the default.
+	ConflictKeep = "keep"
+	// ConflictReplace rewrites the key file with the password and KDF of the run.
+	ConflictReplace = "replace"
+	// ConflictFail refuses to apply the manifest.
+	ConflictFail = "fail"
+)
+
+// Manifest declares the accounts a keystore directory should hold, see KeystoreWrapper.Plan.
+type Manifest struct {
+	// KeystoreDir is the keystore directory, relative to the manifest.
+	KeystoreDir string `yaml:"keystore_dir"`
+	// RemoveExtra removes the key files of accounts the manifest doesn't declare.
+	RemoveExtra bool `yaml:"remove_extra"`
+	// NameByAlias prefixes the names of the key files written for accounts with an alias with <alias>--.
+	NameByAlias bool `yaml:"name_by_alias"`
+	// Mnemonics maps the names accounts refer to onto where the mnemonics are read from.
+	Mnemonics map[string]ManifestMnemonic `yaml:"mnemonics"`
+	Accounts  []ManifestAccount           `yaml:"accounts"`
+}
+
+// ManifestMnemonic tells where a mnemonic is read from, mnemonics are never written in the manifest itself.
+type ManifestMnemonic struct {
+	File string `yaml:"file"`
+	Env  string `yaml:"env"`
+}
+
+// ManifestAccount declares a single account, derived from a mnemonic at a path, or at an index of a scheme.
+type ManifestAccount struct {
+	Alias    string `yaml:"alias"`
+	Mnemonic string `yaml:"mnemonic"`
+	Path     string `yaml:"path"`
+	Index    *int   `yaml:"index"`
+	// Scheme is a derivation scheme name or template, see wallet.DerivationPath, bip44 by default.
+	Scheme      string           `yaml:"scheme"`
+	Password    ManifestPassword `yaml:"password"`
+	OnConflict  string           `yaml:"on_conflict"`
+	Tags        []string         `yaml:"tags"`
+	Environment string           `yaml:"environment"`
+}
+
+// ManifestPassword tells where the password of an account is read from. When empty, the password source given on
+// the command line is used.
+type ManifestPassword struct {
+	File    string `yaml:"file"`
+	Env     string `yaml:"env"`
+	Command string `yaml:"command"`
+	Keyring string `yaml:"keyring"`
+}
+
+// ReadManifest reads and validates a manifest. Relative paths in it are resolved against the manifest directory.
+func ReadManifest(path string) (*Manifest, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	manifest := &Manifest{}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(manifest); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", path, err)
+	}
+
+	base := filepath.Dir(path)
+	resolve := func(p string) string {
+		if p == "" || filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(base, p)
+	}
+	manifest.KeystoreDir = resolve(manifest.KeystoreDir)
+	for name, mnemonic := range manifest.Mnemonics {
+		mnemonic.File = resolve(mnemonic.File)
+		manifest.Mnemonics[name] = mnemonic
+	}
+	for i := range manifest.Accounts {
+		account := &manifest.Accounts[i]
+		account.Password.File = resolve(account.Password.File)
+		if account.OnConflict == "" {
+			account.OnConflict = ConflictKeep
+		}
+	}
+
+	if err := manifest.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", path, err)
+	}
+	return manifest, nil
+}
+
+// Validate checks that every account refers to a declared mnemonic and sets exactly one way of deriving it, and
+// that sources and policies are well formed.
+func (m *Manifest) Validate() error {
+	for name, mnemonic := range m.Mnemonics {
+		if (mnemonic.File == "") == (mnemonic.Env == "") {
+			return fmt.Errorf("mnemonic %q: exactly one of file or env must be set", name)
+		}
+	}
+
+	aliases := make(map[string]int)
+	for i, account := range m.Accounts {
+		position := fmt.Sprintf("account %d", i+1)
+		if account.Alias != "" {
+			position = fmt.Sprintf("account %d (%s)", i+1, account.Alias)
+			if err := ValidateAlias(account.Alias); err != nil {
+				return fmt.Errorf("%s: %w", position, err)
+			}
+			if first, ok := aliases[account.Alias]; ok {
+				return fmt.Errorf("%s: alias already used by account %d", position, first+1)
+			}
+			aliases[account.Alias] = i
+		}
+
+		if _, ok := m.Mnemonics[account.Mnemonic]; !ok {
+			return fmt.Errorf("%s: unknown mnemonic %q", position, account.Mnemonic)
+		}
+		if (account.Path == "") == (account.Index == nil) {
+			return fmt.Errorf("%s: exactly one of path or index must be set", position)
+		}
+		if account.Index != nil && *account.Index < 0 {
+			return fmt.Errorf("%s: index must not be negative", position)
+		}
+		if account.Scheme != "" && account.Index == nil {
+			return fmt.Errorf("%s: scheme can only be used with index", position)
+		}
+
+		switch account.OnConflict {
+		case ConflictKeep, ConflictReplace, ConflictFail:
+		default:
+			return fmt.Errorf("%s: invalid on_conflict %q, expected keep, replace or fail", position, account.OnConflict)
+		}
+
+		sources := 0
+		for _, source := range []string{account.Password.File, account.Password.Env, account.Password.Command, account.Password.Keyring} {
+			if source != "" {
+				sources++
+			}
+		}
+		if sources > 1 {
+			return fmt.Errorf("%s: only one password source can be set", position)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,370 @@
+package keystore
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Statuses of the accounts compared by Diff.
+const (
+	// DiffOnlyA means the account is only in the first directory.
+	DiffOnlyA = "only-a"
+	// DiffOnlyB means the account is only in the second directory.
+	DiffOnlyB = "only-b"
+	// DiffIdentical means both directories hold the same key file for the account.
+	DiffIdentical = "identical"
+	// DiffConflict means the key files of the account differ, see the entry reason.
+	DiffConflict = "conflict"
+	// DiffInvalid means a file couldn't be parsed as a key file.
+	DiffInvalid = "invalid"
+)
+
+// Statuses of the accounts of a merge or copy.
+const (
+	// MergeCopied means the key file was copied into the destination.
+	MergeCopied = "copied"
+	// MergeReplaced means the key file replaced a conflicting one in the destination.
+	MergeReplaced = "replaced"
+	// MergeIdentical means the same key file was already there, it's not copied again.
+	MergeIdentical = "identical"
+	// MergeConflict means the key file conflicts with the one already there, nothing is written.
+	MergeConflict = "conflict"
+	// MergeSkippedConflict means the key file conflicts with the one already there, which was kept.
+	MergeSkippedConflict = "skipped-conflict"
+	// MergeMissing means a requested address isn't in any source, nothing is written.
+	MergeMissing = "missing"
+	// MergeInvalid means a file of a source couldn't be parsed as a key file, it's ignored.
+	MergeInvalid = "invalid"
+)
+
+// Conflict policies of a merge, see MergeOptions.
+const (
+	MergeOnConflictFail    = "fail"
+	MergeOnConflictSkip    = "skip"
+	MergeOnConflictReplace = "replace"
+)
+
+var (
+	// ErrMergeConflict is returned when a merge can't be carried out, because of conflicting or missing accounts.
+	ErrMergeConflict = errors.New("conflicting or missing accounts")
+)
+
+// DiffEntry compares the key files of an account in two keystore directories.
+type DiffEntry struct {
+	Address common.Address
+	Status  string
+	PathA   string
+	PathB   string
+	Reason  string
+}
+
+// MergeResult describes what happens to a key file of a source during a merge or copy.
+type MergeResult struct {
+	Address common.Address
+	Status  string
+	// Source is the key file in a source directory, empty for missing accounts.
+	Source string
+	// Destination is the key file in the destination, existing or copied.
+	Destination string
+	Reason      string
+}
+
+// Written reports whether the key file is written into the destination.
+func (r MergeResult) Written() bool {
+	return r.Status == MergeCopied || r.Status == MergeReplaced
+}
+
+// MergeOptions configures a merge.
+type MergeOptions struct {
+	// Addresses restricts the merge to these accounts, all of which must be found in a source. Every account is
+	// merged when empty.
+	Addresses []common.Address
+	// OnConflict is fail, skip or replace. It applies to key files conflicting with the destination; between sources
+	// the first file found wins, unless the policy is fail.
+	OnConflict string
+	// DryRun only computes the results, without writing anything.
+	DryRun bool
+}
+
+// scannedKeyFile is a key file of a directory, with its raw content.
+type scannedKeyFile struct {
+	keyFile *KeyFile
+	content []byte
+	err     error
+	path    string
+}
+
+// scanKeyFiles reads every candidate key file of a directory, files which can't be parsed are returned with an error.
+func scanKeyFiles(dir string) ([]scannedKeyFile, error) {
+	paths, err := KeyFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	scanned := make([]scannedKeyFile, len(paths))
+	for i, path := range paths {
+		scanned[i].path = path
+		if scanned[i].content, err = os.ReadFile(path); err != nil {
+			scanned[i].err = fmt.Errorf("failed to read key file: %w", err)
+			continue
+		}
+		if scanned[i].keyFile, err = ParseKeyFile(scanned[i].content); err != nil {
+			scanned[i].err = err
+			continue
+		}
+		scanned[i].keyFile.Path = path
+	}
+	return scanned, nil
+}
+
+// Diff compares two keystore directories account by account. Entries are sorted by address, invalid files come
+// first.
+func Diff(dirA, dirB string) ([]DiffEntry, error) {
+	var entries []DiffEntry
+	byAddress := make(map[common.Address]*DiffEntry)
+	keyFiles := make(map[common.Address][2]*KeyFile)
+
+	for side, dir := range []string{dirA, dirB} {
+		scanned, err := scanKeyFiles(dir)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range scanned {
+			if file.err != nil {
+				entry := DiffEntry{Status: DiffInvalid, Reason: file.err.Error()}
+				if side == 0 {
+					entry.PathA = file.path
+				} else {
+					entry.PathB = file.path
+				}
+				entries = append(entries, entry)
+				continue
+			}
+
+			address := file.keyFile.Address
+			entry, ok := byAddress[address]
+			if !ok {
+				entry = &DiffEntry{Address: address}
+				byAddress[address] = entry
+			}
+			files := keyFiles[address]
+			if files[side] != nil {
+				// A directory holding several key files for an account conflicts with itself when they differ
+				if same, reason := files[side].Compare(file.keyFile); !same {
+					entry.Status = DiffConflict
+					entry.Reason = fmt.Sprintf("several key files in %s: %s", dir, reason)
+				}
+				continue
+			}
+			files[side] = file.keyFile
+			keyFiles[address] = files
+			if side == 0 {
+				entry.PathA = file.path
+			} else {
+				entry.PathB = file.path
+			}
+		}
+	}
+
+	addresses := make([]common.Address, 0, len(byAddress))
+	for address := range byAddress {
+		addresses = append(addresses, address)
+	}
+	sort.Slice(addresses, func(i, j int) bool {
+		return strings.ToLower(addresses[i].Hex()) < strings.ToLower(addresses[j].Hex())
+	})
+
+	for _, address := range addresses {
+		entry := byAddress[address]
+		files := keyFiles[address]
+		if entry.Status == "" {
+			switch {
+			case files[1] == nil:
+				entry.Status = DiffOnlyA
+			case files[0] == nil:
+				entry.Status = DiffOnlyB
+			default:
+				if same, reason := files[0].Compare(files[1]); same {
+					entry.Status = DiffIdentical
+				} else {
+					entry.Status = DiffConflict
+					entry.Reason = reason
+				}
+			}
+		}
+		entries = append(entries, *entry)
+	}
+
+	return entries, nil
+}
+
+// Merge copies the key files of the source directories into the keystore, deduplicating accounts by address. Key
+// files keep their names, unless another file of the keystore or another copied key file uses it, in which case they
+// get a geth-style name. Accounts already in the keystore with an identical key file are skipped, conflicting ones
+// are handled following the conflict policy. When an account conflicts under the fail policy, or a requested address
+// is missing, ErrMergeConflict is returned and nothing is written; otherwise every key file is written in a single
+// transaction.
+func (kst *KeystoreWrapper) Merge(sourceDirs []string, opts MergeOptions) ([]MergeResult, error) {
+	switch opts.OnConflict {
+	case MergeOnConflictFail, MergeOnConflictSkip, MergeOnConflictReplace:
+	default:
+		return nil, fmt.Errorf("invalid conflict policy %q: expected fail, skip or replace", opts.OnConflict)
+	}
+
+	requested := make(map[common.Address]bool, len(opts.Addresses))
+	for _, address := range opts.Addresses {
+		requested[address] = true
+	}
+
+	var results []MergeResult
+	chosen := make(map[common.Address]scannedKeyFile)
+	destinations := make(map[common.Address]string)
+	contents := make(map[common.Address][]byte)
+	names := make(map[string]bool)
+	failed := false
+
+	for _, dir := range sourceDirs {
+		if filepath.Clean(dir) == filepath.Clean(kst.dir) {
+			return nil, fmt.Errorf("source %s is the destination keystore", dir)
+		}
+		scanned, err := scanKeyFiles(dir)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range scanned {
+			if file.err != nil {
+				if len(requested) == 0 {
+					results = append(results, MergeResult{Status: MergeInvalid, Source: file.path, Reason: file.err.Error()})
+				}
+				continue
+			}
+			address := file.keyFile.Address
+			if len(requested) > 0 && !requested[address] {
+				continue
+			}
+			result := MergeResult{Address: address, Source: file.path}
+
+			// Another source already provided the account
+			if first, ok := chosen[address]; ok {
+				result.Destination = destinations[address]
+				if same, reason := first.keyFile.Compare(file.keyFile); same {
+					result.Status = MergeIdentical
+				} else {
+					result.Reason = fmt.Sprintf("%s than %s", reason, first.path)
+					result.Status = MergeSkippedConflict
+					if opts.OnConflict == MergeOnConflictFail {
+						result.Status = MergeConflict
+						failed = true
+					}
+				}
+				results = append(results, result)
+				continue
+			}
+			chosen[address] = file
+
+			existing, err := kst.Find(address)
+			if err != nil {
+				result.Status = MergeCopied
+				if result.Destination, err = kst.mergeDestination(address, file.path, "", names); err != nil {
+					return nil, err
+				}
+				contents[address] = file.content
+				destinations[address] = result.Destination
+				results = append(results, result)
+				continue
+			}
+
+			result.Destination = existing.URL.Path
+			existingKeyFile, err := ReadKeyFile(existing.URL.Path)
+			if err != nil {
+				result.Status, result.Reason = MergeConflict, fmt.Sprintf("existing key file is invalid: %v", err)
+				failed = true
+				destinations[address] = result.Destination
+				results = append(results, result)
+				continue
+			}
+			same, reason := existingKeyFile.Compare(file.keyFile)
+			switch {
+			case same:
+				result.Status = MergeIdentical
+			case opts.OnConflict == MergeOnConflictReplace:
+				result.Status, result.Reason = MergeReplaced, reason
+				if result.Destination, err = kst.mergeDestination(address, file.path, existing.URL.Path, names); err != nil {
+					return nil, err
+				}
+				contents[address] = file.content
+			case opts.OnConflict == MergeOnConflictSkip:
+				result.Status, result.Reason = MergeSkippedConflict, reason
+			default:
+				result.Status, result.Reason = MergeConflict, reason
+				failed = true
+			}
+			destinations[address] = result.Destination
+			results = append(results, result)
+		}
+	}
+
+	for _, address := range opts.Addresses {
+		if _, ok := chosen[address]; !ok {
+			results = append(results, MergeResult{Address: address, Status: MergeMissing, Reason: "not found in any source"})
+			failed = true
+		}
+	}
+
+	if failed {
+		return results, ErrMergeConflict
+	}
+	if opts.DryRun {
+		return results, nil
+	}
+
+	return results, kst.writeMerge(results, contents)
+}
+
+// mergeDestination returns the path a key file copied from source is written to, recording its name in names. It
+// keeps the name of source, unless a file of the keystore other than replaces, the key file of the account being
+// replaced, or another copied key file already uses it.
+func (kst *KeystoreWrapper) mergeDestination(address common.Address, source, replaces string, names map[string]bool) (string, error) {
+	name := filepath.Base(source)
+	taken := names[name]
+	if !taken && (replaces == "" || filepath.Base(replaces) != name) {
+		var err error
+		if taken, err = fileExists(filepath.Join(kst.dir, name)); err != nil {
+			return "", err
+		}
+	}
+	if taken {
+		name = keyFileName(address, "")
+	}
+	names[name] = true
+	return filepath.Join(kst.dir, name), nil
+}
+
+// writeMerge writes the key files to copy in a single transaction.
+func (kst *KeystoreWrapper) writeMerge(results []MergeResult, contents map[common.Address][]byte) error {
+	tx, err := kst.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, result := range results {
+		if !result.Written() {
+			continue
+		}
+		name := filepath.Base(result.Destination)
+		if _, _, err := tx.WriteKeyFile(result.Address, name, contents[result.Address], result.Status == MergeReplaced); err != nil {
+			return fmt.Errorf("failed to copy key file %s: %w", result.Source, err)
+		}
+	}
+
+	return tx.Commit()
+}
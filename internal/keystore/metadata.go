@@ -0,0 +1,244 @@
+package keystore
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// MetadataFileName is the name of the sidecar file holding account metadata in the keystore directory. geth skips
+// it when scanning the directory, as it holds no address.
+const MetadataFileName = "ethw-meta.json"
+
+// Lifecycle states of an account, accounts without a state are active.
+const (
+	StateActive      = "active"
+	StateRetired     = "retired"
+	StateCompromised = "compromised"
+)
+
+// metadataVersion is the version of the metadata file format.
+const metadataVersion = 1
+
+var (
+	// reAlias matches aliases usable in key file names, e.g. faucet or deployer-1. Aliases start with a letter so
+	// they can't be mistaken for an address.
+	reAlias = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_.-]*$`)
+	// reTag matches tags, e.g. ci or team:infra.
+	reTag = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.:/-]*$`)
+)
+
+// SourceMnemonic is the type of derivation sources for keys derived from a mnemonic.
+const SourceMnemonic = "mnemonic"
+
+// DerivationSource records where the key of an account comes from.
+type DerivationSource struct {
+	// Type is SourceMnemonic for keys derived from a mnemonic.
+	Type string `json:"type"`
+	// Mnemonic is the name of the mnemonic in a manifest, mnemonics themselves are never stored.
+	Mnemonic string `json:"mnemonic,omitempty"`
+	Path     string `json:"path,omitempty"`
+}
+
+// AccountMetadata is what ethw knows about an account besides its key file.
+type AccountMetadata struct {
+	Alias       string            `json:"alias,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	Environment string            `json:"environment,omitempty"`
+	CreatedBy   string            `json:"created_by,omitempty"`
+	Source      *DerivationSource `json:"source,omitempty"`
+	State       string            `json:"state,omitempty"`
+}
+
+// LifecycleState returns the state of the account, active when none was set.
+func (m AccountMetadata) LifecycleState() string {
+	if m.State == "" {
+		return StateActive
+	}
+	return m.State
+}
+
+// HasTag reports whether the account carries the tag.
+func (m AccountMetadata) HasTag(tag string) bool {
+	for _, t := range m.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// AddTags adds the tags the account doesn't carry yet, keeping tags sorted.
+func (m *AccountMetadata) AddTags(tags ...string) {
+	for _, tag := range tags {
+		if !m.HasTag(tag) {
+			m.Tags = append(m.Tags, tag)
+		}
+	}
+	sort.Strings(m.Tags)
+}
+
+// RemoveTags removes the given tags from the account.
+func (m *AccountMetadata) RemoveTags(tags ...string) {
+	kept := m.Tags[:0]
+	for _, t := range m.Tags {
+		remove := false
+		for _, tag := range tags {
+			remove = remove || t == tag
+		}
+		if !remove {
+			kept = append(kept, t)
+		}
+	}
+	m.Tags = kept
+	if len(m.Tags) == 0 {
+		m.Tags = nil
+	}
+}
+
+// Validate checks the alias, tags and state of the account.
+func (m AccountMetadata) Validate() error {
+	if err := ValidateAlias(m.Alias); m.Alias != "" && err != nil {
+		return err
+	}
+	for _, tag := range m.Tags {
+		if !reTag.MatchString(tag) {
+			return fmt.Errorf("invalid tag %q: expected letters, digits and _.:/-", tag)
+		}
+	}
+	switch m.State {
+	case "", StateActive, StateRetired, StateCompromised:
+	default:
+		return fmt.Errorf("invalid state %q: expected active, retired or compromised", m.State)
+	}
+	return nil
+}
+
+// ValidateAlias checks that an alias starts with a letter and only holds letters, digits and _.-, without "--" as it
+// separates the parts of key file names.
+func ValidateAlias(alias string) error {
+	if !reAlias.MatchString(alias) || strings.Contains(alias, "--") {
+		return fmt.Errorf("invalid alias %q: expected a letter followed by letters, digits and _.- (without --)", alias)
+	}
+	return nil
+}
+
+// Metadata is the content of the metadata sidecar file of a keystore.
+type Metadata struct {
+	path     string
+	Version  int                                `json:"version"`
+	Accounts map[common.Address]AccountMetadata `json:"accounts"`
+}
+
+// ReadMetadata reads the metadata sidecar file of the keystore directory, a missing file holds no metadata.
+func ReadMetadata(dir string) (*Metadata, error) {
+	md := &Metadata{
+		path:     filepath.Join(dir, MetadataFileName),
+		Version:  metadataVersion,
+		Accounts: make(map[common.Address]AccountMetadata),
+	}
+
+	content, err := os.ReadFile(md.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return md, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata: %w", err)
+	}
+	if err := json.Unmarshal(content, md); err != nil {
+		return nil, fmt.Errorf("failed to parse metadata %s: %w", md.path, err)
+	}
+	if md.Version != metadataVersion {
+		return nil, fmt.Errorf("unsupported metadata version %d in %s", md.Version, md.path)
+	}
+	if md.Accounts == nil {
+		md.Accounts = make(map[common.Address]AccountMetadata)
+	}
+	return md, nil
+}
+
+// Metadata reads the metadata sidecar file of the keystore.
+func (kst *KeystoreWrapper) Metadata() (*Metadata, error) {
+	return ReadMetadata(kst.dir)
+}
+
+// Get returns the metadata of the account, empty if there's none.
+func (md *Metadata) Get(address common.Address) AccountMetadata {
+	return md.Accounts[address]
+}
+
+// Update changes the metadata of the account, checking the result is valid and its alias isn't used by another
+// account. Nothing is changed on error.
+func (md *Metadata) Update(address common.Address, update func(*AccountMetadata)) error {
+	meta := md.Get(address)
+	meta.Tags = append([]string(nil), meta.Tags...)
+	update(&meta)
+
+	if err := meta.Validate(); err != nil {
+		return fmt.Errorf("account %s: %w", address.Hex(), err)
+	}
+	if meta.Alias != "" {
+		if other, ok := md.FindAlias(meta.Alias); ok && other != address {
+			return fmt.Errorf("account %s: alias %q is already used by %s", address.Hex(), meta.Alias, other.Hex())
+		}
+	}
+
+	md.Accounts[address] = meta
+	return nil
+}
+
+// Delete drops the metadata of the account.
+func (md *Metadata) Delete(address common.Address) {
+	delete(md.Accounts, address)
+}
+
+// FindAlias returns the account with the given alias.
+func (md *Metadata) FindAlias(alias string) (common.Address, bool) {
+	for address, meta := range md.Accounts {
+		if meta.Alias == alias {
+			return address, true
+		}
+	}
+	return common.Address{}, false
+}
+
+// Resolve returns the account designated by an address or an alias.
+func (md *Metadata) Resolve(ref string) (common.Address, error) {
+	if common.IsHexAddress(ref) {
+		return common.HexToAddress(ref), nil
+	}
+	if address, ok := md.FindAlias(ref); ok {
+		return address, nil
+	}
+	return common.Address{}, fmt.Errorf("no account with address or alias %q", ref)
+}
+
+// Save writes the metadata file atomically, leaving it untouched when nothing changed and not creating it without
+// any metadata to hold.
+func (md *Metadata) Save() error {
+	content, err := json.MarshalIndent(md, "", "  ")
+	if err != nil {
+		return err
+	}
+	content = append(content, '\n')
+
+	existing, err := os.ReadFile(md.path)
+	if err == nil && bytes.Equal(existing, content) {
+		return nil
+	}
+	if errors.Is(err, os.ErrNotExist) && len(md.Accounts) == 0 {
+		return nil
+	}
+	if err := writeKeyFile(md.path, content); err != nil {
+		return fmt.Errorf("failed to write metadata: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package keystore
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Actions of a plan entry, reconciling the keystore with the accounts of a manifest.
+const (
+	// ActionCreate writes the key file of a declared account missing from the keystore.
+	ActionCreate = "create"
+	// ActionKeep leaves the key file of a declared account as it is.
+	ActionKeep = "keep"
+	// ActionReplace rewrites the key file of a declared account already in the keystore.
+	ActionReplace = "replace"
+	// ActionRemoveExtra removes the key file of an account the manifest doesn't declare.
+	ActionRemoveExtra = "remove-extra"
+	// ActionConflict means the account can't be reconciled, see the entry error. A plan with conflicts isn't applied.
+	ActionConflict = "conflict"
+)
+
+// DesiredAccount is an account declared in a manifest, once derived from its mnemonic.
+type DesiredAccount struct {
+	Alias          string
+	Address        common.Address
+	DerivationPath string
+	OnConflict     string
+}
+
+// PlanEntry is a single step of a plan.
+type PlanEntry struct {
+	Action string
+	// Index is the zero based position of the account in the manifest, -1 for accounts only found in the keystore.
+	Index          int
+	Alias          string
+	Address        common.Address
+	DerivationPath string
+	KeyFile        string
+	Err            error
+}
+
+// ErrorMessage returns the error message of a conflicting entry, or an empty string.
+func (e PlanEntry) ErrorMessage() string {
+	if e.Err == nil {
+		return ""
+	}
+	return e.Err.Error()
+}
+
+// Plan computes the steps reconciling the keystore with the desired accounts: missing accounts are created, present
+// ones are kept or replaced following their conflict policy and, with removeExtra set, the accounts nobody declared
+// are removed. Entries follow the order of desired, extra accounts come last.
+func (kst *KeystoreWrapper) Plan(desired []DesiredAccount, removeExtra bool) []PlanEntry {
+	plan := make([]PlanEntry, 0, len(desired))
+	declared := make(map[common.Address]int, len(desired))
+
+	for i, account := range desired {
+		entry := PlanEntry{
+			Index:          i,
+			Alias:          account.Alias,
+			Address:        account.Address,
+			DerivationPath: account.DerivationPath,
+		}
+
+		if first, ok := declared[account.Address]; ok {
+			entry.Action = ActionConflict
+			entry.Err = fmt.Errorf("same address as account %d", first+1)
+			plan = append(plan, entry)
+			continue
+		}
+		declared[account.Address] = i
+
+		existing, err := kst.Find(account.Address)
+		if err != nil {
+			entry.Action = ActionCreate
+			plan = append(plan, entry)
+			continue
+		}
+
+		entry.KeyFile = existing.URL.Path
+		switch account.OnConflict {
+		case ConflictReplace:
+			entry.Action = ActionReplace
+		case ConflictFail:
+			entry.Action = ActionConflict
+			entry.Err = ErrAccountExists
+		default:
+			entry.Action = ActionKeep
+		}
+		plan = append(plan, entry)
+	}
+
+	if removeExtra {
+		for _, account := range kst.Accounts() {
+			if _, ok := declared[account.Address]; ok {
+				continue
+			}
+			plan = append(plan, PlanEntry{
+				Action:  ActionRemoveExtra,
+				Index:   -1,
+				Address: account.Address,
+				KeyFile: account.URL.Path,
+			})
+		}
+	}
+
+	return plan
+}
+
+// PlanChanges returns the number of entries of the plan which change the keystore.
+func PlanChanges(plan []PlanEntry) int {
+	changes := 0
+	for _, entry := range plan {
+		if entry.Action != ActionKeep && entry.Action != ActionConflict {
+			changes++
+		}
+	}
+	return changes
+}
@@ -0,0 +1,90 @@
+package keystore
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Statuses of the accounts of a restore.
+const (
+	// RestoreMissing means the derived account has no key file, it's only reported by dry runs.
+	RestoreMissing = "missing"
+	// RestoreRestored means the key file of the derived account was written.
+	RestoreRestored = "restored"
+	// RestoreMatching means the existing key file of the derived account passed verification.
+	RestoreMatching = "matching"
+	// RestoreMismatch means the existing key file of the derived account failed verification, see the entry reason.
+	RestoreMismatch = "mismatch"
+	// RestoreUnexpected means the account isn't derivable from the mnemonic within the restored range.
+	RestoreUnexpected = "unexpected"
+	// RestoreInvalid means a file couldn't be parsed as a key file.
+	RestoreInvalid = "invalid"
+)
+
+// RestoreEntry describes an account of a restored keystore.
+type RestoreEntry struct {
+	Status string
+	// Index is the derivation index of the account, -1 for unexpected accounts and invalid files.
+	Index          int
+	Address        common.Address
+	DerivationPath string
+	KeyFile        string
+	Reason         string
+}
+
+// Derived reports whether the account is derived from the mnemonic.
+func (e RestoreEntry) Derived() bool {
+	return e.Index >= 0
+}
+
+// RestorePlan compares the keystore with the accounts derived at consecutive indexes starting at firstIndex.
+// Derived accounts are missing or have a key file to verify, reported as matching, and the accounts and files found
+// besides them are unexpected or invalid. Entries follow the order of derived, the others come last.
+func (kst *KeystoreWrapper) RestorePlan(derived []DesiredAccount, firstIndex int) ([]RestoreEntry, error) {
+	plan := kst.Plan(derived, true)
+	entries := make([]RestoreEntry, 0, len(plan))
+	known := make(map[string]bool, len(plan))
+
+	for _, entry := range plan {
+		restore := RestoreEntry{
+			Index:          -1,
+			Address:        entry.Address,
+			DerivationPath: entry.DerivationPath,
+			KeyFile:        entry.KeyFile,
+		}
+		switch entry.Action {
+		case ActionCreate:
+			restore.Status = RestoreMissing
+		case ActionKeep:
+			restore.Status = RestoreMatching
+		case ActionRemoveExtra:
+			restore.Status = RestoreUnexpected
+			restore.Reason = fmt.Sprintf("not derived from the mnemonic at indexes %d-%d", firstIndex, firstIndex+len(derived)-1)
+		default:
+			restore.Status = RestoreMismatch
+			restore.Reason = entry.ErrorMessage()
+		}
+		if entry.Index >= 0 {
+			restore.Index = firstIndex + entry.Index
+		}
+		known[restore.KeyFile] = true
+		entries = append(entries, restore)
+	}
+
+	// geth ignores files it can't parse, they are flagged as they may be damaged key files. The directory of a lost
+	// keystore may not exist yet
+	scanned, err := scanKeyFiles(kst.dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	for _, file := range scanned {
+		if file.err != nil && !known[file.path] {
+			entries = append(entries, RestoreEntry{Status: RestoreInvalid, Index: -1, KeyFile: file.path, Reason: file.err.Error()})
+		}
+	}
+
+	return entries, nil
+}
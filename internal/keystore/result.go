@@ -0,0 +1,52 @@
+package keystore
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// CreateStatus is the outcome of creating the key file of a single wallet.
+type CreateStatus string
+
+const (
+	// StatusCreated means a new key file was written.
+	StatusCreated CreateStatus = "created"
+	// StatusSkippedExisting means the account was already present and left untouched.
+	StatusSkippedExisting CreateStatus = "skipped-existing"
+	// StatusReplaced means the account was already present and its key file was rewritten.
+	StatusReplaced CreateStatus = "replaced"
+	// StatusFailed means the key file couldn't be written, see the result error.
+	StatusFailed CreateStatus = "failed"
+	// StatusRolledBack means the key file wasn't kept, because another wallet failed or the run was interrupted.
+	StatusRolledBack CreateStatus = "rolled-back"
+)
+
+// CreateResult describes what happened to a single wallet during keystore creation.
+type CreateResult struct {
+	// Index is the zero based position of the wallet in the command arguments.
+	Index          int
+	Status         CreateStatus
+	Address        common.Address
+	DerivationPath string
+	KeyFile        string
+	// Metadata is the metadata of the account in the keystore, only the alias of the wallet spec if it isn't recorded.
+	Metadata AccountMetadata
+	Err      error
+}
+
+// Succeeded reports whether the account is present in the keystore after the operation.
+func (r CreateResult) Succeeded() bool {
+	return r.Status != StatusFailed && r.Status != StatusRolledBack
+}
+
+// Written reports whether a key file was written for the account with the password of this run.
+func (r CreateResult) Written() bool {
+	return r.Status == StatusCreated || r.Status == StatusReplaced
+}
+
+// ErrorMessage returns the error message of a failed result, or an empty string.
+func (r CreateResult) ErrorMessage() string {
+	if r.Err == nil {
+		return ""
+	}
+	return r.Err.Error()
+}
@@ -0,0 +1,246 @@
+package keystore
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	k "github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Prefixes of the hidden directories a transaction keeps inside the keystore directory, geth ignores them.
+const (
+	stagingDirPrefix = ".ethw-staging-"
+	backupDirPrefix  = ".ethw-backup-"
+)
+
+var (
+	// ErrTransactionDone is returned when using a transaction which was already committed or rolled back.
+	ErrTransactionDone = errors.New("keystore transaction already committed or rolled back")
+	// ErrKeyFileNameTaken is returned when staging a key file under the name of a file the transaction doesn't replace.
+	ErrKeyFileNameTaken = errors.New("key file name already used by another file")
+)
+
+// stagedKey is a key file written to the staging directory, waiting to be moved into the keystore.
+type stagedKey struct {
+	name     string
+	replaces string
+}
+
+// Transaction writes key files to a staging directory and only moves them into the keystore on Commit, so that a
+// failed or interrupted run leaves the keystore untouched. Files are fsynced and renamed into place, the staging
+// directory lives inside the keystore directory to keep renames atomic.
+type Transaction struct {
+	kst     *KeystoreWrapper
+	staging string
+
+	mu      sync.Mutex
+	staged  []stagedKey
+	removed []string
+	done    bool
+}
+
+// Begin starts a transaction on the keystore.
+func (kst *KeystoreWrapper) Begin() (*Transaction, error) {
+	if err := os.MkdirAll(kst.dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create keystore directory: %w", err)
+	}
+	staging, err := os.MkdirTemp(kst.dir, stagingDirPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	return &Transaction{kst: kst, staging: staging}, nil
+}
+
+// WriteKey stages an already encrypted key file (see EncryptKey) for the address, its file name being prefixed with
+// the alias when one is given (see ValidateAlias). When replace is set, the existing key file of the address is
+// removed on commit; without it, staging a key for an existing address fails. It's safe for concurrent use, so keys
+// can be encrypted in parallel. It returns the account as it will be after the commit, and the path of the staged
+// key file.
+func (tx *Transaction) WriteKey(address common.Address, alias string, keyJSON []byte, replace bool) (accounts.Account, string, error) {
+	if alias != "" {
+		if err := ValidateAlias(alias); err != nil {
+			return accounts.Account{}, "", err
+		}
+	}
+	return tx.WriteKeyFile(address, keyFileName(address, alias), keyJSON, replace)
+}
+
+// WriteKeyFile stages a key file for the address like WriteKey, under the given file name, e.g. to keep the name of
+// a key file copied from another keystore. The name must not be used by a file of the keystore which isn't replaced
+// or removed by the transaction, such as the key file of another account.
+func (tx *Transaction) WriteKeyFile(address common.Address, name string, keyJSON []byte, replace bool) (accounts.Account, string, error) {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	if tx.done {
+		return accounts.Account{}, "", ErrTransactionDone
+	}
+
+	if name != filepath.Base(name) || strings.HasPrefix(name, ".") || name == MetadataFileName {
+		return accounts.Account{}, "", fmt.Errorf("invalid key file name %q", name)
+	}
+	for _, staged := range tx.staged {
+		if staged.name == name {
+			return accounts.Account{}, "", fmt.Errorf("key file %s is already staged", name)
+		}
+	}
+
+	staged := stagedKey{name: name}
+	if existing, err := tx.kst.ks.Find(accounts.Account{Address: address}); err == nil {
+		if !replace {
+			return accounts.Account{}, "", ErrAccountExists
+		}
+		staged.replaces = existing.URL.Path
+	}
+	if taken, err := tx.nameTaken(name, staged.replaces); err != nil {
+		return accounts.Account{}, "", err
+	} else if taken {
+		return accounts.Account{}, "", fmt.Errorf("%w: %s", ErrKeyFileNameTaken, name)
+	}
+
+	stagedPath := filepath.Join(tx.staging, staged.name)
+	if err := writeKeyFile(stagedPath, keyJSON); err != nil {
+		return accounts.Account{}, "", fmt.Errorf("failed to write key file: %w", err)
+	}
+	tx.staged = append(tx.staged, staged)
+
+	account := accounts.Account{Address: address, URL: accounts.URL{Scheme: k.KeyStoreScheme, Path: filepath.Join(tx.kst.dir, staged.name)}}
+	return account, stagedPath, nil
+}
+
+// nameTaken reports whether a file named name exists in the keystore directory and isn't moved aside on commit,
+// either as the file replaced by the staged key or as a removed one.
+func (tx *Transaction) nameTaken(name, replaces string) (bool, error) {
+	if replaces != "" && filepath.Base(replaces) == name {
+		return false, nil
+	}
+	for _, removed := range tx.removed {
+		if filepath.Base(removed) == name {
+			return false, nil
+		}
+	}
+	return fileExists(filepath.Join(tx.kst.dir, name))
+}
+
+// fileExists reports whether a file, or anything else, exists at path.
+func fileExists(path string) (bool, error) {
+	if _, err := os.Lstat(path); err == nil {
+		return true, nil
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return false, err
+	}
+	return false, nil
+}
+
+// Remove removes the key file of the account from the keystore on commit.
+func (tx *Transaction) Remove(account accounts.Account) error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	if tx.done {
+		return ErrTransactionDone
+	}
+
+	if filepath.Dir(account.URL.Path) != filepath.Clean(tx.kst.dir) {
+		return fmt.Errorf("key file %s is not in the keystore directory", account.URL.Path)
+	}
+	tx.removed = append(tx.removed, account.URL.Path)
+	return nil
+}
+
+// Commit moves the staged key files into the keystore, removing the files they replace and the removed ones.
+// Replaced files are first moved aside, and put back if any step fails, so the keystore ends up either fully updated
+// or as it was. A staged key file is never moved over a file which wasn't moved aside, since it couldn't be restored.
+func (tx *Transaction) Commit() (err error) {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	if tx.done {
+		return ErrTransactionDone
+	}
+	tx.done = true
+	defer os.RemoveAll(tx.staging)
+
+	dir := tx.kst.dir
+	if err := syncDir(tx.staging); err != nil {
+		return fmt.Errorf("failed to sync staging directory: %w", err)
+	}
+
+	backup, err := os.MkdirTemp(dir, backupDirPrefix)
+	if err != nil {
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	// Every rename is recorded so it can be undone in reverse order
+	type move struct{ from, to string }
+	var moves []move
+	rename := func(from, to string) error {
+		if err := os.Rename(from, to); err != nil {
+			return err
+		}
+		moves = append(moves, move{from, to})
+		return nil
+	}
+	defer func() {
+		if err != nil {
+			for i := len(moves) - 1; i >= 0; i-- {
+				if undoErr := os.Rename(moves[i].to, moves[i].from); undoErr != nil {
+					err = fmt.Errorf("%w, and failed to restore %s from %s: %v", err, moves[i].from, moves[i].to, undoErr)
+					return
+				}
+			}
+		}
+		os.RemoveAll(backup)
+		tx.kst.reload()
+	}()
+
+	replaced := append([]string(nil), tx.removed...)
+	for _, staged := range tx.staged {
+		if staged.replaces != "" {
+			replaced = append(replaced, staged.replaces)
+		}
+	}
+
+	for i, path := range replaced {
+		if err := rename(path, filepath.Join(backup, fmt.Sprintf("%d-%s", i, filepath.Base(path)))); err != nil {
+			return fmt.Errorf("failed to move aside %s: %w", path, err)
+		}
+	}
+	for _, staged := range tx.staged {
+		target := filepath.Join(dir, staged.name)
+		// The file may have appeared since the key was staged
+		if exists, err := fileExists(target); err != nil {
+			return fmt.Errorf("failed to commit key file %s: %w", staged.name, err)
+		} else if exists {
+			return fmt.Errorf("failed to commit key file %s: %w", staged.name, ErrKeyFileNameTaken)
+		}
+		if err := rename(filepath.Join(tx.staging, staged.name), target); err != nil {
+			return fmt.Errorf("failed to commit key file %s: %w", staged.name, err)
+		}
+	}
+	if err := syncDir(dir); err != nil {
+		return fmt.Errorf("failed to sync keystore directory: %w", err)
+	}
+
+	return nil
+}
+
+// Rollback discards the staged key files, leaving the keystore untouched. Rolling back a committed transaction does
+// nothing, so it can be deferred.
+func (tx *Transaction) Rollback() error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	if tx.done {
+		return nil
+	}
+	tx.done = true
+
+	if err := os.RemoveAll(tx.staging); err != nil {
+		return fmt.Errorf("failed to remove staging directory: %w", err)
+	}
+	return nil
+}
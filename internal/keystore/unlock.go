@@ -0,0 +1,35 @@
+package keystore
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// GethUnlock describes the accounts geth has to unlock on startup, in the order expected by its
+// --unlock flag and matching the lines of the file given to its --password flag.
+type GethUnlock struct {
+	Addresses    []common.Address `json:"addresses"`
+	PasswordFile string           `json:"password_file,omitempty"`
+}
+
+// UnlockList returns the comma separated address list for geth's --unlock flag.
+func (u GethUnlock) UnlockList() string {
+	addresses := make([]string, len(u.Addresses))
+	for i, address := range u.Addresses {
+		addresses[i] = address.Hex()
+	}
+	return strings.Join(addresses, ",")
+}
+
+// Flags returns the geth flags that unlock the accounts, or an empty string if there is nothing to unlock.
+func (u GethUnlock) Flags() string {
+	if len(u.Addresses) == 0 {
+		return ""
+	}
+	if u.PasswordFile == "" {
+		return fmt.Sprintf("--unlock=%s", u.UnlockList())
+	}
+	return fmt.Sprintf("--unlock=%s --password=%s", u.UnlockList(), u.PasswordFile)
+}
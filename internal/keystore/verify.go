@@ -0,0 +1,216 @@
+package keystore
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/aldoborrero/ethw/internal/wallet"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// CheckStatus is the outcome of a single key file check.
+type CheckStatus string
+
+const (
+	CheckOK      CheckStatus = "ok"
+	CheckFailed  CheckStatus = "failed"
+	CheckSkipped CheckStatus = "skipped"
+)
+
+// Names of the checks run by VerifyKeyFile, in order.
+const (
+	CheckJSON     = "json"
+	CheckFilename = "filename"
+	CheckMAC      = "mac"
+	CheckDecrypt  = "decrypt"
+	CheckAddress  = "address"
+	CheckMnemonic = "mnemonic"
+)
+
+// Check is the result of a single check run against a key file.
+type Check struct {
+	Name    string      `json:"name"`
+	Status  CheckStatus `json:"status"`
+	Message string      `json:"message,omitempty"`
+}
+
+// VerifyOptions configures the checks run by VerifyKeyFile.
+type VerifyOptions struct {
+	// Password is used to validate the MAC and decrypt the key, these checks are skipped without it.
+	Password    string
+	HasPassword bool
+	// Derivable maps the addresses derivable from a mnemonic to their derivation path. When set, every
+	// key file has to be one of them.
+	Derivable map[common.Address]string
+	// Mnemonic and DerivationPath, when set, derive the key the key file has to hold again, instead of looking
+	// its address up in Derivable.
+	Mnemonic       string
+	DerivationPath string
+}
+
+// VerifyResult holds the outcome of every check run against a key file.
+type VerifyResult struct {
+	Path           string
+	Address        common.Address
+	DerivationPath string
+	Checks         []Check
+}
+
+// OK reports whether none of the checks failed.
+func (r VerifyResult) OK() bool {
+	for _, check := range r.Checks {
+		if check.Status == CheckFailed {
+			return false
+		}
+	}
+	return true
+}
+
+// Failures returns the messages of the failed checks.
+func (r VerifyResult) Failures() []string {
+	var failures []string
+	for _, check := range r.Checks {
+		if check.Status == CheckFailed {
+			failures = append(failures, fmt.Sprintf("%s: %s", check.Name, check.Message))
+		}
+	}
+	return failures
+}
+
+// Status returns the status of the named check, or an empty string if it didn't run.
+func (r VerifyResult) Status(name string) CheckStatus {
+	for _, check := range r.Checks {
+		if check.Name == name {
+			return check.Status
+		}
+	}
+	return ""
+}
+
+func (r *VerifyResult) add(name string, status CheckStatus, format string, args ...interface{}) {
+	r.Checks = append(r.Checks, Check{Name: name, Status: status, Message: fmt.Sprintf(format, args...)})
+}
+
+// skipRemaining marks every check that didn't run yet as skipped.
+func (r *VerifyResult) skipRemaining(reason string, names ...string) {
+	for _, name := range names {
+		r.add(name, CheckSkipped, "%s", reason)
+	}
+}
+
+// VerifyKeyFile checks that the key file is well formed, that its name matches its address and, when a password
+// is given, that the MAC is valid and the decrypted key matches the address. It optionally confirms the account
+// is derivable from a mnemonic.
+func VerifyKeyFile(path string, opts VerifyOptions) VerifyResult {
+	result := VerifyResult{Path: path}
+
+	keyFile, err := ReadKeyFile(path)
+	if err != nil {
+		result.add(CheckJSON, CheckFailed, "%v", err)
+		result.skipRemaining("key file could not be parsed", CheckFilename, CheckMAC, CheckDecrypt, CheckAddress, CheckMnemonic)
+		return result
+	}
+	result.Address = keyFile.Address
+	result.add(CheckJSON, CheckOK, "")
+
+	addressHex := hex.EncodeToString(keyFile.Address[:])
+	if strings.HasSuffix(strings.ToLower(filepath.Base(path)), addressHex) {
+		result.add(CheckFilename, CheckOK, "")
+	} else {
+		result.add(CheckFilename, CheckFailed, "file name does not end with address %s", addressHex)
+	}
+
+	if opts.HasPassword {
+		verifyDecryption(&result, keyFile, opts.Password)
+	} else {
+		result.skipRemaining("no password given", CheckMAC, CheckDecrypt, CheckAddress)
+	}
+
+	switch {
+	case opts.Mnemonic != "":
+		verifyDerivation(&result, keyFile, opts.Mnemonic, opts.DerivationPath)
+	case opts.Derivable == nil:
+		result.skipRemaining("no mnemonic given", CheckMnemonic)
+	default:
+		if derivationPath, ok := opts.Derivable[keyFile.Address]; ok {
+			result.DerivationPath = derivationPath
+			result.add(CheckMnemonic, CheckOK, "")
+		} else {
+			result.add(CheckMnemonic, CheckFailed, "address is not derivable from the mnemonic in the given range")
+		}
+	}
+
+	return result
+}
+
+// verifyDerivation derives the key at the derivation path of the mnemonic and checks the key file holds it.
+func verifyDerivation(result *VerifyResult, keyFile *KeyFile, mnemonic, derivationPath string) {
+	w, err := wallet.NewWallet(mnemonic, "", derivationPath)
+	if err != nil {
+		result.add(CheckMnemonic, CheckFailed, "%v", err)
+		return
+	}
+	if address := common.HexToAddress(w.Address); address != keyFile.Address {
+		result.add(CheckMnemonic, CheckFailed, "the mnemonic derives %s at %s", address.Hex(), w.DerivationPath)
+		return
+	}
+	result.DerivationPath = w.DerivationPath
+	result.add(CheckMnemonic, CheckOK, "")
+}
+
+// verifyDecryption runs the MAC, decryption and address checks with the given password.
+func verifyDecryption(result *VerifyResult, keyFile *KeyFile, password string) {
+	derivedKey, err := keyFile.DeriveKey(password)
+	if err != nil {
+		result.add(CheckMAC, CheckFailed, "failed to derive key: %v", err)
+		result.skipRemaining("key could not be derived", CheckDecrypt, CheckAddress)
+		return
+	}
+
+	if err := keyFile.VerifyMAC(derivedKey); err != nil {
+		result.add(CheckMAC, CheckFailed, "wrong password or corrupt ciphertext")
+		result.skipRemaining("MAC is invalid", CheckDecrypt, CheckAddress)
+		return
+	}
+	result.add(CheckMAC, CheckOK, "")
+
+	key, err := keyFile.DecryptWithKey(derivedKey)
+	if err != nil {
+		result.add(CheckDecrypt, CheckFailed, "%v", err)
+		result.skipRemaining("key could not be decrypted", CheckAddress)
+		return
+	}
+	result.add(CheckDecrypt, CheckOK, "")
+
+	if address := crypto.PubkeyToAddress(key.PublicKey); address != keyFile.Address {
+		result.add(CheckAddress, CheckFailed, "decrypted key belongs to %s", address.Hex())
+		return
+	}
+	result.add(CheckAddress, CheckOK, "")
+}
+
+// KeyFiles returns the paths of the candidate key files of a directory, sorted by name. Like geth, it ignores
+// directories, hidden files, editor backups and the metadata sidecar file.
+func KeyFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore directory: %w", err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || strings.HasSuffix(name, "~") || name == "README" || name == MetadataFileName {
+			continue
+		}
+		files = append(files, filepath.Join(dir, name))
+	}
+	sort.Strings(files)
+
+	return files, nil
+}
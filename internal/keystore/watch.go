@@ -0,0 +1,74 @@
+package keystore
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Types of the events reported by Watch.
+const (
+	WatchEventArrived = "arrived"
+	WatchEventDropped = "dropped"
+)
+
+// WatchEvent reports a key file added to or removed from the keystore directory.
+type WatchEvent struct {
+	Type    string
+	Address common.Address
+	Path    string
+	Time    time.Time
+}
+
+// Watch reports accounts arriving in and dropping from the keystore directory to handle, until the context is done or
+// handle fails. With existing set, accounts already present are first reported as arrived. geth detects changes with
+// a file system watcher where available and by rescanning the directory every few seconds otherwise.
+func (kst *KeystoreWrapper) Watch(ctx context.Context, existing bool, handle func(WatchEvent) error) error {
+	// Events are relative to the wallets known when subscribing, take a snapshot first so none is reported twice
+	wallets := kst.ks.Wallets()
+
+	sink := make(chan accounts.WalletEvent, 64)
+	sub := kst.ks.Subscribe(sink)
+	defer sub.Unsubscribe()
+
+	if existing {
+		for _, wallet := range wallets {
+			if err := handle(newWatchEvent(WatchEventArrived, wallet)); err != nil {
+				return err
+			}
+		}
+	}
+
+	for {
+		select {
+		case event := <-sink:
+			var eventType string
+			switch event.Kind {
+			case accounts.WalletArrived:
+				eventType = WatchEventArrived
+			case accounts.WalletDropped:
+				eventType = WatchEventDropped
+			default:
+				continue
+			}
+			if err := handle(newWatchEvent(eventType, event.Wallet)); err != nil {
+				return err
+			}
+		case err := <-sub.Err():
+			return err
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// newWatchEvent returns the event of the given type for a keystore wallet, which always holds a single account.
+func newWatchEvent(eventType string, wallet accounts.Wallet) WatchEvent {
+	event := WatchEvent{Type: eventType, Path: wallet.URL().Path, Time: time.Now().UTC()}
+	if walletAccounts := wallet.Accounts(); len(walletAccounts) > 0 {
+		event.Address = walletAccounts[0].Address
+	}
+	return event
+}
@@ -0,0 +1,80 @@
+package password
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// DefaultGeneratedLength is the length of generated passwords, ~190 bits of entropy with the alphanumeric charset.
+	DefaultGeneratedLength = 32
+
+	// generatedCharset only includes characters which are safe to store in a password file and pass around in shells.
+	generatedCharset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+)
+
+// Generate returns a random alphanumeric password of the given length.
+func Generate(length int) (string, error) {
+	if length <= 0 {
+		return "", fmt.Errorf("password length must be positive, got %d", length)
+	}
+
+	var sb strings.Builder
+	max := big.NewInt(int64(len(generatedCharset)))
+	for i := 0; i < length; i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate password: %w", err)
+		}
+		sb.WriteByte(generatedCharset[n.Int64()])
+	}
+
+	return sb.String(), nil
+}
+
+// Generator generates a new random password for every wallet.
+type Generator struct {
+	Length int
+}
+
+func (g Generator) Password(req Request) (string, error) {
+	return Generate(g.Length)
+}
+
+// WriteFile writes the passwords one per line, the format expected by geth's --password flag and read by File.
+// The file is only readable by the current user and is replaced atomically.
+func WriteFile(path string, passwords []string) error {
+	for i, password := range passwords {
+		if strings.ContainsAny(password, "\r\n") {
+			return fmt.Errorf("password #%d contains a line break and can't be stored in a password file", i+1)
+		}
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create password file directory: %w", err)
+	}
+
+	f, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create password file: %w", err)
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.WriteString(strings.Join(passwords, "\n") + "\n"); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write password file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write password file: %w", err)
+	}
+
+	if err := os.Rename(f.Name(), path); err != nil {
+		return fmt.Errorf("failed to write password file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package password
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/zalando/go-keyring"
+)
+
+// Keyring reads passwords from the OS keyring (the Secret Service on Linux). Secrets are looked up
+// under the given service, using the lower case wallet address as the user name.
+type Keyring struct {
+	Service string
+}
+
+func (k Keyring) Password(req Request) (string, error) {
+	if req.Address == "" {
+		return "", fmt.Errorf("%w for %s: the keyring needs the wallet address", ErrNoPassword, req)
+	}
+
+	password, err := keyring.Get(k.Service, strings.ToLower(req.Address))
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", fmt.Errorf("%w for %s: no secret stored under service %q", ErrNoPassword, req, k.Service)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read password for %s from keyring: %w", req, err)
+	}
+
+	return password, nil
+}
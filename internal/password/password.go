@@ -0,0 +1,144 @@
+// Package password provides the different places ethw can read keystore passwords from.
+package password
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+var (
+	// ErrNoPassword is returned when a source has no password for the requested wallet.
+	ErrNoPassword = errors.New("no password available")
+)
+
+// Request identifies the wallet a password is requested for.
+type Request struct {
+	// Index is the zero based position of the wallet in the current command.
+	Index int
+	// Address is the hex encoded address of the wallet, if already known.
+	Address string
+}
+
+// String returns a human readable description of the wallet, used in prompts and errors.
+func (r Request) String() string {
+	if r.Address == "" {
+		return fmt.Sprintf("wallet #%d", r.Index+1)
+	}
+	return fmt.Sprintf("wallet #%d (%s)", r.Index+1, r.Address)
+}
+
+// Source provides the password of a wallet.
+type Source interface {
+	Password(req Request) (string, error)
+}
+
+// Static returns the given passwords in order, as passed inline on the command line.
+// Empty entries are reported as missing.
+type Static []string
+
+func (s Static) Password(req Request) (string, error) {
+	if req.Index >= len(s) || s[req.Index] == "" {
+		return "", fmt.Errorf("%w for %s", ErrNoPassword, req)
+	}
+	return s[req.Index], nil
+}
+
+// File reads one password per line from a file, mapped to wallets in order.
+// Like geth's --password flag, the last line is reused when there are more wallets than lines.
+type File struct {
+	lines []string
+}
+
+// NewFile reads the passwords stored in the given file.
+func NewFile(path string) (*File, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read password file: %w", err)
+	}
+
+	lines := strings.Split(string(content), "\n")
+	// Sanitise DOS line endings and drop the trailing empty line
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], "\r")
+	}
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("password file %s is empty", path)
+	}
+
+	return &File{lines: lines}, nil
+}
+
+func (f *File) Password(req Request) (string, error) {
+	if req.Index < len(f.lines) {
+		return f.lines[req.Index], nil
+	}
+	return f.lines[len(f.lines)-1], nil
+}
+
+// Env reads passwords from environment variables. The variable <Name>_<n> (1 based) is used for
+// the n-th wallet when present, falling back to <Name> for every wallet.
+type Env struct {
+	Name string
+}
+
+func (e Env) Password(req Request) (string, error) {
+	if value, ok := os.LookupEnv(fmt.Sprintf("%s_%d", e.Name, req.Index+1)); ok {
+		return value, nil
+	}
+	if value, ok := os.LookupEnv(e.Name); ok {
+		return value, nil
+	}
+	return "", fmt.Errorf("%w for %s: environment variable %s is not set", ErrNoPassword, req, e.Name)
+}
+
+// Command runs a shell command (e.g. `pass show devnet/keystore`) and uses the first line it prints
+// as the password. The wallet index (1 based) and address are exposed to the command through the
+// ETHW_WALLET_INDEX and ETHW_WALLET_ADDRESS environment variables.
+type Command struct {
+	Command string
+}
+
+func (c Command) Password(req Request) (string, error) {
+	cmd := exec.Command("sh", "-c", c.Command)
+	cmd.Env = append(os.Environ(),
+		fmt.Sprintf("ETHW_WALLET_INDEX=%d", req.Index+1),
+		fmt.Sprintf("ETHW_WALLET_ADDRESS=%s", req.Address),
+	)
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = os.Stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("password command failed for %s: %w", req, err)
+	}
+
+	line, err := bufio.NewReader(bytes.NewReader(out)).ReadString('\n')
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" && err != nil {
+		return "", fmt.Errorf("%w for %s: password command printed nothing", ErrNoPassword, req)
+	}
+
+	return line, nil
+}
+
+// Fallback tries each source in order, moving to the next one when a source has no password.
+type Fallback []Source
+
+func (f Fallback) Password(req Request) (string, error) {
+	for _, source := range f {
+		password, err := source.Password(req)
+		if errors.Is(err, ErrNoPassword) {
+			continue
+		}
+		return password, err
+	}
+	return "", fmt.Errorf("%w for %s", ErrNoPassword, req)
+}
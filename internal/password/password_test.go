@@ -0,0 +1,112 @@
+package password
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type PasswordTestSuite struct {
+	suite.Suite
+}
+
+func (suite *PasswordTestSuite) TestFile() {
+	path := filepath.Join(suite.T().TempDir(), "password.txt")
+	err := os.WriteFile(path, []byte("first\r\nsecond\n"), 0o600)
+	assert.NoError(suite.T(), err)
+
+	file, err := NewFile(path)
+	assert.NoError(suite.T(), err, "Reading the password file should succeed")
+
+	for index, expected := range []string{"first", "second", "second"} {
+		password, err := file.Password(Request{Index: index})
+		assert.NoError(suite.T(), err)
+		assert.Equal(suite.T(), expected, password, "The last line should be reused for extra wallets")
+	}
+
+	empty := filepath.Join(suite.T().TempDir(), "empty.txt")
+	assert.NoError(suite.T(), os.WriteFile(empty, nil, 0o600))
+	_, err = NewFile(empty)
+	assert.Error(suite.T(), err, "Empty password files should be rejected")
+}
+
+func (suite *PasswordTestSuite) TestEnv() {
+	suite.T().Setenv("ETHW_TEST_PASSWORD", "shared")
+	suite.T().Setenv("ETHW_TEST_PASSWORD_2", "second")
+
+	source := Env{Name: "ETHW_TEST_PASSWORD"}
+
+	password, err := source.Password(Request{Index: 0})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "shared", password)
+
+	password, err = source.Password(Request{Index: 1})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "second", password, "Per wallet variables should take precedence")
+
+	_, err = Env{Name: "ETHW_TEST_MISSING"}.Password(Request{Index: 0})
+	assert.ErrorIs(suite.T(), err, ErrNoPassword)
+}
+
+func (suite *PasswordTestSuite) TestCommand() {
+	source := Command{Command: `printf 'secret-%s\nignored\n' "$ETHW_WALLET_INDEX"`}
+
+	password, err := source.Password(Request{Index: 2, Address: "0x0"})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "secret-3", password, "Only the first line should be used")
+
+	_, err = Command{Command: "exit 1"}.Password(Request{})
+	assert.Error(suite.T(), err, "Failing commands should be reported")
+}
+
+func (suite *PasswordTestSuite) TestFallback() {
+	source := Fallback{Static{"inline", ""}, Static{"", "fallback"}}
+
+	password, err := source.Password(Request{Index: 0})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "inline", password)
+
+	password, err = source.Password(Request{Index: 1})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "fallback", password)
+
+	_, err = source.Password(Request{Index: 2})
+	assert.ErrorIs(suite.T(), err, ErrNoPassword)
+}
+
+func (suite *PasswordTestSuite) TestGenerateAndWriteFile() {
+	generated := make([]string, 3)
+	for i := range generated {
+		password, err := Generator{Length: DefaultGeneratedLength}.Password(Request{Index: i})
+		assert.NoError(suite.T(), err)
+		assert.Len(suite.T(), password, DefaultGeneratedLength)
+		generated[i] = password
+	}
+	assert.NotEqual(suite.T(), generated[0], generated[1], "Every wallet should get a different password")
+
+	path := filepath.Join(suite.T().TempDir(), "password.txt")
+	assert.NoError(suite.T(), WriteFile(path, generated), "Writing the password file should succeed")
+
+	info, err := os.Stat(path)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), os.FileMode(0o600), info.Mode().Perm(), "Password files should only be readable by the owner")
+
+	// Reading the file back must map every line to the same wallet
+	file, err := NewFile(path)
+	assert.NoError(suite.T(), err)
+	for i, expected := range generated {
+		password, err := file.Password(Request{Index: i})
+		assert.NoError(suite.T(), err)
+		assert.Equal(suite.T(), expected, password)
+	}
+
+	assert.Error(suite.T(), WriteFile(path, []string{"multi\nline"}), "Passwords with line breaks can't be stored")
+}
+
+// Execute the test suite
+func TestPasswordTestSuite(t *testing.T) {
+	suite.Run(t, new(PasswordTestSuite))
+}
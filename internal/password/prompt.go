@@ -0,0 +1,71 @@
+package password
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+var (
+	// ErrPasswordMismatch is returned when the confirmation of a prompted password doesn't match.
+	ErrPasswordMismatch = errors.New("passwords do not match")
+
+	// ErrNotTerminal is returned when prompting is requested but stdin is not a terminal.
+	ErrNotTerminal = errors.New("cannot prompt for a password: stdin is not a terminal")
+)
+
+// Prompt asks for the password interactively without echoing it. When Confirm is set, the password
+// has to be typed twice, which should be the case whenever a new password is being chosen. Label names
+// the password asked for, e.g. "New password", and defaults to "Password".
+type Prompt struct {
+	Confirm bool
+	Label   string
+}
+
+// IsTerminal reports whether passwords can be prompted for on the current stdin.
+func IsTerminal() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+func (p Prompt) Password(req Request) (string, error) {
+	if !IsTerminal() {
+		return "", ErrNotTerminal
+	}
+
+	label := p.Label
+	if label == "" {
+		label = "Password"
+	}
+
+	password, err := readPassword(fmt.Sprintf("%s for %s: ", label, req))
+	if err != nil {
+		return "", err
+	}
+
+	if p.Confirm {
+		confirmation, err := readPassword(fmt.Sprintf("Repeat %s: ", strings.ToLower(label)))
+		if err != nil {
+			return "", err
+		}
+		if password != confirmation {
+			return "", fmt.Errorf("%w for %s", ErrPasswordMismatch, req)
+		}
+	}
+
+	return password, nil
+}
+
+// readPassword prints the prompt on stderr, so it doesn't pollute the command output, and reads a line without echo.
+func readPassword(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	defer fmt.Fprintln(os.Stderr)
+
+	password, err := term.ReadPassword(int(os.Stdin.Fd()))
+	if err != nil {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+	return string(password), nil
+}
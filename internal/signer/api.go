@@ -0,0 +1,137 @@
+package signer
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// ExternalAPIVersion is the version of Clef's external API the signer implements, reported by account_version.
+const ExternalAPIVersion = "6.1.0"
+
+// SignTransactionResult is the result of signing a transaction, as returned by Clef and geth.
+type SignTransactionResult struct {
+	Raw hexutil.Bytes      `json:"raw"`
+	Tx  *types.Transaction `json:"tx"`
+}
+
+// AccountAPI is the account namespace of Clef's external API, which geth uses with --signer.
+type AccountAPI struct {
+	signer *Signer
+}
+
+// Version returns the version of the external API.
+func (api *AccountAPI) Version() string {
+	return ExternalAPIVersion
+}
+
+// List returns the accounts of the signer.
+func (api *AccountAPI) List() []common.Address {
+	return api.signer.Accounts()
+}
+
+// SignTransaction signs a transaction, the method selector is only informative in Clef and ignored.
+func (api *AccountAPI) SignTransaction(args apitypes.SendTxArgs, methodSelector *string) (*SignTransactionResult, error) {
+	return signTransaction(api.signer, args)
+}
+
+// SignData signs data of the given content type. Only text/plain is supported, signed as an Ethereum signed message.
+func (api *AccountAPI) SignData(contentType string, address common.MixedcaseAddress, data hexutil.Bytes) (hexutil.Bytes, error) {
+	if contentType != accounts.MimetypeTextPlain {
+		return nil, fmt.Errorf("unsupported content type %q, expected %s", contentType, accounts.MimetypeTextPlain)
+	}
+	return api.signer.SignText(address.Address(), data)
+}
+
+// SignTypedData signs EIP-712 typed data.
+func (api *AccountAPI) SignTypedData(address common.MixedcaseAddress, typedData apitypes.TypedData) (hexutil.Bytes, error) {
+	return api.signer.SignTypedData(address.Address(), typedData)
+}
+
+// EthAPI is the subset of the eth namespace wallets implement, used by dapps and tools such as Foundry or Hardhat.
+type EthAPI struct {
+	signer *Signer
+}
+
+// Accounts returns the accounts of the signer.
+func (api *EthAPI) Accounts() []common.Address {
+	return api.signer.Accounts()
+}
+
+// SignTransaction signs a transaction without sending it.
+func (api *EthAPI) SignTransaction(args apitypes.SendTxArgs) (*SignTransactionResult, error) {
+	return signTransaction(api.signer, args)
+}
+
+// Sign signs a message as an Ethereum signed message.
+func (api *EthAPI) Sign(address common.Address, message string) (hexutil.Bytes, error) {
+	return api.signer.SignText(address, decodeMessage(message))
+}
+
+// SignTypedData_v4 signs EIP-712 typed data, given as an object or, like MetaMask does, as a JSON string.
+func (api *EthAPI) SignTypedData_v4(address common.Address, raw json.RawMessage) (hexutil.Bytes, error) {
+	var encoded string
+	if err := json.Unmarshal(raw, &encoded); err == nil {
+		raw = json.RawMessage(encoded)
+	}
+	var typedData apitypes.TypedData
+	if err := json.Unmarshal(raw, &typedData); err != nil {
+		return nil, fmt.Errorf("invalid typed data: %w", err)
+	}
+	return api.signer.SignTypedData(address, typedData)
+}
+
+// PersonalAPI is the personal namespace, only implementing personal_sign.
+type PersonalAPI struct {
+	signer *Signer
+}
+
+// Sign signs a message as an Ethereum signed message. The password is accepted for compatibility but unused, as
+// accounts are unlocked when the signer starts.
+func (api *PersonalAPI) Sign(message string, address common.Address, password *string) (hexutil.Bytes, error) {
+	return api.signer.SignText(address, decodeMessage(message))
+}
+
+// NewServer returns a JSON-RPC server exposing the signer through the account, eth and personal namespaces.
+func NewServer(signer *Signer) (*rpc.Server, error) {
+	server := rpc.NewServer()
+	apis := map[string]interface{}{
+		"account":  &AccountAPI{signer: signer},
+		"eth":      &EthAPI{signer: signer},
+		"personal": &PersonalAPI{signer: signer},
+	}
+	for namespace, api := range apis {
+		if err := server.RegisterName(namespace, api); err != nil {
+			server.Stop()
+			return nil, fmt.Errorf("failed to register %s API: %w", namespace, err)
+		}
+	}
+	return server, nil
+}
+
+// signTransaction signs a transaction and encodes it like geth's eth_signTransaction.
+func signTransaction(signer *Signer, args apitypes.SendTxArgs) (*SignTransactionResult, error) {
+	tx, err := signer.SignTransaction(args)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return &SignTransactionResult{Raw: raw, Tx: tx}, nil
+}
+
+// decodeMessage decodes a message to sign, given in hex as the methods specify or, as some dapps do, as plain text.
+func decodeMessage(message string) []byte {
+	if data, err := hexutil.Decode(message); err == nil {
+		return data
+	}
+	return []byte(message)
+}
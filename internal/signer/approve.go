@@ -0,0 +1,136 @@
+package signer
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// Request describes a signing request waiting for approval.
+type Request struct {
+	// Method is what is requested, e.g. "sign transaction".
+	Method  string
+	Account common.Address
+	// Details are the lines describing what would be signed.
+	Details []string
+}
+
+// Approver decides whether signing requests may proceed.
+type Approver interface {
+	Approve(req Request) (bool, error)
+}
+
+// ApproverFunc adapts a function to the Approver interface.
+type ApproverFunc func(req Request) (bool, error)
+
+func (f ApproverFunc) Approve(req Request) (bool, error) {
+	return f(req)
+}
+
+// AutoApprove approves every request, for signers used by tests and scripts against development networks.
+type AutoApprove struct{}
+
+func (AutoApprove) Approve(Request) (bool, error) {
+	return true, nil
+}
+
+// Prompt asks for the approval of every request interactively, one request at a time.
+type Prompt struct {
+	mu  sync.Mutex
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// NewPrompt returns an approver printing requests to out and reading answers from in.
+func NewPrompt(in io.Reader, out io.Writer) *Prompt {
+	return &Prompt{in: bufio.NewReader(in), out: out}
+}
+
+func (p *Prompt) Approve(req Request) (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	fmt.Fprintf(p.out, "\nRequest to %s with account %s:\n", req.Method, req.Account.Hex())
+	for _, line := range req.Details {
+		fmt.Fprintf(p.out, "  %s\n", line)
+	}
+	fmt.Fprint(p.out, "Approve? [y/N] ")
+
+	answer, err := p.in.ReadString('\n')
+	if err != nil && answer == "" {
+		fmt.Fprintln(p.out)
+		return false, fmt.Errorf("failed to read answer: %w", err)
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// transactionDetails describes a transaction to approve.
+func transactionDetails(tx *types.Transaction) []string {
+	to := "contract creation"
+	if tx.To() != nil {
+		to = tx.To().Hex()
+	}
+	details := []string{
+		fmt.Sprintf("Type: %d", tx.Type()),
+		fmt.Sprintf("To: %s", to),
+		fmt.Sprintf("Value: %s wei", tx.Value()),
+		fmt.Sprintf("Nonce: %d", tx.Nonce()),
+		fmt.Sprintf("Gas: %d", tx.Gas()),
+	}
+	if tx.Type() == types.DynamicFeeTxType {
+		details = append(details,
+			fmt.Sprintf("Max Fee Per Gas: %s wei", tx.GasFeeCap()),
+			fmt.Sprintf("Max Priority Fee Per Gas: %s wei", tx.GasTipCap()))
+	} else {
+		details = append(details, fmt.Sprintf("Gas Price: %s wei", tx.GasPrice()))
+	}
+	if len(tx.Data()) > 0 {
+		details = append(details, fmt.Sprintf("Data: %s", hexutil.Encode(tx.Data())))
+	}
+	if len(tx.AccessList()) > 0 {
+		details = append(details, fmt.Sprintf("Access List: %d address(es)", len(tx.AccessList())))
+	}
+	return details
+}
+
+// messageDetails describes a message to approve, as text when it's printable.
+func messageDetails(data []byte) []string {
+	if utf8.Valid(data) && strings.IndexFunc(string(data), func(r rune) bool { return r < ' ' && r != '\n' && r != '\t' }) < 0 {
+		return append([]string{"Message:"}, strings.Split(string(data), "\n")...)
+	}
+	return []string{fmt.Sprintf("Message: %s", hexutil.Encode(data))}
+}
+
+// typedDataDetails describes EIP-712 typed data to approve.
+func typedDataDetails(typedData apitypes.TypedData) ([]string, error) {
+	message, err := json.MarshalIndent(typedData.Message, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("invalid typed data message: %w", err)
+	}
+	details := []string{
+		fmt.Sprintf("Domain: %q, version %q", typedData.Domain.Name, typedData.Domain.Version),
+	}
+	if typedData.Domain.ChainId != nil {
+		details = append(details, fmt.Sprintf("Chain ID: %s", (*big.Int)(typedData.Domain.ChainId)))
+	}
+	if typedData.Domain.VerifyingContract != "" {
+		details = append(details, fmt.Sprintf("Verifying Contract: %q", typedData.Domain.VerifyingContract))
+	}
+	details = append(details, fmt.Sprintf("Primary Type: %s", typedData.PrimaryType))
+	return append(details, strings.Split("Message: "+string(message), "\n")...), nil
+}
@@ -0,0 +1,146 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// ServeOptions configures the endpoints the signer is served on, at least one is required.
+type ServeOptions struct {
+	// HTTPAddr is the address of the HTTP endpoint, e.g. localhost:8550. It's disabled when empty.
+	HTTPAddr string
+	// CORS are the origins browsers may call the HTTP endpoint from, * allowing any.
+	CORS []string
+	// VHosts are the host names accepted in the Host header of HTTP requests, * allowing any. IP addresses are always
+	// accepted, checking host names protects against DNS rebinding.
+	VHosts []string
+	// IPCPath is the path of the Unix socket endpoint, like geth's IPC endpoint. It's disabled when empty.
+	IPCPath string
+}
+
+// Serve serves the JSON-RPC server on the configured endpoints until the context is done or an endpoint fails.
+// listening is called with every endpoint once it accepts connections.
+func Serve(ctx context.Context, server *rpc.Server, opts ServeOptions, listening func(endpoint string)) error {
+	if opts.HTTPAddr == "" && opts.IPCPath == "" {
+		return errors.New("no endpoint to serve the signer on")
+	}
+
+	errs := make(chan error, 2)
+	// Endpoints are closed in reverse order, before stopping the server
+	cleanups := []func(){server.Stop}
+	defer func() {
+		for i := len(cleanups) - 1; i >= 0; i-- {
+			cleanups[i]()
+		}
+	}()
+
+	if opts.HTTPAddr != "" {
+		listener, err := net.Listen("tcp", opts.HTTPAddr)
+		if err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", opts.HTTPAddr, err)
+		}
+		httpServer := &http.Server{
+			Handler:           newHTTPHandler(server, opts.CORS, opts.VHosts),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		go func() {
+			if err := httpServer.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+				errs <- fmt.Errorf("HTTP endpoint failed: %w", err)
+			}
+		}()
+		cleanups = append(cleanups, func() {
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			httpServer.Shutdown(shutdownCtx)
+		})
+		listening(fmt.Sprintf("http://%s", listener.Addr()))
+	}
+
+	if opts.IPCPath != "" {
+		// A socket left over by a signer which didn't exit cleanly would make listening fail
+		if info, err := os.Stat(opts.IPCPath); err == nil && info.Mode()&os.ModeSocket != 0 {
+			os.Remove(opts.IPCPath)
+		}
+		listener, err := net.Listen("unix", opts.IPCPath)
+		if err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", opts.IPCPath, err)
+		}
+		cleanups = append(cleanups, func() {
+			listener.Close()
+			os.Remove(opts.IPCPath)
+		})
+		if err := os.Chmod(opts.IPCPath, 0o600); err != nil {
+			return fmt.Errorf("failed to restrict access to %s: %w", opts.IPCPath, err)
+		}
+		go func() {
+			if err := server.ServeListener(listener); err != nil && !errors.Is(err, net.ErrClosed) {
+				errs <- fmt.Errorf("IPC endpoint failed: %w", err)
+			}
+		}()
+		listening(opts.IPCPath)
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil
+	case err := <-errs:
+		return err
+	}
+}
+
+// newHTTPHandler wraps the JSON-RPC server with the host name and CORS checks of geth's HTTP endpoint.
+func newHTTPHandler(server http.Handler, cors, vhosts []string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !allowedHost(r.Host, vhosts) {
+			http.Error(w, "invalid host specified", http.StatusForbidden)
+			return
+		}
+
+		if origin := r.Header.Get("Origin"); origin != "" && contains(cors, origin) {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Add("Vary", "Origin")
+			if r.Method == http.MethodOptions {
+				w.Header().Set("Access-Control-Allow-Methods", "POST, GET")
+				w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+				w.Header().Set("Access-Control-Max-Age", "600")
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+		}
+
+		server.ServeHTTP(w, r)
+	})
+}
+
+// allowedHost reports whether the Host header of a request is an IP address or one of the allowed host names.
+func allowedHost(hostport string, vhosts []string) bool {
+	if hostport == "" {
+		return true
+	}
+	host, _, err := net.SplitHostPort(hostport)
+	if err != nil {
+		host = hostport
+	}
+	if net.ParseIP(host) != nil {
+		return true
+	}
+	return contains(vhosts, strings.ToLower(host))
+}
+
+// contains reports whether values holds value, case insensitively, or the * wildcard.
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == "*" || strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+package signer
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+var (
+	// ErrUnknownAccount is returned when a request asks to sign with an account the signer doesn't hold.
+	ErrUnknownAccount = errors.New("unknown account")
+	// ErrRequestDenied is returned when a request wasn't approved.
+	ErrRequestDenied = errors.New("request denied")
+)
+
+// Signer signs transactions and messages with a fixed set of private keys, held in memory while it's used. Every
+// request goes through its approver first.
+type Signer struct {
+	chainID   *big.Int
+	approver  Approver
+	addresses []common.Address
+	keys      map[common.Address]*ecdsa.PrivateKey
+}
+
+// New returns a signer for the given keys, deduplicated by address, signing transactions for chainID.
+func New(keys []*ecdsa.PrivateKey, chainID *big.Int, approver Approver) *Signer {
+	s := &Signer{
+		chainID:  new(big.Int).Set(chainID),
+		approver: approver,
+		keys:     make(map[common.Address]*ecdsa.PrivateKey, len(keys)),
+	}
+	for _, key := range keys {
+		address := crypto.PubkeyToAddress(key.PublicKey)
+		if _, ok := s.keys[address]; ok {
+			continue
+		}
+		s.keys[address] = key
+		s.addresses = append(s.addresses, address)
+	}
+	return s
+}
+
+// Accounts returns the addresses of the accounts the signer holds, in the order their keys were given.
+func (s *Signer) Accounts() []common.Address {
+	return append([]common.Address(nil), s.addresses...)
+}
+
+// ChainID returns the chain ID transactions are signed for.
+func (s *Signer) ChainID() *big.Int {
+	return new(big.Int).Set(s.chainID)
+}
+
+// SignTransaction signs the transaction described by args with the key of its sender. The chain ID of args defaults
+// to the one of the signer, and must match it when given.
+func (s *Signer) SignTransaction(args apitypes.SendTxArgs) (*types.Transaction, error) {
+	from := args.From.Address()
+	key, ok := s.keys[from]
+	if !ok {
+		return nil, fmt.Errorf("%w %s", ErrUnknownAccount, from.Hex())
+	}
+
+	if args.ChainID == nil {
+		args.ChainID = (*hexutil.Big)(s.ChainID())
+	} else if (*big.Int)(args.ChainID).Cmp(s.chainID) != 0 {
+		return nil, fmt.Errorf("requested chain id %v does not match the chain id %v of the signer", (*big.Int)(args.ChainID), s.chainID)
+	}
+	switch {
+	case args.GasPrice != nil && args.MaxFeePerGas != nil:
+		return nil, errors.New("both gasPrice and maxFeePerGas specified")
+	case args.MaxFeePerGas != nil && args.MaxPriorityFeePerGas == nil:
+		return nil, errors.New("maxPriorityFeePerGas is required with maxFeePerGas")
+	case args.GasPrice == nil && args.MaxFeePerGas == nil:
+		return nil, errors.New("missing gasPrice or maxFeePerGas")
+	case args.Data != nil && args.Input != nil && !bytes.Equal(*args.Data, *args.Input):
+		return nil, errors.New("both data and input specified, with different values")
+	}
+
+	tx := args.ToTransaction()
+	if err := s.approve(Request{Method: "sign transaction", Account: from, Details: transactionDetails(tx)}); err != nil {
+		return nil, err
+	}
+	return types.SignTx(tx, types.LatestSignerForChainID(s.chainID), key)
+}
+
+// SignText signs data prefixed as an Ethereum signed message (EIP-191 version 0x45), as done by eth_sign and
+// personal_sign. The recovery id of the signature is 27 or 28.
+func (s *Signer) SignText(address common.Address, data []byte) ([]byte, error) {
+	key, ok := s.keys[address]
+	if !ok {
+		return nil, fmt.Errorf("%w %s", ErrUnknownAccount, address.Hex())
+	}
+	if err := s.approve(Request{Method: "sign message", Account: address, Details: messageDetails(data)}); err != nil {
+		return nil, err
+	}
+	return sign(accounts.TextHash(data), key)
+}
+
+// SignTypedData signs EIP-712 typed data. The recovery id of the signature is 27 or 28.
+func (s *Signer) SignTypedData(address common.Address, typedData apitypes.TypedData) ([]byte, error) {
+	key, ok := s.keys[address]
+	if !ok {
+		return nil, fmt.Errorf("%w %s", ErrUnknownAccount, address.Hex())
+	}
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return nil, fmt.Errorf("invalid typed data: %w", err)
+	}
+	details, err := typedDataDetails(typedData)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.approve(Request{Method: "sign typed data", Account: address, Details: details}); err != nil {
+		return nil, err
+	}
+	return sign(hash, key)
+}
+
+// approve asks the approver for the request, any error denies it.
+func (s *Signer) approve(req Request) error {
+	approved, err := s.approver.Approve(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrRequestDenied, err)
+	}
+	if !approved {
+		return ErrRequestDenied
+	}
+	return nil
+}
+
+// sign signs the hash, moving the recovery id to the 27/28 form expected by wallets and contracts.
+func sign(hash []byte, key *ecdsa.PrivateKey) ([]byte, error) {
+	signature, err := crypto.Sign(hash, key)
+	if err != nil {
+		return nil, err
+	}
+	signature[crypto.RecoveryIDOffset] += 27
+	return signature, nil
+}
@@ -0,0 +1,311 @@
+package signer
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"math/big"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/external"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+const typedData = `{
+  "types": {
+    "EIP712Domain": [{"name": "name", "type": "string"}, {"name": "chainId", "type": "uint256"}],
+    "Mail": [{"name": "contents", "type": "string"}]
+  },
+  "primaryType": "Mail",
+  "domain": {"name": "Ether Mail", "chainId": 1337},
+  "message": {"contents": "Hello, Bob!"}
+}`
+
+type SignerTestSuite struct {
+	suite.Suite
+	key      *ecdsa.PrivateKey
+	address  common.Address
+	approved bool
+	requests []Request
+	server   *rpc.Server
+	client   *rpc.Client
+}
+
+func (suite *SignerTestSuite) SetupTest() {
+	var err error
+	suite.key, err = crypto.HexToECDSA("8e46b439b30731a639a3d94a9016b040a87b3027da8c932af7e1560862d11b58")
+	assert.NoError(suite.T(), err)
+	suite.address = crypto.PubkeyToAddress(suite.key.PublicKey)
+	suite.approved = true
+	suite.requests = nil
+
+	approver := ApproverFunc(func(req Request) (bool, error) {
+		suite.requests = append(suite.requests, req)
+		return suite.approved, nil
+	})
+	suite.server, err = NewServer(New([]*ecdsa.PrivateKey{suite.key, suite.key}, big.NewInt(1337), approver))
+	assert.NoError(suite.T(), err)
+	suite.client = rpc.DialInProc(suite.server)
+}
+
+func (suite *SignerTestSuite) TearDownTest() {
+	suite.client.Close()
+	suite.server.Stop()
+}
+
+// recover returns the signer of a 27/28 form signature of hash.
+func (suite *SignerTestSuite) recover(hash []byte, signature hexutil.Bytes) common.Address {
+	if !assert.Len(suite.T(), signature, crypto.SignatureLength) {
+		return common.Address{}
+	}
+	sig := append([]byte(nil), signature...)
+	sig[crypto.RecoveryIDOffset] -= 27
+	pub, err := crypto.SigToPub(hash, sig)
+	assert.NoError(suite.T(), err)
+	return crypto.PubkeyToAddress(*pub)
+}
+
+func (suite *SignerTestSuite) TestAccounts() {
+	var list []common.Address
+	assert.NoError(suite.T(), suite.client.Call(&list, "eth_accounts"))
+	assert.Equal(suite.T(), []common.Address{suite.address}, list)
+	assert.NoError(suite.T(), suite.client.Call(&list, "account_list"))
+	assert.Equal(suite.T(), []common.Address{suite.address}, list)
+}
+
+func (suite *SignerTestSuite) TestSignMessages() {
+	var signature hexutil.Bytes
+	assert.NoError(suite.T(), suite.client.Call(&signature, "eth_sign", suite.address, "0x68656c6c6f"))
+	assert.Equal(suite.T(), suite.address, suite.recover(accounts.TextHash([]byte("hello")), signature))
+
+	// personal_sign takes the message first, plain text is accepted too
+	assert.NoError(suite.T(), suite.client.Call(&signature, "personal_sign", "hello", suite.address))
+	assert.Equal(suite.T(), suite.address, suite.recover(accounts.TextHash([]byte("hello")), signature))
+
+	var data apitypes.TypedData
+	assert.NoError(suite.T(), json.Unmarshal([]byte(typedData), &data))
+	hash, _, err := apitypes.TypedDataAndHash(data)
+	assert.NoError(suite.T(), err)
+	// MetaMask sends the typed data as a JSON string
+	assert.NoError(suite.T(), suite.client.Call(&signature, "eth_signTypedData_v4", suite.address, typedData))
+	assert.Equal(suite.T(), suite.address, suite.recover(hash, signature))
+	assert.NoError(suite.T(), suite.client.Call(&signature, "account_signTypedData", suite.address, data))
+	assert.Equal(suite.T(), suite.address, suite.recover(hash, signature))
+
+	assert.Len(suite.T(), suite.requests, 4)
+}
+
+func (suite *SignerTestSuite) TestDenied() {
+	suite.approved = false
+	var signature hexutil.Bytes
+	assert.ErrorContains(suite.T(), suite.client.Call(&signature, "eth_sign", suite.address, "0x00"), ErrRequestDenied.Error())
+
+	// Unknown accounts are refused before asking
+	other := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	assert.ErrorContains(suite.T(), suite.client.Call(&signature, "eth_sign", other, "0x00"), ErrUnknownAccount.Error())
+	assert.Len(suite.T(), suite.requests, 1)
+}
+
+func (suite *SignerTestSuite) TestExternalSigner() {
+	// geth's --signer talks to the signer through its external signer backend
+	ts := httptest.NewServer(newHTTPHandler(suite.server, nil, []string{"localhost"}))
+	defer ts.Close()
+	externalSigner, err := external.NewExternalSigner(ts.URL)
+	if !assert.NoError(suite.T(), err) {
+		return
+	}
+	if assert.Len(suite.T(), externalSigner.Accounts(), 1) {
+		assert.Equal(suite.T(), suite.address, externalSigner.Accounts()[0].Address)
+	}
+	account := accounts.Account{Address: suite.address}
+
+	to := common.HexToAddress("0x0000000000000000000000000000000000000002")
+	chainID := big.NewInt(1337)
+	for _, tx := range []*types.Transaction{
+		types.NewTx(&types.LegacyTx{Nonce: 1, To: &to, Value: big.NewInt(1), Gas: 21000, GasPrice: big.NewInt(1e9)}),
+		types.NewTx(&types.AccessListTx{ChainID: chainID, Nonce: 2, To: &to, Gas: 21000, GasPrice: big.NewInt(1e9)}),
+		types.NewTx(&types.DynamicFeeTx{ChainID: chainID, Nonce: 3, To: &to, Gas: 21000, GasFeeCap: big.NewInt(2e9), GasTipCap: big.NewInt(1e9)}),
+	} {
+		signed, err := externalSigner.SignTx(account, tx, chainID)
+		if assert.NoError(suite.T(), err) {
+			assert.Equal(suite.T(), tx.Type(), signed.Type())
+			assert.Equal(suite.T(), tx.Nonce(), signed.Nonce())
+			sender, err := types.Sender(types.LatestSignerForChainID(chainID), signed)
+			assert.NoError(suite.T(), err)
+			assert.Equal(suite.T(), suite.address, sender)
+		}
+	}
+
+	// Transactions for another chain are refused
+	_, err = externalSigner.SignTx(account, types.NewTx(&types.LegacyTx{To: &to, Gas: 21000, GasPrice: big.NewInt(1)}), big.NewInt(1))
+	assert.ErrorContains(suite.T(), err, "does not match")
+
+	signature, err := externalSigner.SignText(account, []byte("hello"))
+	assert.NoError(suite.T(), err)
+	pub, err := crypto.SigToPub(accounts.TextHash([]byte("hello")), signature)
+	if assert.NoError(suite.T(), err) {
+		assert.Equal(suite.T(), suite.address, crypto.PubkeyToAddress(*pub))
+	}
+}
+
+func (suite *SignerTestSuite) TestHTTPHandler() {
+	assert.True(suite.T(), allowedHost("localhost:8550", []string{"localhost"}))
+	assert.True(suite.T(), allowedHost("127.0.0.1:8550", nil))
+	assert.False(suite.T(), allowedHost("attacker.example:8550", []string{"localhost"}))
+	assert.True(suite.T(), allowedHost("signer.internal", []string{"*"}))
+}
+
+func (suite *SignerTestSuite) TestTxArgs() {
+	nonce := uint64(7)
+	to := "0x70997970C51812dc3A010C7d01b50e0d17dc79C8"
+	key := "0x0000000000000000000000000000000000000000000000000000000000000001"
+
+	// The transaction type follows the fees and access list, from flags as from the spec
+	for _, tc := range []struct {
+		name   string
+		spec   string
+		fields TxFields
+		txType uint8
+	}{
+		{"legacy flags", "", TxFields{GasPrice: "20"}, types.LegacyTxType},
+		{"access list flags", "", TxFields{GasPrice: "20", AccessList: []string{to + ":" + key}}, types.AccessListTxType},
+		{"dynamic fee flags", "", TxFields{MaxFee: "50", PriorityFee: "2"}, types.DynamicFeeTxType},
+		{"legacy spec", `{"gasPrice": "0x1"}`, TxFields{}, types.LegacyTxType},
+		{"access list spec", `{"gasPrice": "0x1", "accessList": []}`, TxFields{}, types.AccessListTxType},
+		{"dynamic fee spec", `{"maxFeePerGas": "0x2", "maxPriorityFeePerGas": "0x1"}`, TxFields{}, types.DynamicFeeTxType},
+	} {
+		tc.fields.To, tc.fields.Gas, tc.fields.ChainID = to, 21000, 1
+		args, err := TxArgs([]byte(tc.spec), tc.fields)
+		if assert.NoError(suite.T(), err, tc.name) {
+			assert.Equal(suite.T(), tc.txType, args.ToTransaction().Type(), tc.name)
+		}
+	}
+
+	// Flags override the spec, fees without unit being gwei and values ether
+	spec := []byte(`{"to": "0x0000000000000000000000000000000000000001", "gas": "0x5208", "gasPrice": "0x1", "nonce": "0x1", "value": "0x2", "chainId": "0x5", "data": "0x01"}`)
+	args, err := TxArgs(spec, TxFields{To: to, Value: "0.5", Data: "0x02", Nonce: &nonce, Gas: 30000, GasPrice: "1.5", ChainID: 1})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), common.HexToAddress(to), args.To.Address())
+	assert.Equal(suite.T(), "500000000000000000", args.Value.ToInt().String())
+	assert.Equal(suite.T(), hexutil.Bytes{0x02}, *args.Input)
+	assert.Nil(suite.T(), args.Data)
+	assert.Equal(suite.T(), hexutil.Uint64(7), args.Nonce)
+	assert.Equal(suite.T(), hexutil.Uint64(30000), args.Gas)
+	assert.Equal(suite.T(), "1500000000", args.GasPrice.ToInt().String())
+	assert.Equal(suite.T(), int64(1), args.ChainID.ToInt().Int64())
+
+	args, err = TxArgs(spec, TxFields{})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(5), args.ChainID.ToInt().Int64())
+	assert.Equal(suite.T(), "1", args.GasPrice.ToInt().String())
+
+	_, err = TxArgs(nil, TxFields{To: to, Gas: 21000})
+	assert.ErrorIs(suite.T(), err, ErrMissingChainID)
+	_, err = TxArgs(nil, TxFields{To: to, ChainID: 1})
+	assert.ErrorIs(suite.T(), err, ErrMissingGas)
+	for name, fields := range map[string]TxFields{
+		"missing recipient": {Gas: 21000, ChainID: 1},
+		"invalid recipient": {To: "0x01", Gas: 21000, ChainID: 1},
+		"invalid fee":       {To: to, Gas: 21000, ChainID: 1, MaxFee: "fast"},
+	} {
+		_, err := TxArgs(nil, fields)
+		assert.Error(suite.T(), err, name)
+	}
+	_, err = TxArgs([]byte("{"), TxFields{})
+	assert.Error(suite.T(), err)
+}
+
+func (suite *SignerTestSuite) TestParseAccessList() {
+	address := "0x70997970C51812dc3A010C7d01b50e0d17dc79C8"
+	accessList, err := ParseAccessList([]string{address, address + ":0x01:0x" + strings.Repeat("ab", 32)})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), types.AccessList{
+		{Address: common.HexToAddress(address), StorageKeys: []common.Hash{}},
+		{Address: common.HexToAddress(address), StorageKeys: []common.Hash{
+			common.HexToHash("0x01"),
+			common.HexToHash("0x" + strings.Repeat("ab", 32)),
+		}},
+	}, accessList)
+
+	for _, entry := range []string{"0x01", address + ":xyz", address + ":0x" + strings.Repeat("ab", 33)} {
+		_, err := ParseAccessList([]string{entry})
+		assert.Error(suite.T(), err, entry)
+	}
+}
+
+// TestSignTransactionVectors signs transactions of every type from go-ethereum's t8n test data (cmd/evm/testdata 9,
+// 16 and 13), checking them against the signatures published there.
+func (suite *SignerTestSuite) TestSignTransactionVectors() {
+	for _, tc := range []struct {
+		name  string
+		key   string
+		spec  string
+		check func(tx *types.Transaction)
+	}{
+		{
+			name: "legacy",
+			key:  "45a915e4d060149eb4365960e6a7a45f334393093061116b197e3240065ff2d8",
+			spec: `{"to": "0x000000000000000000000000000000000000aaaa", "gas": "0x4ef00", "gasPrice": "0x12A05F200", "nonce": "0x1", "value": "0x0", "chainId": "0x1", "input": "0x"}`,
+			check: func(tx *types.Transaction) {
+				v, r, s := tx.RawSignatureValues()
+				assert.Equal(suite.T(), "0x25", hexutil.EncodeBig(v))
+				assert.Equal(suite.T(), "0xbee5ec9f6650020266bf3455a852eece2b073a2fa918c4d1836a1af69c2aa50c", hexutil.EncodeBig(r))
+				assert.Equal(suite.T(), "0x556c897a58dbc007a6b09814e1fba7502adb76effd2146da4365816926f387ce", hexutil.EncodeBig(s))
+			},
+		},
+		{
+			name: "access list",
+			key:  "45a915e4d060149eb4365960e6a7a45f334393093061116b197e3240065ff2d8",
+			spec: `{"to": "0x1111111111111111111111111111111111111111", "gas": "0x5208", "gasPrice": "0x1", "nonce": "0x0", "value": "0x20", "chainId": "0x1", "input": "0x", "accessList": []}`,
+			check: func(tx *types.Transaction) {
+				assert.Equal(suite.T(), "0x7cc3d1a8540a44736750f03bb4d85c0113be4b3472a71bf82241a3b261b479e6", tx.Hash().Hex())
+			},
+		},
+		{
+			name: "dynamic fee",
+			key:  "41f6e321b31e72173f8ff2e292359e1862f24fba42fe6f97efaf641980eff298",
+			spec: `{"to": "0x1111111111111111111111111111111111111111", "gas": "0x84d0", "maxFeePerGas": "0xfa0", "maxPriorityFeePerGas": "0x0", "nonce": "0x1", "value": "0x0", "chainId": "0x1", "input": "0x", "accessList": []}`,
+			check: func(tx *types.Transaction) {
+				raw, err := tx.MarshalBinary()
+				assert.NoError(suite.T(), err)
+				assert.Equal(suite.T(), "0x02f864010180820fa08284d09411111111111111111111111111111111111111118080c001a0b7dfab36232379bb3d1497a4f91c1966b1f932eae3ade107bf5d723b9cb474e0a06261c359a10f2132f126d250485b90cf20f30340801244a08ef6142ab33d1904", hexutil.Encode(raw))
+			},
+		},
+	} {
+		key, err := crypto.HexToECDSA(tc.key)
+		assert.NoError(suite.T(), err)
+		args, err := TxArgs([]byte(tc.spec), TxFields{})
+		assert.NoError(suite.T(), err, tc.name)
+		assert.NoError(suite.T(), SetSender(&args, crypto.PubkeyToAddress(key.PublicKey)))
+
+		tx, err := New([]*ecdsa.PrivateKey{key}, big.NewInt(1), AutoApprove{}).SignTransaction(args)
+		if assert.NoError(suite.T(), err, tc.name) {
+			tc.check(tx)
+		}
+	}
+}
+
+func (suite *SignerTestSuite) TestSetSender() {
+	var args apitypes.SendTxArgs
+	assert.NoError(suite.T(), SetSender(&args, suite.address))
+	assert.Equal(suite.T(), suite.address, args.From.Address())
+	assert.NoError(suite.T(), SetSender(&args, suite.address), "the sender of the spec may be the signing account")
+
+	other := common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+	err := SetSender(&args, other)
+	assert.ErrorContains(suite.T(), err, "not from the signing account")
+	assert.Equal(suite.T(), suite.address, args.From.Address())
+}
+
+func TestSignerTestSuite(t *testing.T) {
+	suite.Run(t, new(SignerTestSuite))
+}
@@ -0,0 +1,147 @@
+package signer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/aldoborrero/ethw/internal/chain"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+var (
+	// ErrMissingChainID is returned for transactions without chain ID.
+	ErrMissingChainID = errors.New("missing chain ID")
+	// ErrMissingGas is returned for transactions without gas limit.
+	ErrMissingGas = errors.New("missing gas limit")
+)
+
+// TxFields are fields of a transaction given one by one, e.g. on the command line, overriding the ones of a spec.
+// Empty fields keep the value of the spec. The value is read as ether and fees as gwei when given without unit.
+type TxFields struct {
+	To          string
+	Value       string
+	Data        string
+	Nonce       *uint64
+	Gas         uint64
+	GasPrice    string
+	MaxFee      string
+	PriorityFee string
+	ChainID     uint64
+	// AccessList entries are an address followed by its storage keys, separated by colons.
+	AccessList []string
+}
+
+// TxArgs returns the transaction of the spec, in the format of eth_signTransaction, with the fields applied; spec may
+// be empty. The transaction is an EIP-1559 one when it has a maximum fee, an access list one when it has an access
+// list and a gas price, and a legacy one otherwise.
+func TxArgs(spec []byte, fields TxFields) (apitypes.SendTxArgs, error) {
+	var args apitypes.SendTxArgs
+	if len(spec) > 0 {
+		if err := json.Unmarshal(spec, &args); err != nil {
+			return args, fmt.Errorf("invalid transaction spec: %w", err)
+		}
+	}
+
+	if fields.To != "" {
+		if !common.IsHexAddress(fields.To) {
+			return args, fmt.Errorf("invalid recipient %q", fields.To)
+		}
+		to := common.NewMixedcaseAddress(common.HexToAddress(fields.To))
+		args.To = &to
+	}
+	if fields.Value != "" {
+		value, err := chain.ParseUnits(fields.Value, chain.EtherDecimals)
+		if err != nil {
+			return args, fmt.Errorf("invalid value: %w", err)
+		}
+		args.Value = hexutil.Big(*value)
+	}
+	if fields.Data != "" {
+		data, err := hexutil.Decode(fields.Data)
+		if err != nil {
+			return args, fmt.Errorf("invalid data: %w", err)
+		}
+		input := hexutil.Bytes(data)
+		args.Data, args.Input = nil, &input
+	}
+	if fields.Nonce != nil {
+		args.Nonce = hexutil.Uint64(*fields.Nonce)
+	}
+	if fields.Gas != 0 {
+		args.Gas = hexutil.Uint64(fields.Gas)
+	}
+	for _, fee := range []struct {
+		name  string
+		raw   string
+		value **hexutil.Big
+	}{
+		{"gas price", fields.GasPrice, &args.GasPrice},
+		{"maximum fee", fields.MaxFee, &args.MaxFeePerGas},
+		{"priority fee", fields.PriorityFee, &args.MaxPriorityFeePerGas},
+	} {
+		if fee.raw == "" {
+			continue
+		}
+		amount, err := chain.ParseFee(fee.raw)
+		if err != nil {
+			return args, fmt.Errorf("invalid %s: %w", fee.name, err)
+		}
+		*fee.value = (*hexutil.Big)(amount)
+	}
+	if len(fields.AccessList) > 0 {
+		accessList, err := ParseAccessList(fields.AccessList)
+		if err != nil {
+			return args, err
+		}
+		args.AccessList = &accessList
+	}
+	if fields.ChainID != 0 {
+		args.ChainID = (*hexutil.Big)(new(big.Int).SetUint64(fields.ChainID))
+	}
+
+	switch {
+	case args.ChainID == nil:
+		return args, ErrMissingChainID
+	case args.Gas == 0:
+		return args, ErrMissingGas
+	case args.To == nil && args.Data == nil && args.Input == nil:
+		return args, errors.New("missing recipient, or code of the contract created")
+	}
+	return args, nil
+}
+
+// SetSender sets the sender of the transaction, which must match the one it already has, if any.
+func SetSender(args *apitypes.SendTxArgs, from common.Address) error {
+	if args.From != (common.MixedcaseAddress{}) && args.From.Address() != from {
+		return fmt.Errorf("the transaction is sent from %s, not from the signing account %s", args.From.Address().Hex(), from.Hex())
+	}
+	args.From = common.NewMixedcaseAddress(from)
+	return nil
+}
+
+// ParseAccessList parses access list entries given as an address followed by its storage keys, separated by colons.
+func ParseAccessList(entries []string) (types.AccessList, error) {
+	accessList := make(types.AccessList, len(entries))
+	for i, entry := range entries {
+		parts := strings.Split(entry, ":")
+		if !common.IsHexAddress(parts[0]) {
+			return nil, fmt.Errorf("invalid access list entry %q: invalid address %q", entry, parts[0])
+		}
+		accessList[i].Address = common.HexToAddress(parts[0])
+		accessList[i].StorageKeys = []common.Hash{}
+		for _, key := range parts[1:] {
+			raw, err := hexutil.Decode(key)
+			if err != nil || len(raw) > common.HashLength {
+				return nil, fmt.Errorf("invalid access list entry %q: invalid storage key %q", entry, key)
+			}
+			accessList[i].StorageKeys = append(accessList[i].StorageKeys, common.BytesToHash(raw))
+		}
+	}
+	return accessList, nil
+}
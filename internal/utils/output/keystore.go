@@ -5,81 +5,466 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/aldoborrero/ethw/internal/keystore"
-	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/jedib0t/go-pretty/v6/table"
 )
 
 // KeystoreOutputWriter is an interface for writing keystore information to different output formats.
 type KeystoreOutputWriter interface {
-	WriteCreateOutput(ks keystore.KeystoreWrapper) error
-	WriteListOutput(accounts []accounts.Account) error
+	WriteCreateOutput(results []keystore.CreateResult, unlock keystore.GethUnlock) error
+	WriteListOutput(infos []keystore.AccountInfo) error
+	WriteBenchmarkOutput(results []keystore.BenchmarkResult, recommended keystore.KDF) error
+	WriteVerifyOutput(results []keystore.VerifyResult) error
+	WriteApplyOutput(plan []keystore.PlanEntry, dryRun bool) error
+	WriteDiffOutput(entries []keystore.DiffEntry) error
+	WriteMergeOutput(results []keystore.MergeResult, dryRun bool) error
+	WriteRestoreOutput(entries []keystore.RestoreEntry, dryRun bool) error
+}
+
+// formatCreatedAt formats the creation date of a key file, which is unknown for non geth-style file names.
+func formatCreatedAt(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// verifyChecks is the order of the check columns in verification output.
+var verifyChecks = []string{
+	keystore.CheckJSON,
+	keystore.CheckFilename,
+	keystore.CheckMAC,
+	keystore.CheckDecrypt,
+	keystore.CheckAddress,
+	keystore.CheckMnemonic,
+}
+
+// verifyOutcome summarizes a verification result as "ok" or "failed".
+func verifyOutcome(result keystore.VerifyResult) string {
+	if result.OK() {
+		return string(keystore.CheckOK)
+	}
+	return string(keystore.CheckFailed)
+}
+
+// kdfFlags returns the command-line flags that select the given KDF.
+func kdfFlags(kdf keystore.KDF) string {
+	if kdf.Name == keystore.KDFPBKDF2 {
+		return fmt.Sprintf("--kdf=pbkdf2 --pbkdf2-iterations=%d", kdf.C)
+	}
+	return fmt.Sprintf("--kdf=scrypt --scrypt-n=%d --scrypt-r=%d --scrypt-p=%d", kdf.N, kdf.R, kdf.P)
+}
+
+// metadataInfo returns the JSON representation of the metadata of an account.
+func metadataInfo(meta keystore.AccountMetadata) map[string]interface{} {
+	info := map[string]interface{}{
+		"alias":       meta.Alias,
+		"tags":        append([]string{}, meta.Tags...),
+		"environment": meta.Environment,
+		"state":       meta.LifecycleState(),
+	}
+	if meta.CreatedBy != "" {
+		info["created_by"] = meta.CreatedBy
+	}
+	if meta.Source != nil {
+		info["source"] = meta.Source
+	}
+	return info
+}
+
+// entryAddress returns the address of a diff entry or merge result, empty for invalid files which have none.
+func entryAddress(address common.Address) string {
+	if address == (common.Address{}) {
+		return ""
+	}
+	return address.Hex()
+}
+
+// planIndex returns the manifest position of a plan entry, empty for accounts only found in the keystore.
+func planIndex(entry keystore.PlanEntry) string {
+	if entry.Index < 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d", entry.Index+1)
+}
+
+// restoreIndex returns the derivation index of a restored account, empty for accounts which aren't derived.
+func restoreIndex(entry keystore.RestoreEntry) string {
+	if !entry.Derived() {
+		return ""
+	}
+	return fmt.Sprintf("%d", entry.Index)
 }
 
 // KeystoreTextOutputWriter writes keystore output in pure text format.
 type KeystoreTextOutputWriter struct{}
 
-func (w KeystoreTextOutputWriter) WriteCreateOutput(ks keystore.KeystoreWrapper) error {
+func (w KeystoreTextOutputWriter) WriteCreateOutput(results []keystore.CreateResult, unlock keystore.GethUnlock) error {
+	if len(results) == 0 {
+		fmt.Println("No accounts created.")
+		return nil
+	}
+
 	fmt.Println("Account Creation Details:")
-	for _, account := range ks.Accounts() {
-		fmt.Printf("  Address: %s\n  Keystore Path: %s\n\n", account.Address.Hex(), account.URL.Path)
+	for _, result := range results {
+		meta := result.Metadata
+		fmt.Printf("  Wallet #%d: %s\n", result.Index+1, result.Status)
+		if meta.Alias != "" {
+			fmt.Printf("    Alias: %s\n", meta.Alias)
+		}
+		fmt.Printf("    Address: %s\n", result.Address.Hex())
+		fmt.Printf("    Derivation Path: %s\n", result.DerivationPath)
+		if result.KeyFile != "" {
+			fmt.Printf("    Keystore Path: %s\n", result.KeyFile)
+		}
+		fmt.Printf("    State: %s\n", meta.LifecycleState())
+		if len(meta.Tags) > 0 {
+			fmt.Printf("    Tags: %s\n", strings.Join(meta.Tags, ", "))
+		}
+		if meta.Environment != "" {
+			fmt.Printf("    Environment: %s\n", meta.Environment)
+		}
+		if meta.CreatedBy != "" {
+			fmt.Printf("    Created By: %s\n", meta.CreatedBy)
+		}
+		if result.Err != nil {
+			fmt.Printf("    Error: %s\n", result.ErrorMessage())
+		}
+		fmt.Println()
+	}
+	if flags := unlock.Flags(); flags != "" {
+		fmt.Printf("Geth Flags:\n  %s\n", flags)
 	}
 	return nil
 }
 
-func (w KeystoreTextOutputWriter) WriteListOutput(accounts []accounts.Account) error {
-	if len(accounts) == 0 {
+func (w KeystoreTextOutputWriter) WriteListOutput(infos []keystore.AccountInfo) error {
+	if len(infos) == 0 {
 		fmt.Println("No accounts found.")
 		return nil
 	}
 
 	fmt.Println("List of Wallets:")
-	for i, account := range accounts {
-		fmt.Printf("  Wallet %d: %s\n", i+1, account.Address.Hex())
+	for i, info := range infos {
+		fmt.Printf("  Wallet %d: %s\n", i+1, info.Address.Hex())
+		if info.Metadata.Alias != "" {
+			fmt.Printf("    Alias: %s\n", info.Metadata.Alias)
+		}
+		fmt.Printf("    State: %s\n", info.Metadata.LifecycleState())
+		if len(info.Metadata.Tags) > 0 {
+			fmt.Printf("    Tags: %s\n", strings.Join(info.Metadata.Tags, ", "))
+		}
+		if info.Metadata.Environment != "" {
+			fmt.Printf("    Environment: %s\n", info.Metadata.Environment)
+		}
+		fmt.Printf("    Keystore Path: %s\n", info.Path)
+		fmt.Printf("    ID: %s\n", info.ID)
+		fmt.Printf("    Version: %d\n", info.Version)
+		fmt.Printf("    KDF: %s\n", info.KDF)
+		fmt.Printf("    Cipher: %s\n", info.Cipher)
+		fmt.Printf("    Created At: %s\n\n", formatCreatedAt(info.CreatedAt))
+	}
+
+	return nil
+}
+
+func (w KeystoreTextOutputWriter) WriteBenchmarkOutput(results []keystore.BenchmarkResult, recommended keystore.KDF) error {
+	fmt.Println("Benchmark Results:")
+	for _, result := range results {
+		fmt.Printf("  %s: %s\n", result.KDF, result.Duration.Round(time.Millisecond))
+	}
+	fmt.Printf("\nRecommended KDF: %s\n  Flags: %s\n", recommended, kdfFlags(recommended))
+	return nil
+}
+
+func (w KeystoreTextOutputWriter) WriteVerifyOutput(results []keystore.VerifyResult) error {
+	if len(results) == 0 {
+		fmt.Println("No key files found.")
+		return nil
+	}
+
+	fmt.Println("Verification Results:")
+	for i, result := range results {
+		fmt.Printf("  Key File #%d: %s\n", i+1, verifyOutcome(result))
+		fmt.Printf("    Path: %s\n", result.Path)
+		fmt.Printf("    Address: %s\n", result.Address.Hex())
+		if result.DerivationPath != "" {
+			fmt.Printf("    Derivation Path: %s\n", result.DerivationPath)
+		}
+		for _, check := range result.Checks {
+			if check.Message != "" {
+				fmt.Printf("    %s: %s (%s)\n", check.Name, check.Status, check.Message)
+			} else {
+				fmt.Printf("    %s: %s\n", check.Name, check.Status)
+			}
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+func (w KeystoreTextOutputWriter) WriteApplyOutput(plan []keystore.PlanEntry, dryRun bool) error {
+	if dryRun {
+		fmt.Println("Plan (dry run):")
+	} else {
+		fmt.Println("Plan:")
+	}
+	if len(plan) == 0 {
+		fmt.Println("  Nothing to do.")
+		return nil
+	}
+	for _, entry := range plan {
+		if entry.Index >= 0 {
+			fmt.Printf("  Account #%d: %s\n", entry.Index+1, entry.Action)
+		} else {
+			fmt.Printf("  Extra Account: %s\n", entry.Action)
+		}
+		if entry.Alias != "" {
+			fmt.Printf("    Alias: %s\n", entry.Alias)
+		}
+		fmt.Printf("    Address: %s\n", entry.Address.Hex())
+		if entry.DerivationPath != "" {
+			fmt.Printf("    Derivation Path: %s\n", entry.DerivationPath)
+		}
+		if entry.KeyFile != "" {
+			fmt.Printf("    Keystore Path: %s\n", entry.KeyFile)
+		}
+		if entry.Err != nil {
+			fmt.Printf("    Error: %s\n", entry.ErrorMessage())
+		}
+		fmt.Println()
+	}
+	fmt.Printf("%d change(s)\n", keystore.PlanChanges(plan))
+	return nil
+}
+
+func (w KeystoreTextOutputWriter) WriteDiffOutput(entries []keystore.DiffEntry) error {
+	if len(entries) == 0 {
+		fmt.Println("No accounts found.")
+		return nil
+	}
+
+	fmt.Println("Keystore Differences:")
+	for i, entry := range entries {
+		fmt.Printf("  Account #%d: %s\n", i+1, entry.Status)
+		if address := entryAddress(entry.Address); address != "" {
+			fmt.Printf("    Address: %s\n", address)
+		}
+		if entry.PathA != "" {
+			fmt.Printf("    A: %s\n", entry.PathA)
+		}
+		if entry.PathB != "" {
+			fmt.Printf("    B: %s\n", entry.PathB)
+		}
+		if entry.Reason != "" {
+			fmt.Printf("    Reason: %s\n", entry.Reason)
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+func (w KeystoreTextOutputWriter) WriteMergeOutput(results []keystore.MergeResult, dryRun bool) error {
+	if len(results) == 0 {
+		fmt.Println("No accounts found.")
+		return nil
 	}
 
+	if dryRun {
+		fmt.Println("Merge Results (dry run):")
+	} else {
+		fmt.Println("Merge Results:")
+	}
+	for i, result := range results {
+		fmt.Printf("  Account #%d: %s\n", i+1, result.Status)
+		if address := entryAddress(result.Address); address != "" {
+			fmt.Printf("    Address: %s\n", address)
+		}
+		if result.Source != "" {
+			fmt.Printf("    Source: %s\n", result.Source)
+		}
+		if result.Destination != "" {
+			fmt.Printf("    Destination: %s\n", result.Destination)
+		}
+		if result.Reason != "" {
+			fmt.Printf("    Reason: %s\n", result.Reason)
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+func (w KeystoreTextOutputWriter) WriteRestoreOutput(entries []keystore.RestoreEntry, dryRun bool) error {
+	if dryRun {
+		fmt.Println("Restore Results (dry run):")
+	} else {
+		fmt.Println("Restore Results:")
+	}
+	for _, entry := range entries {
+		if entry.Derived() {
+			fmt.Printf("  Account %d: %s\n", entry.Index, entry.Status)
+		} else {
+			fmt.Printf("  Account: %s\n", entry.Status)
+		}
+		if address := entryAddress(entry.Address); address != "" {
+			fmt.Printf("    Address: %s\n", address)
+		}
+		if entry.DerivationPath != "" {
+			fmt.Printf("    Derivation Path: %s\n", entry.DerivationPath)
+		}
+		if entry.KeyFile != "" {
+			fmt.Printf("    Keystore Path: %s\n", entry.KeyFile)
+		}
+		if entry.Reason != "" {
+			fmt.Printf("    Reason: %s\n", entry.Reason)
+		}
+		fmt.Println()
+	}
 	return nil
 }
 
 // KeystoreTableOutputWriter writes keystore output in table format.
 type KeystoreTableOutputWriter struct{}
 
-func (w KeystoreTableOutputWriter) WriteCreateOutput(ks keystore.KeystoreWrapper) error {
+func (w KeystoreTableOutputWriter) WriteCreateOutput(results []keystore.CreateResult, unlock keystore.GethUnlock) error {
+	tw := table.NewWriter()
+	tw.SetOutputMirror(os.Stdout)
+	tw.AppendHeader(table.Row{"#", "Status", "Alias", "Address", "State", "Tags", "Environment", "Created By", "Derivation Path", "Keystore Path", "Error"})
+	for _, result := range results {
+		meta := result.Metadata
+		tw.AppendRow(table.Row{result.Index + 1, result.Status, meta.Alias, result.Address.Hex(), meta.LifecycleState(), strings.Join(meta.Tags, ","), meta.Environment, meta.CreatedBy, result.DerivationPath, result.KeyFile, result.ErrorMessage()})
+	}
+	tw.Render()
+	if flags := unlock.Flags(); flags != "" {
+		fmt.Printf("Geth Flags: %s\n", flags)
+	}
+	return nil
+}
+
+func (w KeystoreTableOutputWriter) WriteListOutput(infos []keystore.AccountInfo) error {
+	tw := table.NewWriter()
+	tw.SetOutputMirror(os.Stdout)
+	tw.AppendHeader(table.Row{"#", "Alias", "Address", "State", "Tags", "Environment", "Created At", "KDF", "Cipher", "Version", "ID", "Keystore Path"})
+	for i, info := range infos {
+		meta := info.Metadata
+		tw.AppendRow(table.Row{i + 1, meta.Alias, info.Address.Hex(), meta.LifecycleState(), strings.Join(meta.Tags, ","), meta.Environment, formatCreatedAt(info.CreatedAt), info.KDF.String(), info.Cipher, info.Version, info.ID, info.Path})
+	}
+	tw.Render()
+	return nil
+}
+
+func (w KeystoreTableOutputWriter) WriteBenchmarkOutput(results []keystore.BenchmarkResult, recommended keystore.KDF) error {
+	tw := table.NewWriter()
+	tw.SetOutputMirror(os.Stdout)
+	tw.AppendHeader(table.Row{"#", "KDF", "Duration", "Recommended"})
+	for i, result := range results {
+		mark := ""
+		if result.KDF == recommended {
+			mark = "*"
+		}
+		tw.AppendRow(table.Row{i + 1, result.KDF.String(), result.Duration.Round(time.Millisecond), mark})
+	}
+	tw.Render()
+	return nil
+}
+
+func (w KeystoreTableOutputWriter) WriteVerifyOutput(results []keystore.VerifyResult) error {
+	tw := table.NewWriter()
+	tw.SetOutputMirror(os.Stdout)
+	header := table.Row{"#", "Address", "Result"}
+	for _, name := range verifyChecks {
+		header = append(header, name)
+	}
+	header = append(header, "Derivation Path", "Keystore Path")
+	tw.AppendHeader(header)
+	for i, result := range results {
+		row := table.Row{i + 1, result.Address.Hex(), verifyOutcome(result)}
+		for _, name := range verifyChecks {
+			row = append(row, result.Status(name))
+		}
+		row = append(row, result.DerivationPath, result.Path)
+		tw.AppendRow(row)
+	}
+	tw.Render()
+	return nil
+}
+
+func (w KeystoreTableOutputWriter) WriteApplyOutput(plan []keystore.PlanEntry, dryRun bool) error {
+	tw := table.NewWriter()
+	tw.SetOutputMirror(os.Stdout)
+	tw.AppendHeader(table.Row{"#", "Action", "Alias", "Address", "Derivation Path", "Keystore Path", "Error"})
+	for _, entry := range plan {
+		tw.AppendRow(table.Row{planIndex(entry), entry.Action, entry.Alias, entry.Address.Hex(), entry.DerivationPath, entry.KeyFile, entry.ErrorMessage()})
+	}
+	tw.Render()
+	if dryRun {
+		fmt.Printf("Dry run: %d change(s) not applied\n", keystore.PlanChanges(plan))
+	}
+	return nil
+}
+
+func (w KeystoreTableOutputWriter) WriteDiffOutput(entries []keystore.DiffEntry) error {
 	tw := table.NewWriter()
 	tw.SetOutputMirror(os.Stdout)
-	tw.AppendHeader(table.Row{"#", "Address", "Keystore Path"})
-	for i, account := range ks.Accounts() {
-		tw.AppendRow(table.Row{i + 1, account.Address.Hex(), account.URL.Path})
+	tw.AppendHeader(table.Row{"#", "Status", "Address", "A", "B", "Reason"})
+	for i, entry := range entries {
+		tw.AppendRow(table.Row{i + 1, entry.Status, entryAddress(entry.Address), entry.PathA, entry.PathB, entry.Reason})
 	}
 	tw.Render()
 	return nil
 }
 
-func (w KeystoreTableOutputWriter) WriteListOutput(accounts []accounts.Account) error {
+func (w KeystoreTableOutputWriter) WriteMergeOutput(results []keystore.MergeResult, dryRun bool) error {
 	tw := table.NewWriter()
 	tw.SetOutputMirror(os.Stdout)
-	tw.AppendHeader(table.Row{"#", "Address"})
-	for i, account := range accounts {
-		tw.AppendRow(table.Row{i + 1, account.Address.Hex()})
+	tw.AppendHeader(table.Row{"#", "Status", "Address", "Source", "Destination", "Reason"})
+	for i, result := range results {
+		tw.AppendRow(table.Row{i + 1, result.Status, entryAddress(result.Address), result.Source, result.Destination, result.Reason})
 	}
 	tw.Render()
+	if dryRun {
+		fmt.Println("Dry run: nothing was written")
+	}
+	return nil
+}
+
+func (w KeystoreTableOutputWriter) WriteRestoreOutput(entries []keystore.RestoreEntry, dryRun bool) error {
+	tw := table.NewWriter()
+	tw.SetOutputMirror(os.Stdout)
+	tw.AppendHeader(table.Row{"Index", "Status", "Address", "Derivation Path", "Keystore Path", "Reason"})
+	for _, entry := range entries {
+		tw.AppendRow(table.Row{restoreIndex(entry), entry.Status, entryAddress(entry.Address), entry.DerivationPath, entry.KeyFile, entry.Reason})
+	}
+	tw.Render()
+	if dryRun {
+		fmt.Println("Dry run: nothing was written")
+	}
 	return nil
 }
 
 // KeyStoreJSONOutputWriter writes keystore output in JSON format.
 type KeystoreJSONOutputWriter struct{}
 
-func (w KeystoreJSONOutputWriter) WriteCreateOutput(ks keystore.KeystoreWrapper) error {
-	accounts := ks.Accounts()
-	keystoreInfo := make([]map[string]string, len(accounts))
-	for i, account := range accounts {
-		keystoreInfo[i] = map[string]string{
-			"address":       account.Address.Hex(),
-			"keystore_path": account.URL.Path,
+func (w KeystoreJSONOutputWriter) WriteCreateOutput(results []keystore.CreateResult, unlock keystore.GethUnlock) error {
+	accountInfo := make([]map[string]interface{}, len(results))
+	for i, result := range results {
+		accountInfo[i] = map[string]interface{}{
+			"index":           result.Index + 1,
+			"status":          result.Status,
+			"address":         result.Address.Hex(),
+			"derivation_path": result.DerivationPath,
+			"keystore_path":   result.KeyFile,
+			"metadata":        metadataInfo(result.Metadata),
+		}
+		if result.Err != nil {
+			accountInfo[i]["error"] = result.ErrorMessage()
 		}
 	}
-	jsonOutput, err := json.Marshal(keystoreInfo)
+	jsonOutput, err := json.Marshal(accountInfo)
 	if err != nil {
 		return err
 	}
@@ -87,15 +472,155 @@ func (w KeystoreJSONOutputWriter) WriteCreateOutput(ks keystore.KeystoreWrapper)
 	return nil
 }
 
-func (w KeystoreJSONOutputWriter) WriteListOutput(accounts []accounts.Account) error {
-	accountAddresses := make([]string, len(accounts))
-	for i, account := range accounts {
-		accountAddresses[i] = account.Address.Hex()
+func (w KeystoreJSONOutputWriter) WriteListOutput(infos []keystore.AccountInfo) error {
+	accountInfo := make([]map[string]interface{}, len(infos))
+	for i, info := range infos {
+		accountInfo[i] = map[string]interface{}{
+			"address":       info.Address.Hex(),
+			"keystore_path": info.Path,
+			"id":            info.ID,
+			"version":       info.Version,
+			"kdf":           info.KDF,
+			"cipher":        info.Cipher,
+			"created_at":    formatCreatedAt(info.CreatedAt),
+			"metadata":      metadataInfo(info.Metadata),
+		}
 	}
-	accountInfo := map[string][]string{
-		"accounts": accountAddresses,
+	jsonOutput, err := json.Marshal(map[string]interface{}{"accounts": accountInfo})
+	if err != nil {
+		return err
 	}
-	jsonOutput, err := json.Marshal(accountInfo)
+	fmt.Println(string(jsonOutput))
+	return nil
+}
+
+func (w KeystoreJSONOutputWriter) WriteBenchmarkOutput(results []keystore.BenchmarkResult, recommended keystore.KDF) error {
+	benchmarks := make([]map[string]interface{}, len(results))
+	for i, result := range results {
+		benchmarks[i] = map[string]interface{}{
+			"kdf":         result.KDF,
+			"duration_ms": result.Duration.Milliseconds(),
+		}
+	}
+	benchmarkInfo := map[string]interface{}{
+		"results":     benchmarks,
+		"recommended": recommended,
+	}
+	jsonOutput, err := json.Marshal(benchmarkInfo)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(jsonOutput))
+	return nil
+}
+
+func (w KeystoreJSONOutputWriter) WriteVerifyOutput(results []keystore.VerifyResult) error {
+	verifyInfo := make([]map[string]interface{}, len(results))
+	for i, result := range results {
+		verifyInfo[i] = map[string]interface{}{
+			"keystore_path":   result.Path,
+			"address":         result.Address.Hex(),
+			"derivation_path": result.DerivationPath,
+			"result":          verifyOutcome(result),
+			"checks":          result.Checks,
+		}
+	}
+	jsonOutput, err := json.Marshal(map[string]interface{}{"key_files": verifyInfo})
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(jsonOutput))
+	return nil
+}
+
+func (w KeystoreJSONOutputWriter) WriteApplyOutput(plan []keystore.PlanEntry, dryRun bool) error {
+	actions := make([]map[string]interface{}, len(plan))
+	for i, entry := range plan {
+		actions[i] = map[string]interface{}{
+			"action":          entry.Action,
+			"alias":           entry.Alias,
+			"address":         entry.Address.Hex(),
+			"derivation_path": entry.DerivationPath,
+			"keystore_path":   entry.KeyFile,
+		}
+		if entry.Index >= 0 {
+			actions[i]["index"] = entry.Index + 1
+		}
+		if entry.Err != nil {
+			actions[i]["error"] = entry.ErrorMessage()
+		}
+	}
+	jsonOutput, err := json.Marshal(map[string]interface{}{
+		"dry_run": dryRun,
+		"changes": keystore.PlanChanges(plan),
+		"plan":    actions,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(jsonOutput))
+	return nil
+}
+
+func (w KeystoreJSONOutputWriter) WriteDiffOutput(entries []keystore.DiffEntry) error {
+	diffInfo := make([]map[string]interface{}, len(entries))
+	for i, entry := range entries {
+		diffInfo[i] = map[string]interface{}{
+			"status":  entry.Status,
+			"address": entryAddress(entry.Address),
+			"a":       entry.PathA,
+			"b":       entry.PathB,
+		}
+		if entry.Reason != "" {
+			diffInfo[i]["reason"] = entry.Reason
+		}
+	}
+	jsonOutput, err := json.Marshal(map[string]interface{}{"accounts": diffInfo})
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(jsonOutput))
+	return nil
+}
+
+func (w KeystoreJSONOutputWriter) WriteMergeOutput(results []keystore.MergeResult, dryRun bool) error {
+	mergeInfo := make([]map[string]interface{}, len(results))
+	for i, result := range results {
+		mergeInfo[i] = map[string]interface{}{
+			"status":      result.Status,
+			"address":     entryAddress(result.Address),
+			"source":      result.Source,
+			"destination": result.Destination,
+		}
+		if result.Reason != "" {
+			mergeInfo[i]["reason"] = result.Reason
+		}
+	}
+	jsonOutput, err := json.Marshal(map[string]interface{}{"dry_run": dryRun, "accounts": mergeInfo})
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(jsonOutput))
+	return nil
+}
+
+func (w KeystoreJSONOutputWriter) WriteRestoreOutput(entries []keystore.RestoreEntry, dryRun bool) error {
+	restoreInfo := make([]map[string]interface{}, len(entries))
+	for i, entry := range entries {
+		restoreInfo[i] = map[string]interface{}{
+			"status":          entry.Status,
+			"address":         entryAddress(entry.Address),
+			"derivation_path": entry.DerivationPath,
+			"keystore_path":   entry.KeyFile,
+		}
+		if entry.Derived() {
+			restoreInfo[i]["index"] = entry.Index
+		}
+		if entry.Reason != "" {
+			restoreInfo[i]["reason"] = entry.Reason
+		}
+	}
+	jsonOutput, err := json.Marshal(map[string]interface{}{"dry_run": dryRun, "accounts": restoreInfo})
 	if err != nil {
 		return err
 	}
@@ -106,17 +631,200 @@ func (w KeystoreJSONOutputWriter) WriteListOutput(accounts []accounts.Account) e
 // KeystoreCSVOutputWriter writes keystore output in CSV format.
 type KeystoreCSVOutputWriter struct{}
 
-func (w KeystoreCSVOutputWriter) WriteCreateOutput(ks keystore.KeystoreWrapper) error {
+// WriteCreateOutput writes the result of every wallet, the accounts to unlock being the ones with an unlock position.
+func (w KeystoreCSVOutputWriter) WriteCreateOutput(results []keystore.CreateResult, unlock keystore.GethUnlock) error {
+	csvWriter := csv.NewWriter(os.Stdout)
+	defer csvWriter.Flush()
+
+	err := csvWriter.Write([]string{"Index", "Status", "Alias", "Address", "State", "Tags", "Environment", "Created By", "Derivation Path", "Keystore Path", "Unlock Position", "Error"})
+	if err != nil {
+		return err
+	}
+
+	positions := make(map[common.Address]int, len(unlock.Addresses))
+	for i, address := range unlock.Addresses {
+		positions[address] = i + 1
+	}
+
+	for _, result := range results {
+		position := ""
+		if p, ok := positions[result.Address]; ok && result.Written() {
+			position = fmt.Sprintf("%d", p)
+		}
+		err := csvWriter.Write([]string{
+			fmt.Sprintf("%d", result.Index+1),
+			string(result.Status),
+			result.Metadata.Alias,
+			result.Address.Hex(),
+			result.Metadata.LifecycleState(),
+			strings.Join(result.Metadata.Tags, ";"),
+			result.Metadata.Environment,
+			result.Metadata.CreatedBy,
+			result.DerivationPath,
+			result.KeyFile,
+			position,
+			result.ErrorMessage(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w KeystoreCSVOutputWriter) WriteListOutput(infos []keystore.AccountInfo) error {
+	csvWriter := csv.NewWriter(os.Stdout)
+	defer csvWriter.Flush()
+
+	err := csvWriter.Write([]string{"Index", "Alias", "Address", "State", "Tags", "Environment", "Created At", "KDF", "Cipher", "Version", "ID", "Keystore Path"})
+	if err != nil {
+		return err
+	}
+
+	for i, info := range infos {
+		err := csvWriter.Write([]string{
+			fmt.Sprintf("%d", i+1),
+			info.Metadata.Alias,
+			info.Address.Hex(),
+			info.Metadata.LifecycleState(),
+			strings.Join(info.Metadata.Tags, ";"),
+			info.Metadata.Environment,
+			formatCreatedAt(info.CreatedAt),
+			info.KDF.String(),
+			info.Cipher,
+			fmt.Sprintf("%d", info.Version),
+			info.ID,
+			info.Path,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w KeystoreCSVOutputWriter) WriteBenchmarkOutput(results []keystore.BenchmarkResult, recommended keystore.KDF) error {
+	csvWriter := csv.NewWriter(os.Stdout)
+	defer csvWriter.Flush()
+
+	err := csvWriter.Write([]string{"Index", "KDF", "Duration (ms)", "Recommended"})
+	if err != nil {
+		return err
+	}
+
+	for i, result := range results {
+		err := csvWriter.Write([]string{
+			fmt.Sprintf("%d", i+1),
+			result.KDF.String(),
+			fmt.Sprintf("%d", result.Duration.Milliseconds()),
+			fmt.Sprintf("%t", result.KDF == recommended),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w KeystoreCSVOutputWriter) WriteVerifyOutput(results []keystore.VerifyResult) error {
+	csvWriter := csv.NewWriter(os.Stdout)
+	defer csvWriter.Flush()
+
+	header := []string{"Index", "Address", "Result"}
+	header = append(header, verifyChecks...)
+	header = append(header, "Derivation Path", "Keystore Path", "Errors")
+	if err := csvWriter.Write(header); err != nil {
+		return err
+	}
+
+	for i, result := range results {
+		record := []string{fmt.Sprintf("%d", i+1), result.Address.Hex(), verifyOutcome(result)}
+		for _, name := range verifyChecks {
+			record = append(record, string(result.Status(name)))
+		}
+		record = append(record, result.DerivationPath, result.Path, strings.Join(result.Failures(), "; "))
+		if err := csvWriter.Write(record); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w KeystoreCSVOutputWriter) WriteApplyOutput(plan []keystore.PlanEntry, dryRun bool) error {
+	csvWriter := csv.NewWriter(os.Stdout)
+	defer csvWriter.Flush()
+
+	err := csvWriter.Write([]string{"Index", "Action", "Alias", "Address", "Derivation Path", "Keystore Path", "Error"})
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range plan {
+		err := csvWriter.Write([]string{
+			planIndex(entry),
+			entry.Action,
+			entry.Alias,
+			entry.Address.Hex(),
+			entry.DerivationPath,
+			entry.KeyFile,
+			entry.ErrorMessage(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w KeystoreCSVOutputWriter) WriteDiffOutput(entries []keystore.DiffEntry) error {
+	csvWriter := csv.NewWriter(os.Stdout)
+	defer csvWriter.Flush()
+
+	err := csvWriter.Write([]string{"Index", "Status", "Address", "A", "B", "Reason"})
+	if err != nil {
+		return err
+	}
+
+	for i, entry := range entries {
+		err := csvWriter.Write([]string{
+			fmt.Sprintf("%d", i+1),
+			entry.Status,
+			entryAddress(entry.Address),
+			entry.PathA,
+			entry.PathB,
+			entry.Reason,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w KeystoreCSVOutputWriter) WriteMergeOutput(results []keystore.MergeResult, dryRun bool) error {
 	csvWriter := csv.NewWriter(os.Stdout)
 	defer csvWriter.Flush()
 
-	err := csvWriter.Write([]string{"Address", "Keystore Path"})
+	err := csvWriter.Write([]string{"Index", "Status", "Address", "Source", "Destination", "Reason"})
 	if err != nil {
 		return err
 	}
 
-	for _, account := range ks.Accounts() {
-		err := csvWriter.Write([]string{account.Address.Hex(), account.URL.Path})
+	for i, result := range results {
+		err := csvWriter.Write([]string{
+			fmt.Sprintf("%d", i+1),
+			result.Status,
+			entryAddress(result.Address),
+			result.Source,
+			result.Destination,
+			result.Reason,
+		})
 		if err != nil {
 			return err
 		}
@@ -125,17 +833,24 @@ func (w KeystoreCSVOutputWriter) WriteCreateOutput(ks keystore.KeystoreWrapper)
 	return nil
 }
 
-func (w KeystoreCSVOutputWriter) WriteListOutput(accounts []accounts.Account) error {
+func (w KeystoreCSVOutputWriter) WriteRestoreOutput(entries []keystore.RestoreEntry, dryRun bool) error {
 	csvWriter := csv.NewWriter(os.Stdout)
 	defer csvWriter.Flush()
 
-	err := csvWriter.Write([]string{"Index", "Address"})
+	err := csvWriter.Write([]string{"Index", "Status", "Address", "Derivation Path", "Keystore Path", "Reason"})
 	if err != nil {
 		return err
 	}
 
-	for i, account := range accounts {
-		err := csvWriter.Write([]string{fmt.Sprintf("%d", i+1), account.Address.Hex()})
+	for _, entry := range entries {
+		err := csvWriter.Write([]string{
+			restoreIndex(entry),
+			entry.Status,
+			entryAddress(entry.Address),
+			entry.DerivationPath,
+			entry.KeyFile,
+			entry.Reason,
+		})
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,53 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/aldoborrero/ethw/internal/keystore"
+)
+
+// KeystoreWatchOutputWriter is an interface for streaming keystore watch events, one at a time as they happen.
+type KeystoreWatchOutputWriter interface {
+	WriteWatchEvent(event keystore.WatchEvent) error
+}
+
+// watchEventInfo returns the JSON representation of a watch event.
+func watchEventInfo(event keystore.WatchEvent) map[string]interface{} {
+	return map[string]interface{}{
+		"event":         event.Type,
+		"address":       event.Address.Hex(),
+		"keystore_path": event.Path,
+		"time":          event.Time.Format(time.RFC3339Nano),
+	}
+}
+
+// WatchEventJSON encodes a watch event as a single line JSON object.
+func WatchEventJSON(event keystore.WatchEvent) ([]byte, error) {
+	return json.Marshal(watchEventInfo(event))
+}
+
+// KeystoreNDJSONWatchOutputWriter writes every watch event as a JSON object on its own line.
+type KeystoreNDJSONWatchOutputWriter struct{}
+
+func (w KeystoreNDJSONWatchOutputWriter) WriteWatchEvent(event keystore.WatchEvent) error {
+	jsonOutput, err := WatchEventJSON(event)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(jsonOutput))
+	return nil
+}
+
+// KeystoreJSONWatchOutputWriter writes every watch event as an indented JSON object.
+type KeystoreJSONWatchOutputWriter struct{}
+
+func (w KeystoreJSONWatchOutputWriter) WriteWatchEvent(event keystore.WatchEvent) error {
+	jsonOutput, err := json.MarshalIndent(watchEventInfo(event), "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(jsonOutput))
+	return nil
+}
@@ -0,0 +1,82 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type OutputTestSuite struct {
+	suite.Suite
+}
+
+// fields returns the fields of a signed transaction by name.
+func (suite *OutputTestSuite) fields(raw string, from common.Address) map[string]string {
+	tx := new(types.Transaction)
+	assert.NoError(suite.T(), tx.UnmarshalBinary(hexutil.MustDecode(raw)))
+	names, values, err := signedTxFields(tx, from)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), values, len(names))
+
+	fields := make(map[string]string, len(names))
+	for i, name := range names {
+		fields[name] = values[i]
+	}
+	assert.Equal(suite.T(), tx.Hash().Hex(), fields["Hash"])
+	assert.Equal(suite.T(), raw, fields["Raw"])
+	return fields
+}
+
+// TestSignedTxFields checks the fields of signed transactions from go-ethereum's t8n test data (cmd/evm/testdata 13).
+func (suite *OutputTestSuite) TestSignedTxFields() {
+	key, err := crypto.HexToECDSA("41f6e321b31e72173f8ff2e292359e1862f24fba42fe6f97efaf641980eff298")
+	assert.NoError(suite.T(), err)
+	from := crypto.PubkeyToAddress(key.PublicKey)
+
+	fields := suite.fields("0x02f864010180820fa08284d09411111111111111111111111111111111111111118080c001a0b7dfab36232379bb3d1497a4f91c1966b1f932eae3ade107bf5d723b9cb474e0a06261c359a10f2132f126d250485b90cf20f30340801244a08ef6142ab33d1904", from)
+	assert.Equal(suite.T(), "dynamic fee (EIP-1559)", fields["Type"])
+	assert.Equal(suite.T(), "1", fields["Chain ID"])
+	assert.Equal(suite.T(), from.Hex(), fields["From"])
+	assert.Equal(suite.T(), "0x1111111111111111111111111111111111111111", fields["To"])
+	assert.Equal(suite.T(), "1", fields["Nonce"])
+	assert.Equal(suite.T(), "34000", fields["Gas"])
+	assert.Equal(suite.T(), "", fields["Gas Price (gwei)"], "EIP-1559 transactions have no gas price")
+	assert.Equal(suite.T(), "0.000004", fields["Max Fee (gwei)"])
+	assert.Equal(suite.T(), "0", fields["Priority Fee (gwei)"])
+	assert.Equal(suite.T(), "0", fields["Value (ETH)"])
+	assert.Equal(suite.T(), "", fields["Data"])
+	assert.Equal(suite.T(), "0x1", fields["V"])
+	assert.Equal(suite.T(), "0xb7dfab36232379bb3d1497a4f91c1966b1f932eae3ade107bf5d723b9cb474e0", fields["R"])
+
+	// A contract creation with a gas price, data and an access list
+	to := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	tx := types.NewTx(&types.AccessListTx{
+		ChainID:    common.Big1,
+		GasPrice:   hexutil.MustDecodeBig("0x12A05F200"),
+		Gas:        60000,
+		Value:      hexutil.MustDecodeBig("0xde0b6b3a7640000"),
+		Data:       []byte{0x60, 0x00},
+		AccessList: types.AccessList{{Address: to, StorageKeys: []common.Hash{{0x01}}}},
+		V:          common.Big0, R: common.Big0, S: common.Big0,
+	})
+	raw, err := tx.MarshalBinary()
+	assert.NoError(suite.T(), err)
+	fields = suite.fields(hexutil.Encode(raw), from)
+	assert.Equal(suite.T(), "access list (EIP-2930)", fields["Type"])
+	assert.Equal(suite.T(), "contract creation", fields["To"])
+	assert.Equal(suite.T(), "5", fields["Gas Price (gwei)"])
+	assert.Equal(suite.T(), "", fields["Max Fee (gwei)"])
+	assert.Equal(suite.T(), "1", fields["Value (ETH)"])
+	assert.Equal(suite.T(), "0x6000", fields["Data"])
+	assert.Equal(suite.T(), to.Hex()+" [0x0100000000000000000000000000000000000000000000000000000000000000]", fields["Access List"])
+}
+
+// Execute the test suite
+func TestOutputTestSuite(t *testing.T) {
+	suite.Run(t, new(OutputTestSuite))
+}
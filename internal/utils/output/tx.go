@@ -0,0 +1,180 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aldoborrero/ethw/internal/chain"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+// TxOutputWriter is an interface for writing transactions to different output formats.
+type TxOutputWriter interface {
+	WriteSignOutput(tx *types.Transaction, from common.Address) error
+}
+
+// txTypeNames are the names of the transaction types.
+var txTypeNames = map[uint8]string{
+	types.LegacyTxType:     "legacy",
+	types.AccessListTxType: "access list (EIP-2930)",
+	types.DynamicFeeTxType: "dynamic fee (EIP-1559)",
+}
+
+// formatAccessList formats an access list as the addresses followed by their storage keys.
+func formatAccessList(accessList types.AccessList) string {
+	tuples := make([]string, len(accessList))
+	for i, tuple := range accessList {
+		keys := make([]string, len(tuple.StorageKeys))
+		for j, key := range tuple.StorageKeys {
+			keys[j] = key.Hex()
+		}
+		tuples[i] = fmt.Sprintf("%s [%s]", tuple.Address.Hex(), strings.Join(keys, " "))
+	}
+	return strings.Join(tuples, ", ")
+}
+
+// signedTxFields returns the names and values of the fields of a signed transaction, with its hash and raw encoding
+// first. Fields the transaction type doesn't have are left empty.
+func signedTxFields(tx *types.Transaction, from common.Address) ([]string, []string, error) {
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode transaction: %w", err)
+	}
+	txType, ok := txTypeNames[tx.Type()]
+	if !ok {
+		txType = fmt.Sprintf("%d", tx.Type())
+	}
+	to := "contract creation"
+	if tx.To() != nil {
+		to = tx.To().Hex()
+	}
+	gasPrice, maxFee, priorityFee := "", "", ""
+	if tx.Type() == types.DynamicFeeTxType {
+		maxFee, priorityFee = chain.FormatUnits(tx.GasFeeCap(), chain.GweiDecimals), chain.FormatUnits(tx.GasTipCap(), chain.GweiDecimals)
+	} else {
+		gasPrice = chain.FormatUnits(tx.GasPrice(), chain.GweiDecimals)
+	}
+	data := ""
+	if len(tx.Data()) > 0 {
+		data = hexutil.Encode(tx.Data())
+	}
+	v, r, s := tx.RawSignatureValues()
+
+	names := []string{
+		"Hash", "Raw", "Type", "Chain ID", "From", "To", "Nonce", "Gas", "Gas Price (gwei)", "Max Fee (gwei)",
+		"Priority Fee (gwei)", "Value (ETH)", "Data", "Access List", "V", "R", "S",
+	}
+	values := []string{
+		tx.Hash().Hex(),
+		hexutil.Encode(raw),
+		txType,
+		tx.ChainId().String(),
+		from.Hex(),
+		to,
+		fmt.Sprintf("%d", tx.Nonce()),
+		fmt.Sprintf("%d", tx.Gas()),
+		gasPrice,
+		maxFee,
+		priorityFee,
+		chain.FormatUnits(tx.Value(), chain.EtherDecimals),
+		data,
+		formatAccessList(tx.AccessList()),
+		hexutil.EncodeBig(v),
+		hexutil.EncodeBig(r),
+		hexutil.EncodeBig(s),
+	}
+	return names, values, nil
+}
+
+// TxTextOutputWriter writes transactions in a readable text format.
+type TxTextOutputWriter struct{}
+
+// WriteSignOutput writes the fields of a signed transaction, skipping the ones its type doesn't have.
+func (w TxTextOutputWriter) WriteSignOutput(tx *types.Transaction, from common.Address) error {
+	names, values, err := signedTxFields(tx, from)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Signed Transaction:")
+	for i, name := range names {
+		if values[i] != "" {
+			fmt.Printf("  %s: %s\n", name, values[i])
+		}
+	}
+	return nil
+}
+
+// TxTableOutputWriter writes transactions in table format.
+type TxTableOutputWriter struct{}
+
+// WriteSignOutput writes the fields of a signed transaction as rows, skipping the ones its type doesn't have.
+func (t TxTableOutputWriter) WriteSignOutput(tx *types.Transaction, from common.Address) error {
+	names, values, err := signedTxFields(tx, from)
+	if err != nil {
+		return err
+	}
+
+	tw := table.NewWriter()
+	tw.SetOutputMirror(os.Stdout)
+	tw.AppendHeader(table.Row{"Field", "Value"})
+	for i, name := range names {
+		if values[i] != "" {
+			tw.AppendRow(table.Row{name, values[i]})
+		}
+	}
+	tw.Render()
+	return nil
+}
+
+// TxJSONOutputWriter writes transactions in JSON format.
+type TxJSONOutputWriter struct{}
+
+// WriteSignOutput writes the hash, raw encoding and sender of a signed transaction, with its fields encoded as by
+// eth_getTransactionByHash.
+func (j TxJSONOutputWriter) WriteSignOutput(tx *types.Transaction, from common.Address) error {
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to encode transaction: %w", err)
+	}
+
+	jsonOutput, err := json.Marshal(map[string]interface{}{
+		"hash": tx.Hash().Hex(),
+		"raw":  hexutil.Encode(raw),
+		"from": from.Hex(),
+		"tx":   tx,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(jsonOutput))
+	return nil
+}
+
+// TxCSVOutputWriter writes transactions in CSV format.
+type TxCSVOutputWriter struct{}
+
+// WriteSignOutput writes the fields of a signed transaction as a CSV record to standard output.
+func (w TxCSVOutputWriter) WriteSignOutput(tx *types.Transaction, from common.Address) error {
+	names, values, err := signedTxFields(tx, from)
+	if err != nil {
+		return err
+	}
+
+	csvWriter := csv.NewWriter(os.Stdout)
+	defer csvWriter.Flush()
+
+	if err := csvWriter.Write(names); err != nil {
+		return fmt.Errorf("writing CSV header: %w", err)
+	}
+	if err := csvWriter.Write(values); err != nil {
+		return fmt.Errorf("writing CSV record: %w", err)
+	}
+	return nil
+}
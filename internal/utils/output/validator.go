@@ -0,0 +1,629 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aldoborrero/ethw/internal/validator"
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+// ValidatorOutputWriter is an interface for writing validator key information to different output formats.
+type ValidatorOutputWriter interface {
+	WriteCreateOutput(results []validator.CreateResult) error
+	WriteDepositOutput(path string, deposits []validator.DepositData) error
+	WriteDepositVerifyOutput(results []validator.DepositVerifyResult) error
+	WriteBLSChangeOutput(path string, changes []validator.SignedBLSToExecutionChange) error
+	WriteExitOutput(results []validator.ExitResult) error
+	WriteExportOutput(client string, results []validator.ExportResult) error
+	WriteSlashingProtectionOutput(path string, watermarks []validator.InterchangeWatermark) error
+	WriteSlashingProtectionValidateOutput(results []validator.InterchangeValidateResult) error
+}
+
+// formatGwei formats an amount of gwei in ether.
+func formatGwei(amount uint64) string {
+	return strings.TrimRight(strings.TrimRight(fmt.Sprintf("%d.%09d", amount/validator.GweiPerEther, amount%validator.GweiPerEther), "0"), ".") + " ETH"
+}
+
+// depositOutcome summarizes a deposit verification result as "ok" or "failed".
+func depositOutcome(result validator.DepositVerifyResult) string {
+	if result.OK() {
+		return "ok"
+	}
+	return "failed"
+}
+
+// interchangeOutcome summarizes an interchange validation result as "ok" or "failed".
+func interchangeOutcome(result validator.InterchangeValidateResult) string {
+	if result.OK() {
+		return "ok"
+	}
+	return "failed"
+}
+
+// watermarkSlot formats the highest slot signed by a validator, or "-" if it hasn't signed any block.
+func watermarkSlot(w validator.InterchangeWatermark) string {
+	if w.Blocks == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d", w.MaxSlot)
+}
+
+// watermarkEpochs formats the highest source and target epochs attested by a validator, or "-" if it hasn't signed
+// any attestation.
+func watermarkEpochs(w validator.InterchangeWatermark) string {
+	if w.Attestations == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d → %d", w.MaxSourceEpoch, w.MaxTargetEpoch)
+}
+
+// ValidatorTextOutputWriter writes validator output in pure text format.
+type ValidatorTextOutputWriter struct{}
+
+func (w ValidatorTextOutputWriter) WriteCreateOutput(results []validator.CreateResult) error {
+	if len(results) == 0 {
+		fmt.Println("No validator keys created.")
+		return nil
+	}
+
+	fmt.Println("Validator Keys:")
+	for _, result := range results {
+		fmt.Printf("  Validator #%d\n", result.Index)
+		fmt.Printf("    Public Key: 0x%s\n", result.Pubkey)
+		fmt.Printf("    Signing Path: %s\n", result.SigningPath)
+		fmt.Printf("    Keystore Path: %s\n", result.KeystorePath)
+		fmt.Printf("    Withdrawal Public Key: 0x%s\n", result.WithdrawalPubkey)
+		fmt.Printf("    Withdrawal Path: %s\n", result.WithdrawalPath)
+		if result.WithdrawalKeystorePath != "" {
+			fmt.Printf("    Withdrawal Keystore Path: %s\n", result.WithdrawalKeystorePath)
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+func (w ValidatorTextOutputWriter) WriteDepositOutput(path string, deposits []validator.DepositData) error {
+	fmt.Printf("Deposit Data: %s\n", path)
+	for i, deposit := range deposits {
+		fmt.Printf("  Deposit #%d\n", i+1)
+		fmt.Printf("    Public Key: 0x%s\n", deposit.Pubkey)
+		fmt.Printf("    Withdrawal Credentials: 0x%s\n", deposit.WithdrawalCredentials)
+		fmt.Printf("    Amount: %s\n", formatGwei(deposit.Amount))
+		fmt.Printf("    Network: %s (fork version 0x%s)\n", deposit.NetworkName, deposit.ForkVersion)
+		fmt.Printf("    Deposit Data Root: 0x%s\n\n", deposit.DepositDataRoot)
+	}
+	return nil
+}
+
+func (w ValidatorTextOutputWriter) WriteDepositVerifyOutput(results []validator.DepositVerifyResult) error {
+	if len(results) == 0 {
+		fmt.Println("No deposits found.")
+		return nil
+	}
+
+	fmt.Println("Verification Results:")
+	for _, result := range results {
+		fmt.Printf("  %s #%d: %s\n", result.File, result.Index+1, depositOutcome(result))
+		fmt.Printf("    Public Key: 0x%s\n", result.Deposit.Pubkey)
+		fmt.Printf("    Amount: %s\n", formatGwei(result.Deposit.Amount))
+		fmt.Printf("    Network: %s\n", result.Deposit.NetworkName)
+		for _, problem := range result.Problems {
+			fmt.Printf("    Error: %s\n", problem)
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+func (w ValidatorTextOutputWriter) WriteBLSChangeOutput(path string, changes []validator.SignedBLSToExecutionChange) error {
+	fmt.Printf("BLS to Execution Changes: %s\n", path)
+	for _, change := range changes {
+		fmt.Printf("  Validator %s\n", change.Message.ValidatorIndex)
+		fmt.Printf("    From BLS Public Key: %s\n", change.Message.FromBLSPubkey)
+		fmt.Printf("    To Execution Address: %s\n", change.Message.ToExecutionAddress)
+		fmt.Printf("    Signature: %s\n\n", change.Signature)
+	}
+	return nil
+}
+
+func (w ValidatorTextOutputWriter) WriteExitOutput(results []validator.ExitResult) error {
+	fmt.Println("Signed Voluntary Exits:")
+	for _, result := range results {
+		fmt.Printf("  Validator %s\n", result.Exit.Message.ValidatorIndex)
+		fmt.Printf("    Public Key: 0x%s\n", result.Pubkey)
+		fmt.Printf("    Epoch: %s\n", result.Exit.Message.Epoch)
+		fmt.Printf("    Path: %s\n\n", result.Path)
+	}
+	return nil
+}
+
+func (w ValidatorTextOutputWriter) WriteExportOutput(client string, results []validator.ExportResult) error {
+	fmt.Printf("Exported Validators (%s):\n", client)
+	for _, result := range results {
+		fmt.Printf("  %s\n", result.Pubkey)
+		fmt.Printf("    Keystore Path: %s\n", result.KeystorePath)
+		fmt.Printf("    Password Path: %s\n\n", result.PasswordPath)
+	}
+	return nil
+}
+
+func (w ValidatorTextOutputWriter) WriteSlashingProtectionOutput(path string, watermarks []validator.InterchangeWatermark) error {
+	fmt.Println("Slashing Protection:")
+	for _, watermark := range watermarks {
+		fmt.Printf("  %s\n", watermark.Pubkey)
+		fmt.Printf("    Signed Blocks: %d (highest slot: %s)\n", watermark.Blocks, watermarkSlot(watermark))
+		fmt.Printf("    Signed Attestations: %d (highest epochs: %s)\n\n", watermark.Attestations, watermarkEpochs(watermark))
+	}
+	fmt.Printf("Interchange File: %s\n", path)
+	return nil
+}
+
+func (w ValidatorTextOutputWriter) WriteSlashingProtectionValidateOutput(results []validator.InterchangeValidateResult) error {
+	fmt.Println("Validation Results:")
+	for _, result := range results {
+		fmt.Printf("  %s: %s\n", result.File, interchangeOutcome(result))
+		fmt.Printf("    Validators: %d\n", result.Validators)
+		for _, problem := range result.Problems {
+			fmt.Printf("    Error: %s\n", problem)
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+// ValidatorTableOutputWriter writes validator output in table format.
+type ValidatorTableOutputWriter struct{}
+
+func (w ValidatorTableOutputWriter) WriteCreateOutput(results []validator.CreateResult) error {
+	tw := table.NewWriter()
+	tw.SetOutputMirror(os.Stdout)
+	tw.AppendHeader(table.Row{"Index", "Public Key", "Signing Path", "Keystore Path", "Withdrawal Public Key", "Withdrawal Keystore Path"})
+	for _, result := range results {
+		tw.AppendRow(table.Row{result.Index, "0x" + result.Pubkey, result.SigningPath, result.KeystorePath, "0x" + result.WithdrawalPubkey, result.WithdrawalKeystorePath})
+	}
+	tw.Render()
+	return nil
+}
+
+func (w ValidatorTableOutputWriter) WriteDepositOutput(path string, deposits []validator.DepositData) error {
+	tw := table.NewWriter()
+	tw.SetOutputMirror(os.Stdout)
+	tw.AppendHeader(table.Row{"#", "Public Key", "Withdrawal Credentials", "Amount", "Network", "Deposit Data Root"})
+	for i, deposit := range deposits {
+		tw.AppendRow(table.Row{i + 1, "0x" + deposit.Pubkey, "0x" + deposit.WithdrawalCredentials, formatGwei(deposit.Amount), deposit.NetworkName, "0x" + deposit.DepositDataRoot})
+	}
+	tw.Render()
+	fmt.Printf("Deposit Data: %s\n", path)
+	return nil
+}
+
+func (w ValidatorTableOutputWriter) WriteDepositVerifyOutput(results []validator.DepositVerifyResult) error {
+	tw := table.NewWriter()
+	tw.SetOutputMirror(os.Stdout)
+	tw.AppendHeader(table.Row{"File", "#", "Public Key", "Amount", "Network", "Result", "Errors"})
+	for _, result := range results {
+		tw.AppendRow(table.Row{result.File, result.Index + 1, "0x" + result.Deposit.Pubkey, formatGwei(result.Deposit.Amount), result.Deposit.NetworkName, depositOutcome(result), strings.Join(result.Problems, "; ")})
+	}
+	tw.Render()
+	return nil
+}
+
+func (w ValidatorTableOutputWriter) WriteBLSChangeOutput(path string, changes []validator.SignedBLSToExecutionChange) error {
+	tw := table.NewWriter()
+	tw.SetOutputMirror(os.Stdout)
+	tw.AppendHeader(table.Row{"Validator Index", "From BLS Public Key", "To Execution Address"})
+	for _, change := range changes {
+		tw.AppendRow(table.Row{change.Message.ValidatorIndex, change.Message.FromBLSPubkey, change.Message.ToExecutionAddress})
+	}
+	tw.Render()
+	fmt.Printf("BLS to Execution Changes: %s\n", path)
+	return nil
+}
+
+func (w ValidatorTableOutputWriter) WriteExitOutput(results []validator.ExitResult) error {
+	tw := table.NewWriter()
+	tw.SetOutputMirror(os.Stdout)
+	tw.AppendHeader(table.Row{"Validator Index", "Public Key", "Epoch", "Path"})
+	for _, result := range results {
+		tw.AppendRow(table.Row{result.Exit.Message.ValidatorIndex, "0x" + result.Pubkey, result.Exit.Message.Epoch, result.Path})
+	}
+	tw.Render()
+	return nil
+}
+
+func (w ValidatorTableOutputWriter) WriteExportOutput(client string, results []validator.ExportResult) error {
+	tw := table.NewWriter()
+	tw.SetOutputMirror(os.Stdout)
+	tw.AppendHeader(table.Row{"#", "Public Key", "Keystore Path", "Password Path"})
+	for i, result := range results {
+		tw.AppendRow(table.Row{i + 1, result.Pubkey, result.KeystorePath, result.PasswordPath})
+	}
+	tw.Render()
+	fmt.Printf("Client: %s\n", client)
+	return nil
+}
+
+func (w ValidatorTableOutputWriter) WriteSlashingProtectionOutput(path string, watermarks []validator.InterchangeWatermark) error {
+	tw := table.NewWriter()
+	tw.SetOutputMirror(os.Stdout)
+	tw.AppendHeader(table.Row{"Public Key", "Signed Blocks", "Highest Slot", "Signed Attestations", "Highest Epochs"})
+	for _, watermark := range watermarks {
+		tw.AppendRow(table.Row{watermark.Pubkey, watermark.Blocks, watermarkSlot(watermark), watermark.Attestations, watermarkEpochs(watermark)})
+	}
+	tw.Render()
+	fmt.Printf("Interchange File: %s\n", path)
+	return nil
+}
+
+func (w ValidatorTableOutputWriter) WriteSlashingProtectionValidateOutput(results []validator.InterchangeValidateResult) error {
+	tw := table.NewWriter()
+	tw.SetOutputMirror(os.Stdout)
+	tw.AppendHeader(table.Row{"File", "Validators", "Result", "Errors"})
+	for _, result := range results {
+		tw.AppendRow(table.Row{result.File, result.Validators, interchangeOutcome(result), strings.Join(result.Problems, "; ")})
+	}
+	tw.Render()
+	return nil
+}
+
+// ValidatorJSONOutputWriter writes validator output in JSON format.
+type ValidatorJSONOutputWriter struct{}
+
+func (w ValidatorJSONOutputWriter) WriteCreateOutput(results []validator.CreateResult) error {
+	validatorInfo := make([]map[string]interface{}, len(results))
+	for i, result := range results {
+		validatorInfo[i] = map[string]interface{}{
+			"index":             result.Index,
+			"pubkey":            "0x" + result.Pubkey,
+			"signing_path":      result.SigningPath,
+			"keystore_path":     result.KeystorePath,
+			"withdrawal_pubkey": "0x" + result.WithdrawalPubkey,
+			"withdrawal_path":   result.WithdrawalPath,
+		}
+		if result.WithdrawalKeystorePath != "" {
+			validatorInfo[i]["withdrawal_keystore_path"] = result.WithdrawalKeystorePath
+		}
+	}
+	jsonOutput, err := json.Marshal(map[string]interface{}{"validators": validatorInfo})
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(jsonOutput))
+	return nil
+}
+
+func (w ValidatorJSONOutputWriter) WriteDepositOutput(path string, deposits []validator.DepositData) error {
+	jsonOutput, err := json.Marshal(map[string]interface{}{
+		"deposit_data_path": path,
+		"deposits":          deposits,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(jsonOutput))
+	return nil
+}
+
+func (w ValidatorJSONOutputWriter) WriteDepositVerifyOutput(results []validator.DepositVerifyResult) error {
+	depositInfo := make([]map[string]interface{}, len(results))
+	for i, result := range results {
+		depositInfo[i] = map[string]interface{}{
+			"file":    result.File,
+			"index":   result.Index + 1,
+			"pubkey":  "0x" + result.Deposit.Pubkey,
+			"amount":  result.Deposit.Amount,
+			"network": result.Deposit.NetworkName,
+			"result":  depositOutcome(result),
+			"errors":  result.Problems,
+		}
+	}
+	jsonOutput, err := json.Marshal(map[string]interface{}{"deposits": depositInfo})
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(jsonOutput))
+	return nil
+}
+
+func (w ValidatorJSONOutputWriter) WriteBLSChangeOutput(path string, changes []validator.SignedBLSToExecutionChange) error {
+	jsonOutput, err := json.Marshal(map[string]interface{}{
+		"bls_to_execution_changes_path": path,
+		"bls_to_execution_changes":      changes,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(jsonOutput))
+	return nil
+}
+
+// WriteExitOutput writes the signed exits as a JSON array, which can be submitted to a beacon node as is.
+func (w ValidatorJSONOutputWriter) WriteExitOutput(results []validator.ExitResult) error {
+	exits := make([]validator.SignedVoluntaryExit, len(results))
+	for i, result := range results {
+		exits[i] = result.Exit
+	}
+	jsonOutput, err := json.Marshal(exits)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(jsonOutput))
+	return nil
+}
+
+func (w ValidatorJSONOutputWriter) WriteExportOutput(client string, results []validator.ExportResult) error {
+	validatorInfo := make([]map[string]interface{}, len(results))
+	for i, result := range results {
+		validatorInfo[i] = map[string]interface{}{
+			"pubkey":        result.Pubkey,
+			"keystore_path": result.KeystorePath,
+			"password_path": result.PasswordPath,
+		}
+	}
+	jsonOutput, err := json.Marshal(map[string]interface{}{"client": client, "validators": validatorInfo})
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(jsonOutput))
+	return nil
+}
+
+func (w ValidatorJSONOutputWriter) WriteSlashingProtectionOutput(path string, watermarks []validator.InterchangeWatermark) error {
+	validatorInfo := make([]map[string]interface{}, len(watermarks))
+	for i, watermark := range watermarks {
+		info := map[string]interface{}{
+			"pubkey":              watermark.Pubkey,
+			"signed_blocks":       watermark.Blocks,
+			"signed_attestations": watermark.Attestations,
+		}
+		if watermark.Blocks > 0 {
+			info["max_slot"] = watermark.MaxSlot
+		}
+		if watermark.Attestations > 0 {
+			info["max_source_epoch"] = watermark.MaxSourceEpoch
+			info["max_target_epoch"] = watermark.MaxTargetEpoch
+		}
+		validatorInfo[i] = info
+	}
+	jsonOutput, err := json.Marshal(map[string]interface{}{"interchange_path": path, "validators": validatorInfo})
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(jsonOutput))
+	return nil
+}
+
+func (w ValidatorJSONOutputWriter) WriteSlashingProtectionValidateOutput(results []validator.InterchangeValidateResult) error {
+	fileInfo := make([]map[string]interface{}, len(results))
+	for i, result := range results {
+		fileInfo[i] = map[string]interface{}{
+			"file":       result.File,
+			"validators": result.Validators,
+			"result":     interchangeOutcome(result),
+			"errors":     result.Problems,
+		}
+	}
+	jsonOutput, err := json.Marshal(map[string]interface{}{"files": fileInfo})
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(jsonOutput))
+	return nil
+}
+
+// ValidatorCSVOutputWriter writes validator output in CSV format.
+type ValidatorCSVOutputWriter struct{}
+
+func (w ValidatorCSVOutputWriter) WriteCreateOutput(results []validator.CreateResult) error {
+	csvWriter := csv.NewWriter(os.Stdout)
+	defer csvWriter.Flush()
+
+	err := csvWriter.Write([]string{"Index", "Public Key", "Signing Path", "Keystore Path", "Withdrawal Public Key", "Withdrawal Path", "Withdrawal Keystore Path"})
+	if err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		err := csvWriter.Write([]string{
+			fmt.Sprintf("%d", result.Index),
+			"0x" + result.Pubkey,
+			result.SigningPath,
+			result.KeystorePath,
+			"0x" + result.WithdrawalPubkey,
+			result.WithdrawalPath,
+			result.WithdrawalKeystorePath,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w ValidatorCSVOutputWriter) WriteDepositOutput(path string, deposits []validator.DepositData) error {
+	csvWriter := csv.NewWriter(os.Stdout)
+	defer csvWriter.Flush()
+
+	err := csvWriter.Write([]string{"Index", "Public Key", "Withdrawal Credentials", "Amount (gwei)", "Network", "Fork Version", "Deposit Data Root", "Deposit Data Path"})
+	if err != nil {
+		return err
+	}
+
+	for i, deposit := range deposits {
+		err := csvWriter.Write([]string{
+			fmt.Sprintf("%d", i+1),
+			"0x" + deposit.Pubkey,
+			"0x" + deposit.WithdrawalCredentials,
+			fmt.Sprintf("%d", deposit.Amount),
+			deposit.NetworkName,
+			"0x" + deposit.ForkVersion,
+			"0x" + deposit.DepositDataRoot,
+			path,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w ValidatorCSVOutputWriter) WriteDepositVerifyOutput(results []validator.DepositVerifyResult) error {
+	csvWriter := csv.NewWriter(os.Stdout)
+	defer csvWriter.Flush()
+
+	err := csvWriter.Write([]string{"File", "Index", "Public Key", "Amount (gwei)", "Network", "Result", "Errors"})
+	if err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		err := csvWriter.Write([]string{
+			result.File,
+			fmt.Sprintf("%d", result.Index+1),
+			"0x" + result.Deposit.Pubkey,
+			fmt.Sprintf("%d", result.Deposit.Amount),
+			result.Deposit.NetworkName,
+			depositOutcome(result),
+			strings.Join(result.Problems, "; "),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w ValidatorCSVOutputWriter) WriteBLSChangeOutput(path string, changes []validator.SignedBLSToExecutionChange) error {
+	csvWriter := csv.NewWriter(os.Stdout)
+	defer csvWriter.Flush()
+
+	err := csvWriter.Write([]string{"Validator Index", "From BLS Public Key", "To Execution Address", "Signature", "BLS to Execution Changes Path"})
+	if err != nil {
+		return err
+	}
+
+	for _, change := range changes {
+		err := csvWriter.Write([]string{
+			change.Message.ValidatorIndex,
+			change.Message.FromBLSPubkey,
+			change.Message.ToExecutionAddress,
+			change.Signature,
+			path,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w ValidatorCSVOutputWriter) WriteExitOutput(results []validator.ExitResult) error {
+	csvWriter := csv.NewWriter(os.Stdout)
+	defer csvWriter.Flush()
+
+	err := csvWriter.Write([]string{"Validator Index", "Public Key", "Epoch", "Signature", "Path"})
+	if err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		err := csvWriter.Write([]string{
+			result.Exit.Message.ValidatorIndex,
+			"0x" + result.Pubkey,
+			result.Exit.Message.Epoch,
+			result.Exit.Signature,
+			result.Path,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w ValidatorCSVOutputWriter) WriteExportOutput(client string, results []validator.ExportResult) error {
+	csvWriter := csv.NewWriter(os.Stdout)
+	defer csvWriter.Flush()
+
+	err := csvWriter.Write([]string{"Client", "Public Key", "Keystore Path", "Password Path"})
+	if err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		err := csvWriter.Write([]string{client, result.Pubkey, result.KeystorePath, result.PasswordPath})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w ValidatorCSVOutputWriter) WriteSlashingProtectionOutput(path string, watermarks []validator.InterchangeWatermark) error {
+	csvWriter := csv.NewWriter(os.Stdout)
+	defer csvWriter.Flush()
+
+	err := csvWriter.Write([]string{"Public Key", "Signed Blocks", "Max Slot", "Signed Attestations", "Max Source Epoch", "Max Target Epoch", "Interchange Path"})
+	if err != nil {
+		return err
+	}
+
+	for _, watermark := range watermarks {
+		maxSlot, maxSource, maxTarget := "", "", ""
+		if watermark.Blocks > 0 {
+			maxSlot = fmt.Sprintf("%d", watermark.MaxSlot)
+		}
+		if watermark.Attestations > 0 {
+			maxSource = fmt.Sprintf("%d", watermark.MaxSourceEpoch)
+			maxTarget = fmt.Sprintf("%d", watermark.MaxTargetEpoch)
+		}
+		err := csvWriter.Write([]string{
+			watermark.Pubkey,
+			fmt.Sprintf("%d", watermark.Blocks),
+			maxSlot,
+			fmt.Sprintf("%d", watermark.Attestations),
+			maxSource,
+			maxTarget,
+			path,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w ValidatorCSVOutputWriter) WriteSlashingProtectionValidateOutput(results []validator.InterchangeValidateResult) error {
+	csvWriter := csv.NewWriter(os.Stdout)
+	defer csvWriter.Flush()
+
+	err := csvWriter.Write([]string{"File", "Validators", "Result", "Errors"})
+	if err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		err := csvWriter.Write([]string{
+			result.File,
+			fmt.Sprintf("%d", result.Validators),
+			interchangeOutcome(result),
+			strings.Join(result.Problems, "; "),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -6,13 +6,94 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/aldoborrero/ethw/internal/chain"
 	"github.com/aldoborrero/ethw/internal/wallet"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/jedib0t/go-pretty/v6/table"
 )
 
 // WalletOutputWriter is an interface for writing wallet information to different output formats.
 type WalletOutputWriter interface {
 	WriteCreateOutput([]*wallet.Wallet) error
+	WriteStatusOutput([]chain.WalletStatus, []chain.Token) error
+	WriteTransferOutput(transfers []chain.WalletTransfer, dryRun bool) error
+	WriteDiscoverOutput([]wallet.Discovered) error
+}
+
+// discoverHeader returns the column names of discovered accounts.
+func discoverHeader() []string {
+	return []string{"#", "Index", "Address", "Derivation Path", "Balance (ETH)", "Nonce"}
+}
+
+// discoverRecord returns the column values of a discovered account, leaving the balance and nonce empty when unknown.
+func discoverRecord(i int, account wallet.Discovered) []string {
+	nonce := ""
+	if account.Nonce != nil {
+		nonce = fmt.Sprintf("%d", *account.Nonce)
+	}
+	return []string{
+		fmt.Sprintf("%d", i+1),
+		fmt.Sprintf("%d", account.Index),
+		account.Address.Hex(),
+		account.DerivationPath,
+		chain.FormatUnits(account.Balance, chain.EtherDecimals),
+		nonce,
+	}
+}
+
+// statusHeader returns the column names of wallet status output, with a balance column per token.
+func statusHeader(tokens []chain.Token) []string {
+	header := []string{"#", "Alias", "Address", "Derivation Path", "Balance (ETH)", "Nonce"}
+	for _, token := range tokens {
+		header = append(header, token.Symbol)
+	}
+	return append(header, "Error")
+}
+
+// statusRecord returns the column values of a wallet status, leaving the values which couldn't be looked up empty.
+func statusRecord(i int, status chain.WalletStatus, tokens []chain.Token) []string {
+	nonce := ""
+	if status.Nonce != nil {
+		nonce = fmt.Sprintf("%d", *status.Nonce)
+	}
+	record := []string{
+		fmt.Sprintf("%d", i+1),
+		status.Alias,
+		status.Address.Hex(),
+		status.DerivationPath,
+		chain.FormatUnits(status.Balance, chain.EtherDecimals),
+		nonce,
+	}
+	for j, token := range tokens {
+		record = append(record, chain.FormatUnits(status.TokenBalances[j], token.Decimals))
+	}
+	return append(record, status.ErrorMessage())
+}
+
+// transferHeader returns the column names of transfer output.
+func transferHeader() []string {
+	return []string{"#", "Alias", "From", "To", "Amount", "Asset", "Max Fee (ETH)", "Nonce", "Tx Hash", "Status", "Error"}
+}
+
+// transferRecord returns the column values of a transfer, leaving the nonce and hash of unsigned transfers empty.
+func transferRecord(i int, transfer chain.WalletTransfer) []string {
+	nonce, hash := "", ""
+	if transfer.Hash != (common.Hash{}) {
+		nonce, hash = fmt.Sprintf("%d", transfer.Nonce), transfer.Hash.Hex()
+	}
+	return []string{
+		fmt.Sprintf("%d", i+1),
+		transfer.Alias,
+		transfer.From.Hex(),
+		transfer.To.Hex(),
+		chain.FormatUnits(transfer.Amount, transfer.Decimals()),
+		transfer.Asset(),
+		chain.FormatUnits(transfer.MaxCost(), chain.EtherDecimals),
+		nonce,
+		hash,
+		transfer.Status,
+		transfer.Error,
+	}
 }
 
 type WalletTextOuputWriter struct{}
@@ -36,6 +117,109 @@ func (w WalletTextOuputWriter) WriteCreateOutput(wallets []*wallet.Wallet) error
 	return nil
 }
 
+// WriteStatusOutput writes the balances and nonces of the wallets in a readable text format.
+func (w WalletTextOuputWriter) WriteStatusOutput(statuses []chain.WalletStatus, tokens []chain.Token) error {
+	if len(statuses) == 0 {
+		fmt.Println("No wallets to look up")
+		return nil
+	}
+
+	fmt.Println("Wallets Status:")
+	for i, status := range statuses {
+		record := statusRecord(i, status, tokens)
+		fmt.Printf("  Wallet #%d:\n", i+1)
+		if status.Alias != "" {
+			fmt.Printf("    Alias: %s\n", status.Alias)
+		}
+		fmt.Printf("    Address: %s\n", status.Address.Hex())
+		if status.DerivationPath != "" {
+			fmt.Printf("    Derivation Path: %s\n", status.DerivationPath)
+		}
+		if status.Balance != nil {
+			fmt.Printf("    Balance: %s ETH\n", record[4])
+		}
+		if status.Nonce != nil {
+			fmt.Printf("    Nonce: %d\n", *status.Nonce)
+		}
+		for j, token := range tokens {
+			if status.TokenBalances[j] != nil {
+				fmt.Printf("    %s Balance: %s\n", token.Symbol, record[6+j])
+			}
+		}
+		if status.Err != nil {
+			fmt.Printf("    Error: %s\n", status.Err)
+		}
+		fmt.Println()
+	}
+
+	return nil
+}
+
+// WriteTransferOutput writes the transfers in a readable text format.
+func (w WalletTextOuputWriter) WriteTransferOutput(transfers []chain.WalletTransfer, dryRun bool) error {
+	if len(transfers) == 0 {
+		fmt.Println("No transfers to make")
+		return nil
+	}
+
+	if dryRun {
+		fmt.Println("Planned Transfers (dry run):")
+	} else {
+		fmt.Println("Transfers:")
+	}
+	for i, transfer := range transfers {
+		record := transferRecord(i, transfer)
+		fmt.Printf("  Transfer #%d: %s\n", i+1, transfer.Status)
+		if transfer.Alias != "" {
+			fmt.Printf("    Alias: %s\n", transfer.Alias)
+		}
+		fmt.Printf("    From: %s\n", record[2])
+		fmt.Printf("    To: %s\n", record[3])
+		fmt.Printf("    Amount: %s %s\n", record[4], record[5])
+		if record[6] != "" {
+			fmt.Printf("    Max Fee: %s ETH\n", record[6])
+		}
+		if record[8] != "" {
+			fmt.Printf("    Nonce: %s\n", record[7])
+			fmt.Printf("    Tx Hash: %s\n", record[8])
+		}
+		if transfer.Block != 0 {
+			fmt.Printf("    Block: %d\n", transfer.Block)
+		}
+		if transfer.Error != "" {
+			fmt.Printf("    Error: %s\n", transfer.Error)
+		}
+		fmt.Println()
+	}
+
+	return nil
+}
+
+// WriteDiscoverOutput writes the discovered accounts in a readable text format.
+func (w WalletTextOuputWriter) WriteDiscoverOutput(accounts []wallet.Discovered) error {
+	if len(accounts) == 0 {
+		fmt.Println("No used accounts found")
+		return nil
+	}
+
+	fmt.Println("Discovered Wallets:")
+	for i, account := range accounts {
+		fmt.Printf("  Wallet #%d:\n", i+1)
+		fmt.Printf("    Index: %d\n", account.Index)
+		fmt.Printf("    Address: %s\n", account.Address.Hex())
+		fmt.Printf("    Derivation Path: %s\n", account.DerivationPath)
+		if account.Balance != nil {
+			fmt.Printf("    Balance: %s ETH\n", chain.FormatUnits(account.Balance, chain.EtherDecimals))
+		}
+		if account.Nonce != nil {
+			fmt.Printf("    Nonce: %d\n", *account.Nonce)
+		}
+		fmt.Println()
+	}
+
+	return nil
+}
+
 // WalletTableOutputWriter is a type that implements the OutputWriter interface for table-formatted data.
 type WalletTableOutputWriter struct{}
 
@@ -52,6 +236,69 @@ func (t WalletTableOutputWriter) WriteCreateOutput(walletInfos []*wallet.Wallet)
 	return nil
 }
 
+// WriteStatusOutput writes the balances and nonces of the wallets in table format.
+func (t WalletTableOutputWriter) WriteStatusOutput(statuses []chain.WalletStatus, tokens []chain.Token) error {
+	tw := table.NewWriter()
+	tw.SetOutputMirror(os.Stdout)
+	header := table.Row{}
+	for _, column := range statusHeader(tokens) {
+		header = append(header, column)
+	}
+	tw.AppendHeader(header)
+	for i, status := range statuses {
+		row := table.Row{}
+		for _, value := range statusRecord(i, status, tokens) {
+			row = append(row, value)
+		}
+		tw.AppendRow(row)
+	}
+	tw.Render()
+	return nil
+}
+
+// WriteTransferOutput writes the transfers in table format.
+func (t WalletTableOutputWriter) WriteTransferOutput(transfers []chain.WalletTransfer, dryRun bool) error {
+	tw := table.NewWriter()
+	tw.SetOutputMirror(os.Stdout)
+	header := table.Row{}
+	for _, column := range transferHeader() {
+		header = append(header, column)
+	}
+	tw.AppendHeader(header)
+	for i, transfer := range transfers {
+		row := table.Row{}
+		for _, value := range transferRecord(i, transfer) {
+			row = append(row, value)
+		}
+		tw.AppendRow(row)
+	}
+	if dryRun {
+		tw.SetCaption("Dry run, no transaction was sent.")
+	}
+	tw.Render()
+	return nil
+}
+
+// WriteDiscoverOutput writes the discovered accounts in table format.
+func (t WalletTableOutputWriter) WriteDiscoverOutput(accounts []wallet.Discovered) error {
+	tw := table.NewWriter()
+	tw.SetOutputMirror(os.Stdout)
+	header := table.Row{}
+	for _, column := range discoverHeader() {
+		header = append(header, column)
+	}
+	tw.AppendHeader(header)
+	for i, account := range accounts {
+		row := table.Row{}
+		for _, value := range discoverRecord(i, account) {
+			row = append(row, value)
+		}
+		tw.AppendRow(row)
+	}
+	tw.Render()
+	return nil
+}
+
 // WalletJSONOutputWriter is a type that implements the OutputWriter interface for JSON-formatted data.
 type WalletJSONOutputWriter struct{}
 
@@ -66,6 +313,125 @@ func (j WalletJSONOutputWriter) WriteCreateOutput(walletInfos []*wallet.Wallet)
 	return nil
 }
 
+// WriteStatusOutput writes the balances and nonces of the wallets in JSON format, with balances both formatted and in
+// their smallest unit.
+func (j WalletJSONOutputWriter) WriteStatusOutput(statuses []chain.WalletStatus, tokens []chain.Token) error {
+	accounts := make([]map[string]interface{}, len(statuses))
+	for i, status := range statuses {
+		account := map[string]interface{}{
+			"alias":   status.Alias,
+			"address": status.Address.Hex(),
+		}
+		if status.DerivationPath != "" {
+			account["derivation_path"] = status.DerivationPath
+		}
+		if status.Balance != nil {
+			account["balance"] = chain.FormatUnits(status.Balance, chain.EtherDecimals)
+			account["balance_wei"] = status.Balance.String()
+		}
+		if status.Nonce != nil {
+			account["nonce"] = *status.Nonce
+		}
+		balances := []map[string]interface{}{}
+		for k, token := range tokens {
+			if status.TokenBalances[k] == nil {
+				continue
+			}
+			balances = append(balances, map[string]interface{}{
+				"token":   token.Address.Hex(),
+				"symbol":  token.Symbol,
+				"balance": chain.FormatUnits(status.TokenBalances[k], token.Decimals),
+				"raw":     status.TokenBalances[k].String(),
+			})
+		}
+		if len(tokens) > 0 {
+			account["tokens"] = balances
+		}
+		if status.Err != nil {
+			account["error"] = status.Err.Error()
+		}
+		accounts[i] = account
+	}
+
+	jsonOutput, err := json.Marshal(accounts)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(jsonOutput))
+	return nil
+}
+
+// WriteTransferOutput writes the transfers in JSON format, with amounts both formatted and in their smallest unit.
+func (j WalletJSONOutputWriter) WriteTransferOutput(transfers []chain.WalletTransfer, dryRun bool) error {
+	entries := make([]map[string]interface{}, len(transfers))
+	for i, transfer := range transfers {
+		entry := map[string]interface{}{
+			"alias":      transfer.Alias,
+			"from":       transfer.From.Hex(),
+			"to":         transfer.To.Hex(),
+			"asset":      transfer.Asset(),
+			"amount":     chain.FormatUnits(transfer.Amount, transfer.Decimals()),
+			"amount_raw": transfer.Amount.String(),
+			"gas":        transfer.Gas,
+			"status":     transfer.Status,
+		}
+		if transfer.DerivationPath != "" {
+			entry["derivation_path"] = transfer.DerivationPath
+		}
+		if transfer.Token != nil {
+			entry["token"] = transfer.Token.Address.Hex()
+		}
+		if transfer.MaxFee != nil {
+			entry["max_fee_per_gas"] = transfer.MaxFee.String()
+		}
+		if transfer.Hash != (common.Hash{}) {
+			entry["nonce"] = transfer.Nonce
+			entry["hash"] = transfer.Hash.Hex()
+		}
+		if transfer.Block != 0 {
+			entry["block"] = transfer.Block
+		}
+		if transfer.Error != "" {
+			entry["error"] = transfer.Error
+		}
+		entries[i] = entry
+	}
+
+	jsonOutput, err := json.Marshal(map[string]interface{}{"dry_run": dryRun, "transfers": entries})
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(jsonOutput))
+	return nil
+}
+
+// WriteDiscoverOutput writes the discovered accounts in JSON format.
+func (j WalletJSONOutputWriter) WriteDiscoverOutput(accounts []wallet.Discovered) error {
+	entries := make([]map[string]interface{}, len(accounts))
+	for i, account := range accounts {
+		entry := map[string]interface{}{
+			"index":           account.Index,
+			"address":         account.Address.Hex(),
+			"derivation_path": account.DerivationPath,
+		}
+		if account.Balance != nil {
+			entry["balance"] = chain.FormatUnits(account.Balance, chain.EtherDecimals)
+			entry["balance_wei"] = account.Balance.String()
+		}
+		if account.Nonce != nil {
+			entry["nonce"] = *account.Nonce
+		}
+		entries[i] = entry
+	}
+
+	jsonOutput, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(jsonOutput))
+	return nil
+}
+
 // WalletCSVOutputWriter writes wallet information in CSV format.
 type WalletCSVOutputWriter struct{}
 
@@ -94,3 +460,57 @@ func (w WalletCSVOutputWriter) WriteCreateOutput(wallets []*wallet.Wallet) error
 
 	return nil
 }
+
+// WriteStatusOutput writes the balances and nonces of the wallets in CSV format to standard output.
+func (w WalletCSVOutputWriter) WriteStatusOutput(statuses []chain.WalletStatus, tokens []chain.Token) error {
+	csvWriter := csv.NewWriter(os.Stdout)
+	defer csvWriter.Flush()
+
+	if err := csvWriter.Write(statusHeader(tokens)); err != nil {
+		return fmt.Errorf("writing CSV header: %w", err)
+	}
+
+	for i, status := range statuses {
+		if err := csvWriter.Write(statusRecord(i, status, tokens)); err != nil {
+			return fmt.Errorf("writing CSV record for wallet #%d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+// WriteTransferOutput writes the transfers in CSV format to standard output.
+func (w WalletCSVOutputWriter) WriteTransferOutput(transfers []chain.WalletTransfer, dryRun bool) error {
+	csvWriter := csv.NewWriter(os.Stdout)
+	defer csvWriter.Flush()
+
+	if err := csvWriter.Write(transferHeader()); err != nil {
+		return fmt.Errorf("writing CSV header: %w", err)
+	}
+
+	for i, transfer := range transfers {
+		if err := csvWriter.Write(transferRecord(i, transfer)); err != nil {
+			return fmt.Errorf("writing CSV record for transfer #%d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+// WriteDiscoverOutput writes the discovered accounts in CSV format to standard output.
+func (w WalletCSVOutputWriter) WriteDiscoverOutput(accounts []wallet.Discovered) error {
+	csvWriter := csv.NewWriter(os.Stdout)
+	defer csvWriter.Flush()
+
+	if err := csvWriter.Write(discoverHeader()); err != nil {
+		return fmt.Errorf("writing CSV header: %w", err)
+	}
+
+	for i, account := range accounts {
+		if err := csvWriter.Write(discoverRecord(i, account)); err != nil {
+			return fmt.Errorf("writing CSV record for wallet #%d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
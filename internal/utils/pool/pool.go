@@ -0,0 +1,32 @@
+package pool
+
+import "sync"
+
+// Run calls fn for every index in [0, n) on at most workers goroutines, returning once every call is done. Indexes
+// are handed out in order, callers keep results deterministic by storing them at their index.
+func Run(n, workers int, fn func(i int)) {
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > n {
+		workers = n
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fn(i)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
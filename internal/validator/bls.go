@@ -0,0 +1,93 @@
+package validator
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+
+	blst "github.com/supranational/blst/bindings/go"
+)
+
+const (
+	// PublicKeyLength is the length of a compressed BLS12-381 G1 public key.
+	PublicKeyLength = 48
+	// SignatureLength is the length of a compressed BLS12-381 G2 signature.
+	SignatureLength = 96
+)
+
+// signatureDST is the domain separation tag of the proof of possession scheme used by Ethereum.
+var signatureDST = []byte("BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_")
+
+var (
+	errInvalidSecretKey = errors.New("invalid BLS secret key")
+	errInvalidPublicKey = errors.New("invalid BLS public key")
+	errInvalidSignature = errors.New("invalid BLS signature")
+)
+
+// SecretKey is a BLS12-381 secret key.
+type SecretKey struct {
+	sk *blst.SecretKey
+}
+
+// NewSecretKey returns the secret key for the given scalar.
+func NewSecretKey(scalar *big.Int) (*SecretKey, error) {
+	raw := make([]byte, 32)
+	if scalar.Sign() <= 0 || scalar.Cmp(curveOrder) >= 0 {
+		return nil, errInvalidSecretKey
+	}
+	scalar.FillBytes(raw)
+	return SecretKeyFromBytes(raw)
+}
+
+// SecretKeyFromBytes returns the secret key of the given 32 bytes big endian scalar.
+func SecretKeyFromBytes(raw []byte) (*SecretKey, error) {
+	sk := new(blst.SecretKey).Deserialize(raw)
+	if sk == nil || !sk.Valid() {
+		return nil, errInvalidSecretKey
+	}
+	return &SecretKey{sk: sk}, nil
+}
+
+// Bytes returns the 32 bytes big endian encoding of the secret key.
+func (k *SecretKey) Bytes() []byte {
+	return k.sk.Serialize()
+}
+
+// PublicKey returns the compressed 48 bytes public key of the secret key.
+func (k *SecretKey) PublicKey() []byte {
+	return new(blst.P1Affine).From(k.sk).Compress()
+}
+
+// Sign signs the message (usually a 32 bytes signing root), returning the compressed 96 bytes signature.
+func (k *SecretKey) Sign(msg []byte) []byte {
+	return new(blst.P2Affine).Sign(k.sk, msg, signatureDST).Compress()
+}
+
+// Verify checks the compressed signature of the message against the compressed public key.
+func Verify(publicKey, msg, signature []byte) error {
+	pk := new(blst.P1Affine).Uncompress(publicKey)
+	if pk == nil || !pk.KeyValidate() {
+		return errInvalidPublicKey
+	}
+	sig := new(blst.P2Affine).Uncompress(signature)
+	if sig == nil {
+		return errInvalidSignature
+	}
+	if !sig.Verify(true, pk, false, msg, signatureDST) {
+		return fmt.Errorf("%w: verification failed for public key 0x%s", errInvalidSignature, hex.EncodeToString(publicKey))
+	}
+	return nil
+}
+
+// ParsePubkey parses a hex encoded compressed public key, checking it's a valid BLS12-381 point.
+func ParsePubkey(raw string) ([]byte, error) {
+	pubkey, err := decodeHex(raw, PublicKeyLength)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key %q: %w", raw, err)
+	}
+	if pk := new(blst.P1Affine).Uncompress(pubkey); pk == nil || !pk.KeyValidate() {
+		return nil, fmt.Errorf("invalid public key %q: %w", raw, errInvalidPublicKey)
+	}
+	return pubkey, nil
+}
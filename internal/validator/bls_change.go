@@ -0,0 +1,93 @@
+package validator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// BLSToExecutionChange is the message changing 0x00 withdrawal credentials into 0x01 execution credentials.
+type BLSToExecutionChange struct {
+	ValidatorIndex     string `json:"validator_index"`
+	FromBLSPubkey      string `json:"from_bls_pubkey"`
+	ToExecutionAddress string `json:"to_execution_address"`
+}
+
+// BLSToExecutionChangeMetadata records the network a change was signed for, like the staking deposit CLI does.
+type BLSToExecutionChangeMetadata struct {
+	NetworkName           string `json:"network_name"`
+	GenesisValidatorsRoot string `json:"genesis_validators_root"`
+	DepositCLIVersion     string `json:"deposit_cli_version"`
+}
+
+// SignedBLSToExecutionChange is a signed BLS to execution change, ready to be submitted to a beacon node.
+type SignedBLSToExecutionChange struct {
+	Message   BLSToExecutionChange          `json:"message"`
+	Signature string                        `json:"signature"`
+	Metadata  *BLSToExecutionChangeMetadata `json:"metadata,omitempty"`
+}
+
+// NewSignedBLSToExecutionChange signs the change of the withdrawal credentials of the validator at validatorIndex
+// (its index in the beacon chain) to the given execution address. The change is signed with the withdrawal key.
+func NewSignedBLSToExecutionChange(withdrawalKey *SecretKey, validatorIndex uint64, address common.Address, network Network) *SignedBLSToExecutionChange {
+	pubkey := withdrawalKey.PublicKey()
+	root := sszContainer(sszUint64(validatorIndex), sszBytes(pubkey), sszBytes(address[:]))
+	// The domain is always computed with the genesis fork version so changes stay valid across forks
+	domain := ComputeDomain(DomainBLSToExecutionChange, network.GenesisForkVersion, network.GenesisValidatorsRoot)
+	signingRoot := ComputeSigningRoot(root, domain)
+
+	return &SignedBLSToExecutionChange{
+		Message: BLSToExecutionChange{
+			ValidatorIndex:     strconv.FormatUint(validatorIndex, 10),
+			FromBLSPubkey:      hexutil.Encode(pubkey),
+			ToExecutionAddress: address.Hex(),
+		},
+		Signature: hexutil.Encode(withdrawalKey.Sign(signingRoot[:])),
+		Metadata: &BLSToExecutionChangeMetadata{
+			NetworkName:           network.Name,
+			GenesisValidatorsRoot: hexutil.Encode(network.GenesisValidatorsRoot[:]),
+			DepositCLIVersion:     DepositCLIVersion,
+		},
+	}
+}
+
+// WriteBLSToExecutionChanges writes the changes into a new bls_to_execution_changes-*.json file in dir, returning
+// its path.
+func WriteBLSToExecutionChanges(dir string, changes []SignedBLSToExecutionChange, now time.Time) (string, error) {
+	content, err := json.Marshal(changes)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode BLS to execution changes: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("bls_to_execution_changes-%d.json", now.Unix()))
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		return "", fmt.Errorf("failed to write BLS to execution changes: %w", err)
+	}
+	return path, nil
+}
+
+// CheckBLSWithdrawalCredentials checks the 0x00 withdrawal credentials belong to the withdrawal public key.
+func CheckBLSWithdrawalCredentials(withdrawalPubkey []byte, credentials string) error {
+	expected := BLSWithdrawalCredentials(withdrawalPubkey)
+	decoded, err := decodeHex(credentials, len(expected))
+	if err != nil {
+		return fmt.Errorf("invalid withdrawal credentials %q: %w", credentials, err)
+	}
+	if decoded[0] != BLSWithdrawalPrefix {
+		return fmt.Errorf("withdrawal credentials %s are not BLS (0x00) credentials", credentials)
+	}
+	if !bytes.Equal(decoded, expected[:]) {
+		return fmt.Errorf("withdrawal credentials %s don't belong to withdrawal key 0x%x", credentials, withdrawalPubkey)
+	}
+	return nil
+}
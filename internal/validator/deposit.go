@@ -0,0 +1,237 @@
+package validator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// GweiPerEther is the number of gwei in one ether, deposit amounts are expressed in gwei.
+	GweiPerEther uint64 = 1_000_000_000
+
+	// MinDepositAmount is the smallest deposit accepted by the deposit contract.
+	MinDepositAmount = 1 * GweiPerEther
+	// MaxDepositAmount is the maximum effective balance of a validator with 0x00 or 0x01 withdrawal credentials.
+	MaxDepositAmount = 32 * GweiPerEther
+
+	// DepositCLIVersion is the staking deposit CLI version reported in deposit data, the launchpad rejects files
+	// from versions it doesn't know.
+	DepositCLIVersion = "2.7.0"
+
+	// Prefixes of the withdrawal credentials.
+	BLSWithdrawalPrefix       = 0x00
+	ExecutionWithdrawalPrefix = 0x01
+)
+
+// DepositData is an entry of a deposit_data-*.json file, as generated by the staking deposit CLI.
+type DepositData struct {
+	Pubkey                string `json:"pubkey"`
+	WithdrawalCredentials string `json:"withdrawal_credentials"`
+	Amount                uint64 `json:"amount"`
+	Signature             string `json:"signature"`
+	DepositMessageRoot    string `json:"deposit_message_root"`
+	DepositDataRoot       string `json:"deposit_data_root"`
+	ForkVersion           string `json:"fork_version"`
+	NetworkName           string `json:"network_name"`
+	DepositCLIVersion     string `json:"deposit_cli_version"`
+}
+
+// BLSWithdrawalCredentials returns the 0x00 withdrawal credentials of a withdrawal public key.
+func BLSWithdrawalCredentials(withdrawalPubkey []byte) [32]byte {
+	credentials := sha256.Sum256(withdrawalPubkey)
+	credentials[0] = BLSWithdrawalPrefix
+	return credentials
+}
+
+// ExecutionWithdrawalCredentials returns the 0x01 withdrawal credentials of an execution layer address.
+func ExecutionWithdrawalCredentials(address common.Address) [32]byte {
+	var credentials [32]byte
+	credentials[0] = ExecutionWithdrawalPrefix
+	copy(credentials[12:], address[:])
+	return credentials
+}
+
+// depositMessageRoot returns the hash tree root of the DepositMessage container.
+func depositMessageRoot(pubkey []byte, withdrawalCredentials [32]byte, amount uint64) [32]byte {
+	return sszContainer(sszBytes(pubkey), sszBytes(withdrawalCredentials[:]), sszUint64(amount))
+}
+
+// depositDataRoot returns the hash tree root of the DepositData container.
+func depositDataRoot(pubkey []byte, withdrawalCredentials [32]byte, amount uint64, signature []byte) [32]byte {
+	return sszContainer(sszBytes(pubkey), sszBytes(withdrawalCredentials[:]), sszUint64(amount), sszBytes(signature))
+}
+
+// depositDomain returns the signature domain of deposits, which only depends on the genesis fork version so
+// deposits can be made before genesis.
+func depositDomain(forkVersion [4]byte) [32]byte {
+	return ComputeDomain(DomainDeposit, forkVersion, [32]byte{})
+}
+
+// NewDepositData signs a deposit of amount gwei for the validator key on the given network.
+func NewDepositData(key *SecretKey, withdrawalCredentials [32]byte, amount uint64, network Network) (*DepositData, error) {
+	if amount < MinDepositAmount || amount > MaxDepositAmount {
+		return nil, fmt.Errorf("invalid deposit amount %d gwei: expected between %d and %d gwei", amount, MinDepositAmount, MaxDepositAmount)
+	}
+
+	pubkey := key.PublicKey()
+	messageRoot := depositMessageRoot(pubkey, withdrawalCredentials, amount)
+	signingRoot := ComputeSigningRoot(messageRoot, depositDomain(network.GenesisForkVersion))
+	signature := key.Sign(signingRoot[:])
+	dataRoot := depositDataRoot(pubkey, withdrawalCredentials, amount, signature)
+
+	return &DepositData{
+		Pubkey:                hex.EncodeToString(pubkey),
+		WithdrawalCredentials: hex.EncodeToString(withdrawalCredentials[:]),
+		Amount:                amount,
+		Signature:             hex.EncodeToString(signature),
+		DepositMessageRoot:    hex.EncodeToString(messageRoot[:]),
+		DepositDataRoot:       hex.EncodeToString(dataRoot[:]),
+		ForkVersion:           hex.EncodeToString(network.GenesisForkVersion[:]),
+		NetworkName:           network.Name,
+		DepositCLIVersion:     DepositCLIVersion,
+	}, nil
+}
+
+// DepositVerifyResult holds the problems found in a deposit data entry.
+type DepositVerifyResult struct {
+	File     string
+	Index    int
+	Deposit  DepositData
+	Problems []string
+}
+
+// OK reports whether the deposit is valid.
+func (r DepositVerifyResult) OK() bool {
+	return len(r.Problems) == 0
+}
+
+// VerifyDepositData checks the roots and signature of a deposit data entry, and that its network name matches its
+// fork version when the network is known.
+func VerifyDepositData(index int, deposit DepositData) DepositVerifyResult {
+	result := DepositVerifyResult{Index: index, Deposit: deposit}
+	problem := func(format string, args ...interface{}) {
+		result.Problems = append(result.Problems, fmt.Sprintf(format, args...))
+	}
+
+	pubkey, err := decodeHex(deposit.Pubkey, PublicKeyLength)
+	if err != nil {
+		problem("pubkey: %v", err)
+	}
+	rawCredentials, err := decodeHex(deposit.WithdrawalCredentials, 32)
+	if err != nil {
+		problem("withdrawal_credentials: %v", err)
+	}
+	signature, err := decodeHex(deposit.Signature, SignatureLength)
+	if err != nil {
+		problem("signature: %v", err)
+	}
+	forkVersion, err := ParseForkVersion(deposit.ForkVersion)
+	if err != nil {
+		problem("fork_version: %v", err)
+	}
+	if len(result.Problems) > 0 {
+		return result
+	}
+
+	var credentials [32]byte
+	copy(credentials[:], rawCredentials)
+	switch credentials[0] {
+	case BLSWithdrawalPrefix:
+	case ExecutionWithdrawalPrefix:
+		if !isZero(credentials[1:12]) {
+			problem("withdrawal_credentials: 0x01 credentials must be padded with zeros")
+		}
+	default:
+		problem("withdrawal_credentials: unsupported prefix 0x%02x", credentials[0])
+	}
+
+	if deposit.Amount < MinDepositAmount || deposit.Amount > MaxDepositAmount {
+		problem("amount: %d gwei is not between %d and %d gwei", deposit.Amount, MinDepositAmount, MaxDepositAmount)
+	}
+
+	if network, ok := NetworkByForkVersion(forkVersion); ok && network.Name != deposit.NetworkName {
+		problem("network_name: fork version %s belongs to %s, not %s", deposit.ForkVersion, network.Name, deposit.NetworkName)
+	}
+
+	messageRoot := depositMessageRoot(pubkey, credentials, deposit.Amount)
+	if hex.EncodeToString(messageRoot[:]) != strings.TrimPrefix(deposit.DepositMessageRoot, "0x") {
+		problem("deposit_message_root: expected %x", messageRoot)
+	}
+	dataRoot := depositDataRoot(pubkey, credentials, deposit.Amount, signature)
+	if hex.EncodeToString(dataRoot[:]) != strings.TrimPrefix(deposit.DepositDataRoot, "0x") {
+		problem("deposit_data_root: expected %x", dataRoot)
+	}
+
+	signingRoot := ComputeSigningRoot(messageRoot, depositDomain(forkVersion))
+	if err := Verify(pubkey, signingRoot[:], signature); err != nil {
+		problem("signature: %v", err)
+	}
+
+	return result
+}
+
+// DepositDataFileName returns the staking deposit CLI style name of a deposit data file.
+func DepositDataFileName(now time.Time) string {
+	return fmt.Sprintf("deposit_data-%d.json", now.Unix())
+}
+
+// WriteDepositData writes the deposits into a new deposit data file in dir, returning its path.
+func WriteDepositData(dir string, deposits []DepositData, now time.Time) (string, error) {
+	content, err := json.Marshal(deposits)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode deposit data: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	path := filepath.Join(dir, DepositDataFileName(now))
+	// Deposit data is public, the launchpad asks to upload it
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		return "", fmt.Errorf("failed to write deposit data: %w", err)
+	}
+	return path, nil
+}
+
+// ReadDepositData reads the entries of a deposit data file.
+func ReadDepositData(path string) ([]DepositData, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read deposit data: %w", err)
+	}
+
+	var deposits []DepositData
+	if err := json.Unmarshal(content, &deposits); err != nil {
+		return nil, fmt.Errorf("malformed deposit data: %w", err)
+	}
+	return deposits, nil
+}
+
+// decodeHex decodes a hex string, with or without 0x prefix, checking its length.
+func decodeHex(value string, length int) ([]byte, error) {
+	decoded, err := hex.DecodeString(strings.TrimPrefix(value, "0x"))
+	if err != nil {
+		return nil, err
+	}
+	if len(decoded) != length {
+		return nil, fmt.Errorf("expected %d bytes, got %d", length, len(decoded))
+	}
+	return decoded, nil
+}
+
+func isZero(b []byte) bool {
+	for _, v := range b {
+		if v != 0 {
+			return false
+		}
+	}
+	return true
+}
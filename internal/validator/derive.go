@@ -0,0 +1,154 @@
+// Package validator derives and stores the BLS12-381 keys of Ethereum consensus layer validators.
+package validator
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	// Purpose and coin type of EIP-2334 validator key paths.
+	eip2334Purpose  = 12381
+	eip2334CoinType = 3600
+
+	lamportChunks = 255
+)
+
+var (
+	// curveOrder is the order (r) of the BLS12-381 subgroup.
+	curveOrder, _ = new(big.Int).SetString("73eda753299d7d483339d80809a1d80553bda402fffe5bfeffffffff00000001", 16)
+
+	// keygenSalt is the initial salt of HKDF_mod_r as defined by the BLS signature draft.
+	keygenSalt = []byte("BLS-SIG-KEYGEN-SALT-")
+
+	errInvalidPath = errors.New("invalid EIP-2334 path")
+)
+
+// SigningKeyPath returns the EIP-2334 path of the signing key of the validator at index.
+func SigningKeyPath(index int) string {
+	return fmt.Sprintf("m/%d/%d/%d/0/0", eip2334Purpose, eip2334CoinType, index)
+}
+
+// WithdrawalKeyPath returns the EIP-2334 path of the withdrawal key of the validator at index.
+func WithdrawalKeyPath(index int) string {
+	return fmt.Sprintf("m/%d/%d/%d/0", eip2334Purpose, eip2334CoinType, index)
+}
+
+// DeriveMasterSK derives the EIP-2333 master secret key from a seed of at least 32 bytes.
+func DeriveMasterSK(seed []byte) (*big.Int, error) {
+	if len(seed) < 32 {
+		return nil, fmt.Errorf("seed must be at least 32 bytes, got %d", len(seed))
+	}
+	return hkdfModR(seed, nil)
+}
+
+// DeriveChildSK derives the EIP-2333 child secret key at index from its parent.
+func DeriveChildSK(parentSK *big.Int, index uint32) (*big.Int, error) {
+	lamportPK := parentSKToLamportPK(parentSK, index)
+	return hkdfModR(lamportPK, nil)
+}
+
+// DerivePath derives the secret key at the given EIP-2334 path (e.g. m/12381/3600/0/0/0) from a seed.
+func DerivePath(seed []byte, path string) (*big.Int, error) {
+	indexes, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	sk, err := DeriveMasterSK(seed)
+	if err != nil {
+		return nil, err
+	}
+	for _, index := range indexes {
+		if sk, err = DeriveChildSK(sk, index); err != nil {
+			return nil, err
+		}
+	}
+	return sk, nil
+}
+
+// parsePath parses an EIP-2334 path into its child indexes. Hardened indexes don't exist in EIP-2333.
+func parsePath(path string) ([]uint32, error) {
+	parts := strings.Split(strings.TrimSpace(path), "/")
+	if len(parts) < 1 || parts[0] != "m" {
+		return nil, fmt.Errorf("%w %q: it must start with m", errInvalidPath, path)
+	}
+
+	indexes := make([]uint32, 0, len(parts)-1)
+	for _, part := range parts[1:] {
+		index, err := strconv.ParseUint(part, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: %v", errInvalidPath, path, err)
+		}
+		indexes = append(indexes, uint32(index))
+	}
+	return indexes, nil
+}
+
+// hkdfModR derives a non-zero secret key from the key material, as defined by the BLS signature draft (KeyGen).
+func hkdfModR(ikm, keyInfo []byte) (*big.Int, error) {
+	const l = 48
+
+	salt := keygenSalt
+	sk := new(big.Int)
+	for sk.Sign() == 0 {
+		digest := sha256.Sum256(salt)
+		salt = digest[:]
+
+		prk := hkdf.Extract(sha256.New, append(append([]byte{}, ikm...), 0), salt)
+		info := append(append([]byte{}, keyInfo...), byte(l>>8), byte(l&0xff))
+
+		okm := make([]byte, l)
+		if _, err := io.ReadFull(hkdf.Expand(sha256.New, prk, info), okm); err != nil {
+			return nil, fmt.Errorf("failed to expand key material: %w", err)
+		}
+		sk.SetBytes(okm).Mod(sk, curveOrder)
+	}
+	return sk, nil
+}
+
+// ikmToLamportSK expands the key material into the 255 chunks of a Lamport secret key.
+func ikmToLamportSK(ikm, salt []byte) [][]byte {
+	prk := hkdf.Extract(sha256.New, ikm, salt)
+	okm := make([]byte, 32*lamportChunks)
+	// HKDF-Expand can't fail for an output shorter than 255 hash lengths
+	_, _ = io.ReadFull(hkdf.Expand(sha256.New, prk, nil), okm)
+
+	chunks := make([][]byte, lamportChunks)
+	for i := range chunks {
+		chunks[i] = okm[i*32 : (i+1)*32]
+	}
+	return chunks
+}
+
+// parentSKToLamportPK computes the compressed Lamport public key used as key material for a child key.
+func parentSKToLamportPK(parentSK *big.Int, index uint32) []byte {
+	salt := make([]byte, 4)
+	binary.BigEndian.PutUint32(salt, index)
+
+	ikm := make([]byte, 32)
+	parentSK.FillBytes(ikm)
+	notIKM := make([]byte, 32)
+	for i := range ikm {
+		notIKM[i] = ^ikm[i]
+	}
+
+	lamportPK := make([]byte, 0, 2*lamportChunks*32)
+	for _, lamportSK := range [][][]byte{ikmToLamportSK(ikm, salt), ikmToLamportSK(notIKM, salt)} {
+		for _, chunk := range lamportSK {
+			digest := sha256.Sum256(chunk)
+			lamportPK = append(lamportPK, digest[:]...)
+		}
+	}
+
+	compressed := sha256.Sum256(lamportPK)
+	return compressed[:]
+}
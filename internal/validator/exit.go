@@ -0,0 +1,66 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// VoluntaryExit is the message requesting the exit of a validator from the beacon chain.
+type VoluntaryExit struct {
+	Epoch          string `json:"epoch"`
+	ValidatorIndex string `json:"validator_index"`
+}
+
+// SignedVoluntaryExit is a signed voluntary exit, ready to be submitted to a beacon node.
+type SignedVoluntaryExit struct {
+	Message   VoluntaryExit `json:"message"`
+	Signature string        `json:"signature"`
+}
+
+// ExitResult is a signed voluntary exit together with the validator public key and the file it was written to.
+type ExitResult struct {
+	Pubkey string
+	Path   string
+	Exit   SignedVoluntaryExit
+}
+
+// NewSignedVoluntaryExit signs the exit of the validator at validatorIndex (its index in the beacon chain) with its
+// signing key. Since Deneb, exits are signed with the Capella fork version (EIP-7044) so they never expire.
+func NewSignedVoluntaryExit(signingKey *SecretKey, validatorIndex, epoch uint64, network Network) *SignedVoluntaryExit {
+	root := sszContainer(sszUint64(epoch), sszUint64(validatorIndex))
+	domain := ComputeDomain(DomainVoluntaryExit, network.CapellaForkVersion, network.GenesisValidatorsRoot)
+	signingRoot := ComputeSigningRoot(root, domain)
+
+	return &SignedVoluntaryExit{
+		Message: VoluntaryExit{
+			Epoch:          strconv.FormatUint(epoch, 10),
+			ValidatorIndex: strconv.FormatUint(validatorIndex, 10),
+		},
+		Signature: hexutil.Encode(signingKey.Sign(signingRoot[:])),
+	}
+}
+
+// WriteVoluntaryExit writes the exit into a new signed_exit_transaction-<index>-<timestamp>.json file in dir,
+// returning its path.
+func WriteVoluntaryExit(dir string, exit *SignedVoluntaryExit, now time.Time) (string, error) {
+	content, err := json.Marshal(exit)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode voluntary exit: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("signed_exit_transaction-%s-%d.json", exit.Message.ValidatorIndex, now.Unix()))
+	// A signed exit can be broadcast by anyone, keep it private until it's used
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		return "", fmt.Errorf("failed to write voluntary exit: %w", err)
+	}
+	return path, nil
+}
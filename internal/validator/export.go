@@ -0,0 +1,251 @@
+package validator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/aldoborrero/ethw/internal/keystore"
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+)
+
+// Consensus clients supported by ExportLayout.
+const (
+	ClientLighthouse = "lighthouse"
+	ClientPrysm      = "prysm"
+	ClientTeku       = "teku"
+	ClientNimbus     = "nimbus"
+	ClientLodestar   = "lodestar"
+)
+
+// prysmKDF mirrors the parameters used by Prysm to encrypt the accounts of its wallets.
+var prysmKDF = keystore.NewPBKDF2KDF(262144)
+
+// ExportKey is a validator keystore to export, with the password it's encrypted with.
+type ExportKey struct {
+	// Path is the file the keystore was read from, its content is copied as is.
+	Path     string
+	Keystore *Keystore
+	Password string
+	// SecretKey is the decrypted key, it's only required by clients which re-encrypt keys (Prysm).
+	SecretKey *SecretKey
+}
+
+// ExportResult describes the files written for a validator.
+type ExportResult struct {
+	Pubkey       string
+	KeystorePath string
+	PasswordPath string
+}
+
+// ClientNeedsSecretKey reports whether the client layout requires decrypted keys.
+func ClientNeedsSecretKey(client string) bool {
+	return client == ClientPrysm
+}
+
+// ExportLayout writes the keys into dir using the directory layout, password files and definitions expected by the
+// consensus client. Existing files are never overwritten.
+func ExportLayout(client, dir string, keys []ExportKey) ([]ExportResult, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve output directory: %w", err)
+	}
+
+	switch client {
+	case ClientLighthouse:
+		return exportLighthouse(absDir, keys)
+	case ClientPrysm:
+		return exportPrysm(absDir, keys)
+	case ClientTeku:
+		return exportFiles(absDir, keys, func(pubkey string) (string, string) {
+			return filepath.Join("keys", pubkey+".json"), filepath.Join("passwords", pubkey+".txt")
+		})
+	case ClientNimbus:
+		return exportFiles(absDir, keys, func(pubkey string) (string, string) {
+			return filepath.Join("validators", pubkey, "keystore.json"), filepath.Join("secrets", pubkey)
+		})
+	case ClientLodestar:
+		return exportFiles(absDir, keys, func(pubkey string) (string, string) {
+			return filepath.Join("keystores", pubkey, "voting-keystore.json"), filepath.Join("secrets", pubkey)
+		})
+	default:
+		return nil, fmt.Errorf("unsupported client: %q", client)
+	}
+}
+
+// exportFiles copies every keystore and writes its password file, at the paths returned by layout for the 0x
+// prefixed public key.
+func exportFiles(dir string, keys []ExportKey, layout func(pubkey string) (keystorePath, passwordPath string)) ([]ExportResult, error) {
+	results := make([]ExportResult, 0, len(keys))
+	for _, key := range keys {
+		pubkey := "0x" + strings.TrimPrefix(key.Keystore.Pubkey, "0x")
+		keystorePath, passwordPath := layout(pubkey)
+		result := ExportResult{
+			Pubkey:       pubkey,
+			KeystorePath: filepath.Join(dir, keystorePath),
+			PasswordPath: filepath.Join(dir, passwordPath),
+		}
+
+		content, err := os.ReadFile(key.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read keystore: %w", err)
+		}
+		if err := writeNewFile(result.KeystorePath, content, 0o600); err != nil {
+			return nil, err
+		}
+		if err := writeNewFile(result.PasswordPath, []byte(key.Password), 0o600); err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// lighthouseDefinition is an entry of Lighthouse's validator_definitions.yml.
+type lighthouseDefinition struct {
+	Enabled                    bool   `yaml:"enabled"`
+	VotingPublicKey            string `yaml:"voting_public_key"`
+	Description                string `yaml:"description"`
+	Type                       string `yaml:"type"`
+	VotingKeystorePath         string `yaml:"voting_keystore_path"`
+	VotingKeystorePasswordPath string `yaml:"voting_keystore_password_path"`
+}
+
+// exportLighthouse writes Lighthouse's validators/<pubkey>/voting-keystore.json and secrets/<pubkey> layout, plus the
+// validators/validator_definitions.yml file referencing them.
+func exportLighthouse(dir string, keys []ExportKey) ([]ExportResult, error) {
+	results, err := exportFiles(dir, keys, func(pubkey string) (string, string) {
+		return filepath.Join("validators", pubkey, "voting-keystore.json"), filepath.Join("secrets", pubkey)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	definitions := make([]lighthouseDefinition, len(results))
+	for i, result := range results {
+		definitions[i] = lighthouseDefinition{
+			Enabled:                    true,
+			VotingPublicKey:            result.Pubkey,
+			Type:                       "local_keystore",
+			VotingKeystorePath:         result.KeystorePath,
+			VotingKeystorePasswordPath: result.PasswordPath,
+		}
+	}
+	content, err := yaml.Marshal(definitions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode validator definitions: %w", err)
+	}
+	if err := writeNewFile(filepath.Join(dir, "validators", "validator_definitions.yml"), content, 0o600); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// prysmAccountStore is the plaintext of Prysm's all-accounts keystore.
+type prysmAccountStore struct {
+	PrivateKeys [][]byte `json:"private_keys"`
+	PublicKeys  [][]byte `json:"public_keys"`
+}
+
+// prysmAccountsKeystore is Prysm's all-accounts.keystore.json, an EIP-2335 crypto section wrapping every key.
+type prysmAccountsKeystore struct {
+	Crypto  KeystoreCrypto `json:"crypto"`
+	ID      string         `json:"uuid"`
+	Version int            `json:"version"`
+	Name    string         `json:"name"`
+}
+
+// exportPrysm writes a Prysm imported-keys wallet: every key encrypted together in direct/accounts/
+// all-accounts.keystore.json, with the password of the first key as wallet password.
+func exportPrysm(dir string, keys []ExportKey) ([]ExportResult, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("no keys to export")
+	}
+
+	store := prysmAccountStore{}
+	results := make([]ExportResult, 0, len(keys))
+	keystorePath := filepath.Join(dir, "direct", "accounts", "all-accounts.keystore.json")
+	passwordPath := filepath.Join(dir, "wallet-password.txt")
+	for _, key := range keys {
+		if key.SecretKey == nil {
+			return nil, fmt.Errorf("key %s must be decrypted to be exported to Prysm", key.Path)
+		}
+		store.PrivateKeys = append(store.PrivateKeys, key.SecretKey.Bytes())
+		store.PublicKeys = append(store.PublicKeys, key.SecretKey.PublicKey())
+		results = append(results, ExportResult{
+			Pubkey:       "0x" + strings.TrimPrefix(key.Keystore.Pubkey, "0x"),
+			KeystorePath: keystorePath,
+			PasswordPath: passwordPath,
+		})
+	}
+
+	plaintext, err := json.Marshal(store)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode accounts: %w", err)
+	}
+	walletPassword := keys[0].Password
+	crypto, err := encryptCrypto(plaintext, walletPassword, prysmKDF)
+	if err != nil {
+		return nil, err
+	}
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate keystore uuid: %w", err)
+	}
+	content, err := json.Marshal(prysmAccountsKeystore{Crypto: *crypto, ID: id.String(), Version: keystoreVersion, Name: "all-accounts"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode accounts keystore: %w", err)
+	}
+
+	if err := writeNewFile(keystorePath, content, 0o600); err != nil {
+		return nil, err
+	}
+	if err := writeNewFile(passwordPath, []byte(walletPassword), 0o600); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// writeNewFile writes a file, creating its parent directories but failing if it already exists.
+func writeNewFile(path string, content []byte, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return f.Close()
+}
+
+// KeystoreFiles returns the EIP-2335 keystores of the signing keys found in dir, sorted by path. Withdrawal key
+// keystores (m/12381/3600/i/0) are skipped.
+func KeystoreFiles(dir string) ([]string, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, "keystore-*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, path := range matches {
+		ks, err := ReadKeystore(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if indexes, err := parsePath(ks.Path); err == nil && len(indexes) == 4 {
+			continue
+		}
+		files = append(files, path)
+	}
+	sort.Strings(files)
+	return files, nil
+}
@@ -0,0 +1,269 @@
+package validator
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aldoborrero/ethw/internal/keystore"
+	"github.com/google/uuid"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	keystoreVersion  = 4
+	keystoreSaltLen  = 32
+	checksumFunction = "sha256"
+	cipherFunction   = "aes-128-ctr"
+)
+
+var (
+	// ErrInvalidChecksum is returned when the checksum of a keystore doesn't match, either because the password is
+	// wrong or because the ciphertext is corrupt.
+	ErrInvalidChecksum = errors.New("could not decrypt keystore with given password (checksum mismatch)")
+)
+
+// KeystoreModule is a module (kdf, checksum or cipher) of an EIP-2335 keystore.
+type KeystoreModule struct {
+	Function string                 `json:"function"`
+	Params   map[string]interface{} `json:"params"`
+	Message  string                 `json:"message"`
+}
+
+// KeystoreCrypto holds the modules used to encrypt the secret key of an EIP-2335 keystore.
+type KeystoreCrypto struct {
+	KDF      KeystoreModule `json:"kdf"`
+	Checksum KeystoreModule `json:"checksum"`
+	Cipher   KeystoreModule `json:"cipher"`
+}
+
+// Keystore is an EIP-2335 BLS12-381 keystore, as consumed by every consensus layer client.
+type Keystore struct {
+	Crypto      KeystoreCrypto `json:"crypto"`
+	Description string         `json:"description"`
+	Pubkey      string         `json:"pubkey"`
+	Path        string         `json:"path"`
+	UUID        string         `json:"uuid"`
+	Version     int            `json:"version"`
+}
+
+// NormalizePassword prepares a password as required by EIP-2335: NFKD normalized, without control codes.
+func NormalizePassword(password string) []byte {
+	normalized := norm.NFKD.String(password)
+	return []byte(strings.Map(func(r rune) rune {
+		if r < 0x20 || (r >= 0x7f && r <= 0x9f) {
+			return -1
+		}
+		return r
+	}, normalized))
+}
+
+// EncryptKeystore encrypts the secret key into an EIP-2335 keystore using the given KDF.
+func EncryptKeystore(sk *SecretKey, password string, kdf keystore.KDF, path, description string) (*Keystore, error) {
+	crypto, err := encryptCrypto(sk.Bytes(), password, kdf)
+	if err != nil {
+		return nil, err
+	}
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate keystore uuid: %w", err)
+	}
+	return newKeystore(sk, crypto, id, path, description), nil
+}
+
+// newKeystore returns the keystore of the secret key, already encrypted into crypto.
+func newKeystore(sk *SecretKey, crypto *KeystoreCrypto, id uuid.UUID, path, description string) *Keystore {
+	return &Keystore{
+		Crypto:      *crypto,
+		Description: description,
+		Pubkey:      hex.EncodeToString(sk.PublicKey()),
+		Path:        path,
+		UUID:        id.String(),
+		Version:     keystoreVersion,
+	}
+}
+
+// encryptCrypto encrypts the secret with a random salt and iv.
+func encryptCrypto(secret []byte, password string, kdf keystore.KDF) (*KeystoreCrypto, error) {
+	salt := make([]byte, keystoreSaltLen)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	iv := make([]byte, aes.BlockSize)
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return nil, fmt.Errorf("failed to generate iv: %w", err)
+	}
+	return encryptCryptoWith(secret, password, kdf, salt, iv)
+}
+
+// encryptCryptoWith encrypts the secret with the given salt and iv into the crypto section of a keystore.
+func encryptCryptoWith(secret []byte, password string, kdf keystore.KDF, salt, iv []byte) (*KeystoreCrypto, error) {
+	if err := kdf.Validate(); err != nil {
+		return nil, err
+	}
+
+	derivedKey, err := kdf.DeriveKey(NormalizePassword(password), salt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+
+	block, err := aes.NewCipher(derivedKey[:16])
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize cipher: %w", err)
+	}
+	cipherText := make([]byte, len(secret))
+	cipher.NewCTR(block, iv).XORKeyStream(cipherText, secret)
+
+	return &KeystoreCrypto{
+		KDF: KeystoreModule{
+			Function: kdf.Name,
+			Params:   kdf.Params(salt),
+			Message:  "",
+		},
+		Checksum: KeystoreModule{
+			Function: checksumFunction,
+			Params:   map[string]interface{}{},
+			Message:  hex.EncodeToString(checksum(derivedKey, cipherText)),
+		},
+		Cipher: KeystoreModule{
+			Function: cipherFunction,
+			Params:   map[string]interface{}{"iv": hex.EncodeToString(iv)},
+			Message:  hex.EncodeToString(cipherText),
+		},
+	}, nil
+}
+
+// checksum computes the EIP-2335 checksum of the ciphertext.
+func checksum(derivedKey, cipherText []byte) []byte {
+	digest := sha256.Sum256(append(append([]byte{}, derivedKey[16:32]...), cipherText...))
+	return digest[:]
+}
+
+// ReadKeystore reads and parses the EIP-2335 keystore at the given path.
+func ReadKeystore(path string) (*Keystore, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore: %w", err)
+	}
+
+	var ks Keystore
+	if err := json.Unmarshal(content, &ks); err != nil {
+		return nil, fmt.Errorf("malformed keystore: %w", err)
+	}
+	if ks.Version != keystoreVersion {
+		return nil, fmt.Errorf("unsupported keystore version: %d", ks.Version)
+	}
+	return &ks, nil
+}
+
+// Decrypt derives the key from the password, verifies the checksum and decrypts the secret key. The decrypted key
+// must match the public key of the keystore.
+func (ks *Keystore) Decrypt(password string) (*SecretKey, error) {
+	secret, err := decryptCrypto(&ks.Crypto, password)
+	if err != nil {
+		return nil, err
+	}
+
+	sk, err := SecretKeyFromBytes(secret)
+	if err != nil {
+		return nil, err
+	}
+	if pubkey := hex.EncodeToString(sk.PublicKey()); ks.Pubkey != "" && pubkey != strings.TrimPrefix(ks.Pubkey, "0x") {
+		return nil, fmt.Errorf("decrypted key belongs to public key 0x%s, not 0x%s", pubkey, ks.Pubkey)
+	}
+	return sk, nil
+}
+
+// decryptCrypto derives the key from the password, verifies the checksum and decrypts the secret.
+func decryptCrypto(crypto *KeystoreCrypto, password string) ([]byte, error) {
+	if crypto.Checksum.Function != checksumFunction {
+		return nil, fmt.Errorf("unsupported checksum function: %q", crypto.Checksum.Function)
+	}
+	if crypto.Cipher.Function != cipherFunction {
+		return nil, fmt.Errorf("unsupported cipher: %q", crypto.Cipher.Function)
+	}
+
+	kdf, salt, err := keystore.ParseKDFParams(crypto.KDF.Function, crypto.KDF.Params)
+	if err != nil {
+		return nil, err
+	}
+	rawIV, _ := crypto.Cipher.Params["iv"].(string)
+	iv, err := hex.DecodeString(rawIV)
+	if err != nil || len(iv) != aes.BlockSize {
+		return nil, fmt.Errorf("invalid cipher.params.iv: %q", rawIV)
+	}
+	cipherText, err := hex.DecodeString(crypto.Cipher.Message)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cipher.message: %w", err)
+	}
+	expected, err := hex.DecodeString(crypto.Checksum.Message)
+	if err != nil {
+		return nil, fmt.Errorf("invalid checksum.message: %w", err)
+	}
+
+	derivedKey, err := kdf.DeriveKey(NormalizePassword(password), salt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	if !bytes.Equal(checksum(derivedKey, cipherText), expected) {
+		return nil, ErrInvalidChecksum
+	}
+
+	block, err := aes.NewCipher(derivedKey[:16])
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize cipher: %w", err)
+	}
+	secret := make([]byte, len(cipherText))
+	cipher.NewCTR(block, iv).XORKeyStream(secret, cipherText)
+	return secret, nil
+}
+
+// KeystoreFileName returns the staking-deposit-cli style file name of a keystore, e.g.
+// keystore-m_12381_3600_0_0_0-1700000000.json.
+func KeystoreFileName(path string, now time.Time) string {
+	return fmt.Sprintf("keystore-%s-%d.json", strings.ReplaceAll(path, "/", "_"), now.Unix())
+}
+
+// WriteKeystore writes the keystore into dir, returning the path of the new file. An existing file is never
+// overwritten.
+func WriteKeystore(dir string, ks *Keystore, now time.Time) (string, error) {
+	content, err := json.Marshal(ks)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode keystore: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create keystore directory: %w", err)
+	}
+
+	path := filepath.Join(dir, KeystoreFileName(ks.Path, now))
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return "", fmt.Errorf("failed to create keystore: %w", err)
+	}
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("failed to write keystore: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("failed to write keystore: %w", err)
+	}
+	// Linking rather than renaming the complete file into place never overwrites an existing keystore
+	defer os.Remove(tmp.Name())
+	if err := os.Link(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("failed to write keystore: %w", err)
+	}
+	return path, nil
+}
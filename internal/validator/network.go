@@ -0,0 +1,118 @@
+package validator
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Domain types of the messages signed by ethw.
+var (
+	DomainDeposit              = [4]byte{0x03, 0x00, 0x00, 0x00}
+	DomainVoluntaryExit        = [4]byte{0x04, 0x00, 0x00, 0x00}
+	DomainBLSToExecutionChange = [4]byte{0x0a, 0x00, 0x00, 0x00}
+)
+
+// CustomNetwork is the name of networks given by their fork parameters.
+const CustomNetwork = "custom"
+
+// Network holds the fork parameters of a consensus layer network needed to sign messages offline.
+type Network struct {
+	Name                  string
+	GenesisForkVersion    [4]byte
+	GenesisValidatorsRoot [32]byte
+	// CapellaForkVersion signs voluntary exits, which are locked to the Capella domain since Deneb (EIP-7044).
+	CapellaForkVersion [4]byte
+}
+
+// Networks holds the parameters of the public networks supported by the staking launchpad.
+var Networks = map[string]Network{
+	"mainnet": {
+		Name:                  "mainnet",
+		GenesisForkVersion:    [4]byte{0x00, 0x00, 0x00, 0x00},
+		GenesisValidatorsRoot: mustRoot("4b363db94e286120d76eb905340fdd4e54bfe9f06bf33ff6cf5ad27f511bfe95"),
+		CapellaForkVersion:    [4]byte{0x03, 0x00, 0x00, 0x00},
+	},
+	"holesky": {
+		Name:                  "holesky",
+		GenesisForkVersion:    [4]byte{0x01, 0x01, 0x70, 0x00},
+		GenesisValidatorsRoot: mustRoot("9143aa7c615a7f7115e2b6aac319c03529df8242ae705fba9df39b79c59fa8b1"),
+		CapellaForkVersion:    [4]byte{0x04, 0x01, 0x70, 0x00},
+	},
+	"sepolia": {
+		Name:                  "sepolia",
+		GenesisForkVersion:    [4]byte{0x90, 0x00, 0x00, 0x69},
+		GenesisValidatorsRoot: mustRoot("d8ea171f3c94aea21ebc42a1ed61052acf3f9209c00e4efbaaddac09ed9b8078"),
+		CapellaForkVersion:    [4]byte{0x90, 0x00, 0x00, 0x72},
+	},
+}
+
+// NetworkNames returns the names of the known networks, sorted.
+func NetworkNames() []string {
+	names := make([]string, 0, len(Networks))
+	for name := range Networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NetworkByForkVersion returns the known network with the given genesis fork version.
+func NetworkByForkVersion(version [4]byte) (Network, bool) {
+	for _, network := range Networks {
+		if network.GenesisForkVersion == version {
+			return network, true
+		}
+	}
+	return Network{}, false
+}
+
+// ParseForkVersion parses a hex encoded 4 bytes fork version, e.g. 0x10000038.
+func ParseForkVersion(raw string) ([4]byte, error) {
+	var version [4]byte
+	decoded, err := hex.DecodeString(strings.TrimPrefix(raw, "0x"))
+	if err != nil || len(decoded) != len(version) {
+		return version, fmt.Errorf("invalid fork version %q: expected 4 hex encoded bytes", raw)
+	}
+	copy(version[:], decoded)
+	return version, nil
+}
+
+// ParseRoot parses a hex encoded 32 bytes root, e.g. a genesis validators root.
+func ParseRoot(raw string) ([32]byte, error) {
+	var root [32]byte
+	decoded, err := hex.DecodeString(strings.TrimPrefix(raw, "0x"))
+	if err != nil || len(decoded) != len(root) {
+		return root, fmt.Errorf("invalid root %q: expected 32 hex encoded bytes", raw)
+	}
+	copy(root[:], decoded)
+	return root, nil
+}
+
+func mustRoot(raw string) [32]byte {
+	root, err := ParseRoot(raw)
+	if err != nil {
+		panic(err)
+	}
+	return root
+}
+
+// ComputeForkDataRoot returns the hash tree root of the ForkData container.
+func ComputeForkDataRoot(forkVersion [4]byte, genesisValidatorsRoot [32]byte) [32]byte {
+	return sszContainer(sszBytes(forkVersion[:]), sszBytes(genesisValidatorsRoot[:]))
+}
+
+// ComputeDomain returns the signature domain of the domain type for a fork.
+func ComputeDomain(domainType [4]byte, forkVersion [4]byte, genesisValidatorsRoot [32]byte) [32]byte {
+	var domain [32]byte
+	forkDataRoot := ComputeForkDataRoot(forkVersion, genesisValidatorsRoot)
+	copy(domain[:4], domainType[:])
+	copy(domain[4:], forkDataRoot[:28])
+	return domain
+}
+
+// ComputeSigningRoot returns the root signed for an object, i.e. the hash tree root of the SigningData container.
+func ComputeSigningRoot(objectRoot [32]byte, domain [32]byte) [32]byte {
+	return sszContainer(objectRoot, domain)
+}
@@ -0,0 +1,297 @@
+package validator
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// InterchangeFormatVersion is the version of the EIP-3076 slashing protection interchange format.
+const InterchangeFormatVersion = "5"
+
+// InterchangeMetadata identifies the format and the network of an interchange file.
+type InterchangeMetadata struct {
+	InterchangeFormatVersion string `json:"interchange_format_version"`
+	GenesisValidatorsRoot    string `json:"genesis_validators_root"`
+}
+
+// SignedBlock is a block proposal recorded in an interchange file.
+type SignedBlock struct {
+	Slot        string `json:"slot"`
+	SigningRoot string `json:"signing_root,omitempty"`
+}
+
+// SignedAttestation is an attestation recorded in an interchange file.
+type SignedAttestation struct {
+	SourceEpoch string `json:"source_epoch"`
+	TargetEpoch string `json:"target_epoch"`
+	SigningRoot string `json:"signing_root,omitempty"`
+}
+
+// InterchangeValidator holds the slashing protection history of a validator.
+type InterchangeValidator struct {
+	Pubkey             string              `json:"pubkey"`
+	SignedBlocks       []SignedBlock       `json:"signed_blocks"`
+	SignedAttestations []SignedAttestation `json:"signed_attestations"`
+}
+
+// Interchange is an EIP-3076 slashing protection interchange file, used to move validators between clients without
+// risking a slashable double vote or proposal.
+type Interchange struct {
+	Metadata InterchangeMetadata    `json:"metadata"`
+	Data     []InterchangeValidator `json:"data"`
+}
+
+// InterchangeWatermark is the highest slot and epochs signed by a validator, as recorded in an interchange file.
+// Maximums are only meaningful when the validator has signed blocks or attestations.
+type InterchangeWatermark struct {
+	Pubkey         string
+	Blocks         int
+	MaxSlot        uint64
+	Attestations   int
+	MaxSourceEpoch uint64
+	MaxTargetEpoch uint64
+}
+
+// InterchangeValidateResult holds the problems found in an interchange file.
+type InterchangeValidateResult struct {
+	File       string
+	Validators int
+	Problems   []string
+}
+
+// OK reports whether the interchange file is valid.
+func (r InterchangeValidateResult) OK() bool {
+	return len(r.Problems) == 0
+}
+
+// NewInterchange returns an interchange file without any signed message for the validators, as used when they
+// haven't signed anything yet.
+func NewInterchange(genesisValidatorsRoot [32]byte, pubkeys []string) *Interchange {
+	ic := &Interchange{
+		Metadata: InterchangeMetadata{
+			InterchangeFormatVersion: InterchangeFormatVersion,
+			GenesisValidatorsRoot:    "0x" + hex.EncodeToString(genesisValidatorsRoot[:]),
+		},
+		Data: make([]InterchangeValidator, len(pubkeys)),
+	}
+	for i, pubkey := range pubkeys {
+		ic.Data[i] = InterchangeValidator{
+			Pubkey:             normalizeHex(pubkey),
+			SignedBlocks:       []SignedBlock{},
+			SignedAttestations: []SignedAttestation{},
+		}
+	}
+	return ic
+}
+
+// ReadInterchange reads and parses the interchange file at the given path.
+func ReadInterchange(path string) (*Interchange, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read interchange file: %w", err)
+	}
+
+	var ic Interchange
+	if err := json.Unmarshal(content, &ic); err != nil {
+		return nil, fmt.Errorf("malformed interchange file %s: %w", path, err)
+	}
+	return &ic, nil
+}
+
+// WriteInterchange writes the interchange into a new file at path. Existing files are never overwritten, as replacing
+// the history of a validator with an older one could get it slashed.
+func WriteInterchange(path string, ic *Interchange) error {
+	content, err := json.Marshal(ic)
+	if err != nil {
+		return fmt.Errorf("failed to encode interchange file: %w", err)
+	}
+	return writeNewFile(path, content, 0o600)
+}
+
+// ValidateInterchange checks the format version, the genesis validators root, the public keys, slots, epochs and
+// signing roots of an interchange file. When pubkeys is given, every one of them must be present in the file.
+func ValidateInterchange(ic *Interchange, pubkeys []string) []string {
+	var problems []string
+	problem := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if ic.Metadata.InterchangeFormatVersion != InterchangeFormatVersion {
+		problem("interchange_format_version: expected %q, got %q", InterchangeFormatVersion, ic.Metadata.InterchangeFormatVersion)
+	}
+	if _, err := decodeHex(ic.Metadata.GenesisValidatorsRoot, 32); err != nil {
+		problem("genesis_validators_root: %v", err)
+	}
+
+	present := make(map[string]bool, len(ic.Data))
+	for i, v := range ic.Data {
+		if _, err := decodeHex(v.Pubkey, PublicKeyLength); err != nil {
+			problem("data[%d].pubkey: %v", i, err)
+		}
+		present[normalizeHex(v.Pubkey)] = true
+
+		for j, block := range v.SignedBlocks {
+			if _, err := strconv.ParseUint(block.Slot, 10, 64); err != nil {
+				problem("data[%d].signed_blocks[%d].slot: invalid slot %q", i, j, block.Slot)
+			}
+			if block.SigningRoot != "" {
+				if _, err := decodeHex(block.SigningRoot, 32); err != nil {
+					problem("data[%d].signed_blocks[%d].signing_root: %v", i, j, err)
+				}
+			}
+		}
+
+		for j, attestation := range v.SignedAttestations {
+			source, sourceErr := strconv.ParseUint(attestation.SourceEpoch, 10, 64)
+			if sourceErr != nil {
+				problem("data[%d].signed_attestations[%d].source_epoch: invalid epoch %q", i, j, attestation.SourceEpoch)
+			}
+			target, targetErr := strconv.ParseUint(attestation.TargetEpoch, 10, 64)
+			if targetErr != nil {
+				problem("data[%d].signed_attestations[%d].target_epoch: invalid epoch %q", i, j, attestation.TargetEpoch)
+			}
+			// Epochs that don't parse are already reported, comparing them would report a bogus ordering too
+			if sourceErr == nil && targetErr == nil && source > target {
+				problem("data[%d].signed_attestations[%d]: source epoch %d is after target epoch %d", i, j, source, target)
+			}
+			if attestation.SigningRoot != "" {
+				if _, err := decodeHex(attestation.SigningRoot, 32); err != nil {
+					problem("data[%d].signed_attestations[%d].signing_root: %v", i, j, err)
+				}
+			}
+		}
+	}
+
+	for _, pubkey := range pubkeys {
+		if !present[normalizeHex(pubkey)] {
+			problem("validator %s is missing", normalizeHex(pubkey))
+		}
+	}
+	return problems
+}
+
+// MergeInterchanges merges interchange files of the same network into a minimal one: for every validator, a single
+// block at the highest slot signed and a single attestation with the highest source and target epochs signed, across
+// every file. Clients refuse to sign at or below these watermarks, which is enough to prevent slashable messages.
+// Validators are kept in the order they first appear in.
+func MergeInterchanges(ics []*Interchange) (*Interchange, error) {
+	if len(ics) == 0 {
+		return nil, errors.New("no interchange files to merge")
+	}
+
+	var root string
+	var order []string
+	watermarks := make(map[string]*InterchangeWatermark)
+	for i, ic := range ics {
+		if problems := ValidateInterchange(ic, nil); len(problems) > 0 {
+			return nil, fmt.Errorf("invalid interchange file #%d: %s", i+1, strings.Join(problems, "; "))
+		}
+		icRoot := normalizeHex(ic.Metadata.GenesisValidatorsRoot)
+		if root == "" {
+			root = icRoot
+		} else if icRoot != root {
+			return nil, fmt.Errorf("interchange file #%d is for genesis validators root %s, not %s", i+1, icRoot, root)
+		}
+
+		for _, w := range Watermarks(ic) {
+			merged, ok := watermarks[w.Pubkey]
+			if !ok {
+				merged = &InterchangeWatermark{Pubkey: w.Pubkey}
+				watermarks[w.Pubkey] = merged
+				order = append(order, w.Pubkey)
+			}
+			mergeWatermark(merged, w)
+		}
+	}
+
+	merged := &Interchange{
+		Metadata: InterchangeMetadata{InterchangeFormatVersion: InterchangeFormatVersion, GenesisValidatorsRoot: root},
+		Data:     make([]InterchangeValidator, len(order)),
+	}
+	for i, pubkey := range order {
+		w := watermarks[pubkey]
+		v := InterchangeValidator{Pubkey: pubkey, SignedBlocks: []SignedBlock{}, SignedAttestations: []SignedAttestation{}}
+		if w.Blocks > 0 {
+			v.SignedBlocks = append(v.SignedBlocks, SignedBlock{Slot: strconv.FormatUint(w.MaxSlot, 10)})
+		}
+		if w.Attestations > 0 {
+			v.SignedAttestations = append(v.SignedAttestations, SignedAttestation{
+				SourceEpoch: strconv.FormatUint(w.MaxSourceEpoch, 10),
+				TargetEpoch: strconv.FormatUint(w.MaxTargetEpoch, 10),
+			})
+		}
+		merged.Data[i] = v
+	}
+	return merged, nil
+}
+
+// MinifyInterchange returns the minimal form of an interchange file, see MergeInterchanges.
+func MinifyInterchange(ic *Interchange) (*Interchange, error) {
+	return MergeInterchanges([]*Interchange{ic})
+}
+
+// Watermarks returns the watermark of every validator of a valid interchange file, in the order they first appear
+// in. Validators listed several times are combined, and entries whose slot or epochs don't parse are left out rather
+// than counted as zero.
+func Watermarks(ic *Interchange) []InterchangeWatermark {
+	var order []string
+	watermarks := make(map[string]*InterchangeWatermark)
+	for _, v := range ic.Data {
+		pubkey := normalizeHex(v.Pubkey)
+		w, ok := watermarks[pubkey]
+		if !ok {
+			w = &InterchangeWatermark{Pubkey: pubkey}
+			watermarks[pubkey] = w
+			order = append(order, pubkey)
+		}
+
+		for _, block := range v.SignedBlocks {
+			slot, err := strconv.ParseUint(block.Slot, 10, 64)
+			if err != nil {
+				continue
+			}
+			mergeWatermark(w, InterchangeWatermark{Blocks: 1, MaxSlot: slot})
+		}
+		for _, attestation := range v.SignedAttestations {
+			source, sourceErr := strconv.ParseUint(attestation.SourceEpoch, 10, 64)
+			target, targetErr := strconv.ParseUint(attestation.TargetEpoch, 10, 64)
+			if sourceErr != nil || targetErr != nil {
+				continue
+			}
+			mergeWatermark(w, InterchangeWatermark{Attestations: 1, MaxSourceEpoch: source, MaxTargetEpoch: target})
+		}
+	}
+
+	result := make([]InterchangeWatermark, len(order))
+	for i, pubkey := range order {
+		result[i] = *watermarks[pubkey]
+	}
+	return result
+}
+
+// mergeWatermark raises the watermark w to the maximums of other.
+func mergeWatermark(w *InterchangeWatermark, other InterchangeWatermark) {
+	if other.Blocks > 0 && (w.Blocks == 0 || other.MaxSlot > w.MaxSlot) {
+		w.MaxSlot = other.MaxSlot
+	}
+	if other.Attestations > 0 {
+		if w.Attestations == 0 || other.MaxSourceEpoch > w.MaxSourceEpoch {
+			w.MaxSourceEpoch = other.MaxSourceEpoch
+		}
+		if w.Attestations == 0 || other.MaxTargetEpoch > w.MaxTargetEpoch {
+			w.MaxTargetEpoch = other.MaxTargetEpoch
+		}
+	}
+	w.Blocks += other.Blocks
+	w.Attestations += other.Attestations
+}
+
+// normalizeHex returns the lower case, 0x prefixed form of a hex encoded value.
+func normalizeHex(pubkey string) string {
+	return "0x" + strings.ToLower(strings.TrimPrefix(pubkey, "0x"))
+}
@@ -0,0 +1,53 @@
+package validator
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// The consensus layer signs the SSZ hash tree root of its messages. Every message signed by ethw is a container of
+// fixed size fields, so only the few SSZ types they use are implemented here.
+
+// sszUint64 returns the hash tree root of a uint64.
+func sszUint64(v uint64) [32]byte {
+	var chunk [32]byte
+	binary.LittleEndian.PutUint64(chunk[:], v)
+	return chunk
+}
+
+// sszBytes returns the hash tree root of a fixed size byte vector (Bytes4, Bytes20, Bytes32, Bytes48, Bytes96).
+func sszBytes(b []byte) [32]byte {
+	chunks := make([][32]byte, (len(b)+31)/32)
+	for i := range chunks {
+		copy(chunks[i][:], b[i*32:])
+	}
+	return merkleize(chunks)
+}
+
+// sszContainer returns the hash tree root of a container given the hash tree roots of its fields.
+func sszContainer(fields ...[32]byte) [32]byte {
+	return merkleize(fields)
+}
+
+// merkleize computes the merkle root of the chunks, padded with zero chunks to the next power of two.
+func merkleize(chunks [][32]byte) [32]byte {
+	if len(chunks) == 0 {
+		return [32]byte{}
+	}
+
+	size := 1
+	for size < len(chunks) {
+		size *= 2
+	}
+	layer := make([][32]byte, size)
+	copy(layer, chunks)
+
+	for len(layer) > 1 {
+		next := make([][32]byte, len(layer)/2)
+		for i := range next {
+			next[i] = sha256.Sum256(append(layer[2*i][:], layer[2*i+1][:]...))
+		}
+		layer = next
+	}
+	return layer[0]
+}
@@ -0,0 +1,83 @@
+package validator
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/tyler-smith/go-bip39"
+)
+
+// Key holds the signing and withdrawal keys of a validator, derived from a mnemonic.
+type Key struct {
+	Index          int
+	SigningPath    string
+	SigningKey     *SecretKey
+	WithdrawalPath string
+	WithdrawalKey  *SecretKey
+}
+
+// Pubkey returns the hex encoded public key of the signing key.
+func (k *Key) Pubkey() string {
+	return hex.EncodeToString(k.SigningKey.PublicKey())
+}
+
+// WithdrawalPubkey returns the hex encoded public key of the withdrawal key.
+func (k *Key) WithdrawalPubkey() string {
+	return hex.EncodeToString(k.WithdrawalKey.PublicKey())
+}
+
+// NewKey derives the keys of the validator at index from the seed.
+func NewKey(seed []byte, index int) (*Key, error) {
+	key := &Key{
+		Index:          index,
+		SigningPath:    SigningKeyPath(index),
+		WithdrawalPath: WithdrawalKeyPath(index),
+	}
+
+	withdrawal, err := DerivePath(seed, key.WithdrawalPath)
+	if err != nil {
+		return nil, err
+	}
+	// The signing key is the child 0 of the withdrawal key, there's no need to derive the path again
+	signing, err := DeriveChildSK(withdrawal, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if key.WithdrawalKey, err = NewSecretKey(withdrawal); err != nil {
+		return nil, err
+	}
+	if key.SigningKey, err = NewSecretKey(signing); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// NewKeys derives the keys of the validators at every index of the inclusive range from the mnemonic.
+func NewKeys(mnemonic, passphrase string, start, end int) ([]*Key, error) {
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mnemonic: %w", err)
+	}
+
+	keys := make([]*Key, 0, end-start+1)
+	for index := start; index <= end; index++ {
+		key, err := NewKey(seed, index)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive validator %d: %w", index, err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// CreateResult is the outcome of writing the keystores of a validator.
+type CreateResult struct {
+	Index                  int
+	Pubkey                 string
+	SigningPath            string
+	KeystorePath           string
+	WithdrawalPubkey       string
+	WithdrawalPath         string
+	WithdrawalKeystorePath string
+}
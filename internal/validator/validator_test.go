@@ -0,0 +1,250 @@
+package validator
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aldoborrero/ethw/internal/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ValidatorTestSuite struct {
+	suite.Suite
+}
+
+func TestValidatorTestSuite(t *testing.T) {
+	suite.Run(t, new(ValidatorTestSuite))
+}
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// TestDeriveEIP2333 checks the first test case of EIP-2333.
+func (suite *ValidatorTestSuite) TestDeriveEIP2333() {
+	seed := mustHex("c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04")
+
+	master, err := DeriveMasterSK(seed)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "6083874454709270928345386274498605044986640685124978867557563392430687146096", master.String())
+
+	child, err := DeriveChildSK(master, 0)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "20397789859736650942317412262472558107875392172444076792671091975210932703118", child.String())
+
+	derived, err := DerivePath(seed, "m/0")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), child, derived)
+
+	_, err = DerivePath(seed, "m/12381'/3600")
+	assert.Error(suite.T(), err)
+}
+
+// TestKeystoreEIP2335 checks the scrypt test vector of EIP-2335 and a round trip through a file.
+func (suite *ValidatorTestSuite) TestKeystoreEIP2335() {
+	password := "\U0001d531\U0001d522\U0001d530\U0001d531\U0001d52d\U0001d51e\U0001d530\U0001d530\U0001d534\U0001d52c\U0001d52f\U0001d521\U0001f511"
+	sk, err := SecretKeyFromBytes(mustHex("000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f"))
+	assert.NoError(suite.T(), err)
+
+	kdf := keystore.NewScryptKDF(262144, 8, 1)
+	salt := mustHex("d4e56740f876aef8c010b86a40d5f56745a118d0906a34e69aec8c0db1cb8fa3")
+	iv := mustHex("264daa3f303d7259501c93d997d84fe6")
+	crypto, err := encryptCryptoWith(sk.Bytes(), password, kdf, salt, iv)
+	assert.NoError(suite.T(), err)
+	ks := newKeystore(sk, crypto, uuid.New(), "m/12381/60/3141592653/589793238", "")
+	assert.Equal(suite.T(), "d2217fe5f3e9a1e34581ef8a78f7c9928e436d36dacc5e846690a5581e8ea484", ks.Crypto.Checksum.Message)
+	assert.Equal(suite.T(), "06ae90d55fe0a6e9c5c3bc5b170827b2e5cce3929ed3f116c2811e6366dfe20f", ks.Crypto.Cipher.Message)
+	assert.Equal(suite.T(), "9612d7a727c9d0a22e185a1c768478dfe919cada9266988cb32359c11f2b7b27f4ae4040902382ae2910c15e2b420d07", ks.Pubkey)
+
+	dir := suite.T().TempDir()
+	path, err := WriteKeystore(dir, ks, time.Unix(1700000000, 0))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "keystore-m_12381_60_3141592653_589793238-1700000000.json", filepath.Base(path))
+	_, err = WriteKeystore(dir, ks, time.Unix(1700000000, 0))
+	assert.ErrorIs(suite.T(), err, os.ErrExist, "existing keystores must not be overwritten")
+	entries, err := os.ReadDir(dir)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), entries, 1, "temporary files must be removed")
+
+	read, err := ReadKeystore(path)
+	assert.NoError(suite.T(), err)
+	decrypted, err := read.Decrypt(password)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), sk.Bytes(), decrypted.Bytes())
+
+	_, err = read.Decrypt("wrong")
+	assert.ErrorIs(suite.T(), err, ErrInvalidChecksum)
+}
+
+func (suite *ValidatorTestSuite) TestSignVerify() {
+	sk, err := NewSecretKey(big.NewInt(42))
+	assert.NoError(suite.T(), err)
+
+	msg := make([]byte, 32)
+	sig := sk.Sign(msg)
+	assert.Len(suite.T(), sig, SignatureLength)
+	assert.NoError(suite.T(), Verify(sk.PublicKey(), msg, sig))
+
+	msg[0] = 1
+	assert.Error(suite.T(), Verify(sk.PublicKey(), msg, sig))
+}
+
+// TestComputeDomain checks the well known deposit domain of mainnet.
+func (suite *ValidatorTestSuite) TestComputeDomain() {
+	domain := ComputeDomain(DomainDeposit, Networks["mainnet"].GenesisForkVersion, [32]byte{})
+	assert.Equal(suite.T(), "03000000f5a5fd42d16a20302798ef6ed309979b43003d2320d9f0e8ea9831a9", hex.EncodeToString(domain[:]))
+}
+
+func (suite *ValidatorTestSuite) TestDepositData() {
+	sk, err := NewSecretKey(big.NewInt(42))
+	assert.NoError(suite.T(), err)
+
+	credentials := ExecutionWithdrawalCredentials(common.HexToAddress("0x099f325BD3Dd60F2A7159AC1CaE8B6432A84e7A7"))
+	deposit, err := NewDepositData(sk, credentials, MaxDepositAmount, Networks["holesky"])
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "010000000000000000000000099f325bd3dd60f2a7159ac1cae8b6432a84e7a7", deposit.WithdrawalCredentials)
+	assert.Equal(suite.T(), "01017000", deposit.ForkVersion)
+	assert.True(suite.T(), VerifyDepositData(0, *deposit).OK())
+
+	tampered := *deposit
+	tampered.Amount = MinDepositAmount
+	result := VerifyDepositData(0, tampered)
+	assert.False(suite.T(), result.OK())
+	assert.Len(suite.T(), result.Problems, 3)
+
+	tampered = *deposit
+	tampered.NetworkName = "mainnet"
+	assert.False(suite.T(), VerifyDepositData(0, tampered).OK())
+
+	_, err = NewDepositData(sk, credentials, 33*GweiPerEther, Networks["holesky"])
+	assert.Error(suite.T(), err)
+}
+
+// TestBLSToExecutionChange checks a change against the mainnet domain, whose fork data root starts with the published
+// genesis fork digest 0xb5303f2a, and a signing root computed by hand from the SSZ encoding of the message.
+func (suite *ValidatorTestSuite) TestBLSToExecutionChange() {
+	sk, err := NewSecretKey(big.NewInt(7))
+	assert.NoError(suite.T(), err)
+
+	network := Networks["mainnet"]
+	domain := ComputeDomain(DomainBLSToExecutionChange, network.GenesisForkVersion, network.GenesisValidatorsRoot)
+	assert.Equal(suite.T(), "0a000000b5303f2ad2010d699a76c8e62350947421a3e4a979779642cfdb0f66", hex.EncodeToString(domain[:]))
+
+	address := common.HexToAddress("0x099f325BD3Dd60F2A7159AC1CaE8B6432A84e7A7")
+	change := NewSignedBLSToExecutionChange(sk, 1000, address, network)
+	assert.Equal(suite.T(), "1000", change.Message.ValidatorIndex)
+	assert.Equal(suite.T(), "0xb928f3beb93519eecf0145da903b40a4c97dca00b21f12ac0df3be9116ef2ef27b2ae6bcd4c5bc2d54ef5a70627efcb7", change.Message.FromBLSPubkey)
+	assert.Equal(suite.T(), address.Hex(), change.Message.ToExecutionAddress)
+
+	signingRoot := mustHex("76662202322ad2234a22ad3ba03883d57a42fe24c295c6eea75130202d49d4bc")
+	signature, err := decodeHex(change.Signature, SignatureLength)
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), Verify(sk.PublicKey(), signingRoot, signature))
+
+	credentials := BLSWithdrawalCredentials(sk.PublicKey())
+	assert.NoError(suite.T(), CheckBLSWithdrawalCredentials(sk.PublicKey(), hex.EncodeToString(credentials[:])))
+	assert.Error(suite.T(), CheckBLSWithdrawalCredentials(sk.PublicKey(), "0x"+hex.EncodeToString(make([]byte, 32))))
+}
+
+// TestVoluntaryExit checks an exit against the mainnet Capella domain, whose fork data root starts with the published
+// Capella fork digest 0xbba4da96, and a signing root computed by hand from the SSZ encoding of the message.
+func (suite *ValidatorTestSuite) TestVoluntaryExit() {
+	sk, err := NewSecretKey(big.NewInt(7))
+	assert.NoError(suite.T(), err)
+
+	// Exits are signed with the Capella fork version, whatever the current fork
+	network := Networks["mainnet"]
+	domain := ComputeDomain(DomainVoluntaryExit, network.CapellaForkVersion, network.GenesisValidatorsRoot)
+	assert.Equal(suite.T(), "04000000bba4da96354c9f25476cf1bc69bf583a7f9e0af049305b62de676640", hex.EncodeToString(domain[:]))
+
+	exit := NewSignedVoluntaryExit(sk, 42, 100, network)
+	assert.Equal(suite.T(), VoluntaryExit{Epoch: "100", ValidatorIndex: "42"}, exit.Message)
+
+	signingRoot := mustHex("63fc7d41ab9c2da64022606b30e14068b5eb5890a3f25d4cbd53be7846544ea4")
+	signature, err := decodeHex(exit.Signature, SignatureLength)
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), Verify(sk.PublicKey(), signingRoot, signature))
+}
+
+func (suite *ValidatorTestSuite) TestExportLayout() {
+	sk, err := NewSecretKey(big.NewInt(7))
+	assert.NoError(suite.T(), err)
+	ks, err := EncryptKeystore(sk, "password", keystore.NewPBKDF2KDF(2), SigningKeyPath(0), "")
+	assert.NoError(suite.T(), err)
+	path, err := WriteKeystore(suite.T().TempDir(), ks, time.Now())
+	assert.NoError(suite.T(), err)
+	keys := []ExportKey{{Path: path, Keystore: ks, Password: "password", SecretKey: sk}}
+
+	dir := suite.T().TempDir()
+	results, err := ExportLayout(ClientTeku, dir, keys)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), filepath.Join(dir, "keys", "0x"+ks.Pubkey+".json"), results[0].KeystorePath)
+	assert.Equal(suite.T(), filepath.Join(dir, "passwords", "0x"+ks.Pubkey+".txt"), results[0].PasswordPath)
+	_, err = ExportLayout(ClientTeku, dir, keys)
+	assert.Error(suite.T(), err, "existing files must not be overwritten")
+
+	results, err = ExportLayout(ClientPrysm, dir, keys)
+	assert.NoError(suite.T(), err)
+	content, err := os.ReadFile(results[0].KeystorePath)
+	assert.NoError(suite.T(), err)
+	var accounts prysmAccountsKeystore
+	assert.NoError(suite.T(), json.Unmarshal(content, &accounts))
+	plaintext, err := decryptCrypto(&accounts.Crypto, "password")
+	assert.NoError(suite.T(), err)
+	var store prysmAccountStore
+	assert.NoError(suite.T(), json.Unmarshal(plaintext, &store))
+	assert.Equal(suite.T(), [][]byte{sk.Bytes()}, store.PrivateKeys)
+	assert.Equal(suite.T(), [][]byte{sk.PublicKey()}, store.PublicKeys)
+}
+
+func (suite *ValidatorTestSuite) TestSlashingProtection() {
+	pubkey := "0x" + strings.Repeat("ab", PublicKeyLength)
+	root := Networks["holesky"].GenesisValidatorsRoot
+
+	empty := NewInterchange(root, []string{pubkey})
+	assert.Empty(suite.T(), ValidateInterchange(empty, []string{pubkey}))
+	assert.Len(suite.T(), ValidateInterchange(empty, []string{"0x" + strings.Repeat("cd", PublicKeyLength)}), 1)
+
+	first := NewInterchange(root, []string{pubkey})
+	first.Data[0].SignedBlocks = []SignedBlock{{Slot: "81952"}, {Slot: "81951"}}
+	first.Data[0].SignedAttestations = []SignedAttestation{{SourceEpoch: "2290", TargetEpoch: "3007"}}
+	second := NewInterchange(root, []string{strings.ToUpper(pubkey[2:])})
+	second.Data[0].SignedAttestations = []SignedAttestation{{SourceEpoch: "2291", TargetEpoch: "3000"}}
+
+	merged, err := MergeInterchanges([]*Interchange{empty, first, second})
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), ValidateInterchange(merged, nil))
+	assert.Equal(suite.T(), []InterchangeValidator{{
+		Pubkey:             pubkey,
+		SignedBlocks:       []SignedBlock{{Slot: "81952"}},
+		SignedAttestations: []SignedAttestation{{SourceEpoch: "2291", TargetEpoch: "3007"}},
+	}}, merged.Data)
+
+	other := NewInterchange(Networks["sepolia"].GenesisValidatorsRoot, []string{pubkey})
+	_, err = MergeInterchanges([]*Interchange{first, other})
+	assert.Error(suite.T(), err, "interchanges of different networks must not be merged")
+
+	first.Data[0].SignedAttestations[0].SourceEpoch = "3008"
+	assert.Len(suite.T(), ValidateInterchange(first, nil), 1)
+
+	// An invalid epoch is its own problem, not also an ordering one
+	first.Data[0].SignedAttestations[0] = SignedAttestation{SourceEpoch: "2290", TargetEpoch: "-1"}
+	problems := ValidateInterchange(first, nil)
+	assert.Len(suite.T(), problems, 1)
+	assert.Contains(suite.T(), problems[0], "target_epoch")
+	first.Data[0].SignedBlocks = append(first.Data[0].SignedBlocks, SignedBlock{Slot: "latest"})
+	assert.Equal(suite.T(), []InterchangeWatermark{{Pubkey: pubkey, Blocks: 2, MaxSlot: 81952}}, Watermarks(first))
+}
@@ -0,0 +1,115 @@
+package wallet
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/aldoborrero/ethw/internal/utils/pool"
+	"github.com/btcsuite/btcutil/hdkeychain"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Discovered is an account found in use while walking derivation indexes.
+type Discovered struct {
+	Index          int
+	Address        common.Address
+	DerivationPath string
+	// Balance and Nonce are nil when usage isn't checked against chain state.
+	Balance *big.Int
+	Nonce   *uint64
+}
+
+// Deriver derives the address and derivation path of the account at an index.
+type Deriver func(index int) (common.Address, string, error)
+
+// UsageFunc reports whether an account was used, filling its balance and nonce when known.
+type UsageFunc func(account *Discovered) (bool, error)
+
+// MnemonicDeriver derives the accounts of a mnemonic with the given derivation scheme.
+func MnemonicDeriver(mnemonic, scheme string) Deriver {
+	return func(index int) (common.Address, string, error) {
+		path, err := DerivationPath(scheme, index)
+		if err != nil {
+			return common.Address{}, "", err
+		}
+		w, err := NewWallet(mnemonic, "", path)
+		if err != nil {
+			return common.Address{}, "", fmt.Errorf("failed to derive wallet %d: %w", index, err)
+		}
+		return common.HexToAddress(w.Address), w.DerivationPath, nil
+	}
+}
+
+// XpubDeriver derives the accounts which are the direct, non-hardened, children of an extended public key, e.g. the
+// key at m/44'/60'/0'/0 for bip44 accounts. path is the derivation path of the extended key, labelling the accounts.
+func XpubDeriver(xpub, path string) (Deriver, error) {
+	parent, err := hdkeychain.NewKeyFromString(strings.TrimSpace(xpub))
+	if err != nil {
+		return nil, fmt.Errorf("invalid extended public key: %w", err)
+	}
+	if parent.IsPrivate() {
+		return nil, fmt.Errorf("invalid extended public key: got an extended private key")
+	}
+
+	return func(index int) (common.Address, string, error) {
+		if index < 0 || index >= hdkeychain.HardenedKeyStart {
+			return common.Address{}, "", fmt.Errorf("index %d can't be derived from an extended public key", index)
+		}
+		child, err := parent.Derive(uint32(index))
+		if err != nil {
+			return common.Address{}, "", fmt.Errorf("failed to derive wallet %d: %w", index, err)
+		}
+		pub, err := child.ECPubKey()
+		if err != nil {
+			return common.Address{}, "", fmt.Errorf("failed to derive wallet %d: %w", index, err)
+		}
+		key, err := crypto.UnmarshalPubkey(pub.SerializeUncompressed())
+		if err != nil {
+			return common.Address{}, "", fmt.Errorf("failed to derive wallet %d: %w", index, err)
+		}
+		return crypto.PubkeyToAddress(*key), fmt.Sprintf("%s/%d", strings.TrimSuffix(path, "/"), index), nil
+	}, nil
+}
+
+// Discover walks the derivation indexes from start, returning the used accounts, until gap consecutive accounts are
+// unused. Accounts are checked workers at a time.
+func Discover(derive Deriver, used UsageFunc, start, gap, workers int) ([]Discovered, error) {
+	if gap < 1 {
+		return nil, fmt.Errorf("invalid gap %d: expected at least 1", gap)
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	var discovered []Discovered
+	unused := 0
+	for next := start; ; next += workers {
+		accounts := make([]Discovered, workers)
+		usage := make([]bool, workers)
+		errs := make([]error, workers)
+		pool.Run(workers, workers, func(i int) {
+			account := &accounts[i]
+			account.Index = next + i
+			if account.Address, account.DerivationPath, errs[i] = derive(account.Index); errs[i] != nil {
+				return
+			}
+			usage[i], errs[i] = used(account)
+		})
+
+		for i := range accounts {
+			if errs[i] != nil {
+				return nil, errs[i]
+			}
+			if !usage[i] {
+				if unused++; unused >= gap {
+					return discovered, nil
+				}
+				continue
+			}
+			unused = 0
+			discovered = append(discovered, accounts[i])
+		}
+	}
+}
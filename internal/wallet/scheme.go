@@ -0,0 +1,77 @@
+package wallet
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Schemes maps the well known derivation path schemes to their path template, where %d is the account index.
+var Schemes = map[string]string{
+	// bip44 is the path used by MetaMask, geth's USB wallets and most software wallets.
+	"bip44": "m/44'/60'/0'/0/%d",
+	// ledger-live is the path used by Ledger Live.
+	"ledger-live": "m/44'/60'/%d'/0/0",
+	// ledger-legacy is the path used by the legacy Ledger Chrome app, index 0 is ethw's default path.
+	"ledger-legacy": "m/44'/60'/0'/%d",
+}
+
+// SchemeNames returns the names of the well known derivation path schemes, sorted.
+func SchemeNames() []string {
+	names := make([]string, 0, len(Schemes))
+	for name := range Schemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DerivationPath returns the derivation path of the account at index for the given scheme. The scheme can be the
+// name of a well known scheme or a custom template containing a single %d, e.g. "m/44'/60'/0'/0/%d".
+func DerivationPath(scheme string, index int) (string, error) {
+	template, ok := Schemes[scheme]
+	if !ok {
+		template = scheme
+	}
+	if strings.Count(template, "%d") != 1 {
+		return "", fmt.Errorf("invalid derivation scheme %q: expected one of %s or a template with a single %%d", scheme, strings.Join(SchemeNames(), ", "))
+	}
+	return fmt.Sprintf(template, index), nil
+}
+
+// ParseRange parses an inclusive index range such as "0-49", or a single index such as "7".
+func ParseRange(raw string) (start, end int, err error) {
+	from, to, found := strings.Cut(strings.TrimSpace(raw), "-")
+	if start, err = strconv.Atoi(strings.TrimSpace(from)); err != nil {
+		return 0, 0, fmt.Errorf("invalid range %q: %w", raw, err)
+	}
+	end = start
+	if found {
+		if end, err = strconv.Atoi(strings.TrimSpace(to)); err != nil {
+			return 0, 0, fmt.Errorf("invalid range %q: %w", raw, err)
+		}
+	}
+	if start < 0 || end < start {
+		return 0, 0, fmt.Errorf("invalid range %q: expected <start>-<end> with 0 <= start <= end", raw)
+	}
+	return start, end, nil
+}
+
+// NewWallets derives the wallets at every index of the inclusive range using the given derivation scheme.
+func NewWallets(mnemonic, scheme string, start, end int) ([]*Wallet, error) {
+	wallets := make([]*Wallet, 0, end-start+1)
+	for index := start; index <= end; index++ {
+		path, err := DerivationPath(scheme, index)
+		if err != nil {
+			return nil, err
+		}
+
+		w, err := NewWallet(mnemonic, "", path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive wallet %d: %w", index, err)
+		}
+		wallets = append(wallets, w)
+	}
+	return wallets, nil
+}
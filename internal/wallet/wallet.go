@@ -1,16 +1,20 @@
 package wallet
 
 import (
+	"crypto/ecdsa"
 	"fmt"
 
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
 	hdwallet "github.com/miguelmota/go-ethereum-hdwallet"
 )
 
 type Wallet struct {
-	Alias      string `json:"alias"`
-	Address    string `json:"address"`
-	PrivateKey string `json:"private_key"`
-	PublicKey  string `json:"public_key"`
+	Alias          string `json:"alias"`
+	Address        string `json:"address"`
+	PrivateKey     string `json:"private_key"`
+	PublicKey      string `json:"public_key"`
+	DerivationPath string `json:"derivation_path,omitempty"`
 }
 
 // NewWallet creates a new Wallet from the given mnemonic, alias, and an optional custom derivation path.
@@ -48,9 +52,21 @@ func NewWallet(mnemonic, alias string, customDerivationPath ...string) (*Wallet,
 	}
 
 	return &Wallet{
-		Alias:      alias,
-		Address:    account.Address.Hex(),
-		PrivateKey: privateKeyHex,
-		PublicKey:  publicKeyHex,
+		Alias:          alias,
+		Address:        account.Address.Hex(),
+		PrivateKey:     privateKeyHex,
+		PublicKey:      publicKeyHex,
+		DerivationPath: path.String(),
 	}, nil
 }
+
+// NewWalletFromPrivateKey creates a new Wallet from an already known private key, e.g. one unlocked from a keystore.
+// Keys are encoded the same way as wallets derived from a mnemonic.
+func NewWalletFromPrivateKey(privateKey *ecdsa.PrivateKey, alias string) *Wallet {
+	return &Wallet{
+		Alias:      alias,
+		Address:    crypto.PubkeyToAddress(privateKey.PublicKey).Hex(),
+		PrivateKey: hexutil.Encode(crypto.FromECDSA(privateKey))[2:],
+		PublicKey:  hexutil.Encode(crypto.FromECDSAPub(&privateKey.PublicKey))[4:],
+	}
+}
@@ -0,0 +1,85 @@
+package wallet
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil/hdkeychain"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/tyler-smith/go-bip39"
+)
+
+const testMnemonic = "install puzzle strike suit boil skate find address thrive reopen outdoor churn"
+
+type WalletTestSuite struct {
+	suite.Suite
+}
+
+// xpub returns the extended public key of the mnemonic at m/44'/60'/0'/0.
+func (suite *WalletTestSuite) xpub() string {
+	key, err := hdkeychain.NewMaster(bip39.NewSeed(testMnemonic, ""), &chaincfg.MainNetParams)
+	assert.NoError(suite.T(), err)
+	for _, index := range []uint32{hdkeychain.HardenedKeyStart + 44, hdkeychain.HardenedKeyStart + 60, hdkeychain.HardenedKeyStart, 0} {
+		key, err = key.Derive(index)
+		assert.NoError(suite.T(), err)
+	}
+	public, err := key.Neuter()
+	assert.NoError(suite.T(), err)
+	return public.String()
+}
+
+func (suite *WalletTestSuite) TestXpubDeriver() {
+	fromMnemonic := MnemonicDeriver(testMnemonic, "bip44")
+	fromXpub, err := XpubDeriver(suite.xpub(), "m/44'/60'/0'/0/")
+	assert.NoError(suite.T(), err)
+
+	for _, index := range []int{0, 1, 7} {
+		expectedAddress, expectedPath, err := fromMnemonic(index)
+		assert.NoError(suite.T(), err)
+		address, path, err := fromXpub(index)
+		assert.NoError(suite.T(), err)
+		assert.Equal(suite.T(), expectedAddress, address)
+		assert.Equal(suite.T(), expectedPath, path)
+	}
+	address, _, err := fromXpub(0)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), common.HexToAddress("0xCbD8DD4430bC8A3fC4A7C33392b90bb4f1892c90"), address)
+
+	_, err = XpubDeriver("xpub-invalid", "m")
+	assert.Error(suite.T(), err)
+}
+
+func (suite *WalletTestSuite) TestDiscover() {
+	derive := func(index int) (common.Address, string, error) {
+		return common.BigToAddress(big.NewInt(int64(index + 1))), "", nil
+	}
+	used := map[int]bool{0: true, 1: true, 4: true, 8: true}
+	usage := func(account *Discovered) (bool, error) {
+		return used[account.Index], nil
+	}
+
+	for _, workers := range []int{1, 3, 8} {
+		discovered, err := Discover(derive, usage, 0, 3, workers)
+		assert.NoError(suite.T(), err)
+		indexes := []int{}
+		for _, account := range discovered {
+			indexes = append(indexes, account.Index)
+		}
+		// Index 8 comes after 3 unused accounts, the walk stops before it
+		assert.Equal(suite.T(), []int{0, 1, 4}, indexes, "workers %d", workers)
+	}
+
+	discovered, err := Discover(derive, usage, 2, 4, 2)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), discovered, 2)
+
+	_, err = Discover(derive, usage, 0, 0, 1)
+	assert.Error(suite.T(), err)
+}
+
+func TestWalletTestSuite(t *testing.T) {
+	suite.Run(t, new(WalletTestSuite))
+}